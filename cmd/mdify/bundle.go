@@ -0,0 +1,41 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"mdify/internal/filesystem"
+)
+
+// bundledExecutable returns the path of the running binary when it was made
+// by "mdify bundle"
+func bundledExecutable() (string, bool) {
+	exe, err := os.Executable()
+	if err != nil {
+		return "", false
+	}
+	bundle, err := filesystem.OpenBundle(exe)
+	if err != nil {
+		return "", false
+	}
+	bundle.Close()
+	return exe, true
+}
+
+// bundledCmd is the root command of a binary made by "mdify bundle": the
+// serve command, pointed at the docs appended to the binary
+func bundledCmd(exe string) *cobra.Command {
+	cmd := serveCmd()
+	cmd.Use = filepath.Base(exe)
+	cmd.Short = "Serve the documentation bundled into this binary"
+	cmd.Long = `Serve the documentation bundled into this binary by "mdify bundle", with
+rendered HTML for browsers, raw markdown for tools and search at /_search.
+Nothing needs to be on disk; the flags are those of "mdify serve".`
+	cmd.Version = version
+	cmd.Args = cobra.NoArgs
+	cmd.Flags().Set("dir", exe)
+	cmd.Flags().MarkHidden("dir")
+	return cmd
+}
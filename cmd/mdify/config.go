@@ -0,0 +1,310 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/spf13/pflag"
+	"gopkg.in/yaml.v3"
+
+	"mdify/internal/httpclient"
+	"mdify/pkg/chunk"
+	"mdify/pkg/scraper"
+)
+
+// defaultConfigFiles are searched in the working directory when --config isn't given
+var defaultConfigFiles = []string{"mdify.yaml", "mdify.yml", "mdify.toml"}
+
+// ConfigFile is the structure of an mdify.yaml or mdify.toml file
+type ConfigFile struct {
+	Profiles map[string]Profile
+}
+
+// Profile is a named set of scrape options
+type Profile struct {
+	Sitemap       string            `yaml:"sitemap" toml:"sitemap"`
+	URLs          []string          `yaml:"urls" toml:"urls"`
+	URLsFile      string            `yaml:"urls_file" toml:"urls_file"`
+	Selector      string            `yaml:"selector" toml:"selector"`
+	Exclude       []string          `yaml:"exclude" toml:"exclude"`
+	Filter        string            `yaml:"filter" toml:"filter"`
+	Workers       int               `yaml:"workers" toml:"workers"`
+	Output        string            `yaml:"output" toml:"output"`
+	TableFallback string            `yaml:"table_fallback" toml:"table_fallback"`
+	RateLimit     time.Duration     `yaml:"rate_limit" toml:"rate_limit"`
+	UserAgent     string            `yaml:"user_agent" toml:"user_agent"`
+	Headers       map[string]string `yaml:"headers" toml:"headers"`
+	Cookies       string            `yaml:"cookies" toml:"cookies"`
+	AuthHosts     []string          `yaml:"auth_hosts" toml:"auth_hosts"`
+	Proxy         string            `yaml:"proxy" toml:"proxy"`
+	CAFile        string            `yaml:"ca_file" toml:"ca_file"`
+	ClientCert    string            `yaml:"client_cert" toml:"client_cert"`
+	ClientKey     string            `yaml:"client_key" toml:"client_key"`
+	Insecure      bool              `yaml:"insecure" toml:"insecure"`
+	Chunks        string            `yaml:"chunks" toml:"chunks"`
+	ChunkTokens   int               `yaml:"chunk_max_tokens" toml:"chunk_max_tokens"`
+	ChunkOverlap  int               `yaml:"chunk_overlap" toml:"chunk_overlap"`
+	Dedup         string            `yaml:"dedup" toml:"dedup"`
+	NearDupBits   int               `yaml:"near_duplicate_bits" toml:"near_duplicate_bits"`
+	Report        string            `yaml:"report" toml:"report"`
+	OutputFormat  string            `yaml:"output_format" toml:"output_format"`
+}
+
+// loadConfigFile reads the config file at path, or the first default config
+// file found in the working directory when path is empty
+func loadConfigFile(path string) (*ConfigFile, error) {
+	if path == "" {
+		for _, candidate := range defaultConfigFiles {
+			if _, err := os.Stat(candidate); err == nil {
+				path = candidate
+				break
+			}
+		}
+		if path == "" {
+			return nil, fmt.Errorf("no config file found (looked for %v)", defaultConfigFiles)
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	return parseConfigFile(path, data)
+}
+
+// parseConfigFile decodes a config file as TOML when path ends in .toml and
+// as YAML otherwise. Each profile is decoded over the defaults, so a value
+// a profile sets to zero is kept the way the same flag's would be.
+func parseConfigFile(path string, data []byte) (*ConfigFile, error) {
+	config := &ConfigFile{Profiles: make(map[string]Profile)}
+	var err error
+	if strings.EqualFold(filepath.Ext(path), ".toml") {
+		err = parseTOMLProfiles(data, config.Profiles)
+	} else {
+		err = parseYAMLProfiles(data, config.Profiles)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse config file: %w", err)
+	}
+	return config, nil
+}
+
+func parseYAMLProfiles(data []byte, profiles map[string]Profile) error {
+	var file struct {
+		Profiles map[string]yaml.Node `yaml:"profiles"`
+	}
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return err
+	}
+	for name, node := range file.Profiles {
+		profile := defaultProfile()
+		if err := node.Decode(&profile); err != nil {
+			return fmt.Errorf("profile %q: %w", name, err)
+		}
+		profiles[name] = profile
+	}
+	return nil
+}
+
+func parseTOMLProfiles(data []byte, profiles map[string]Profile) error {
+	var file struct {
+		Profiles map[string]toml.Primitive `toml:"profiles"`
+	}
+	meta, err := toml.Decode(string(data), &file)
+	if err != nil {
+		return err
+	}
+	for name, primitive := range file.Profiles {
+		profile := defaultProfile()
+		if err := meta.PrimitiveDecode(primitive, &profile); err != nil {
+			return fmt.Errorf("profile %q: %w", name, err)
+		}
+		profiles[name] = profile
+	}
+	return nil
+}
+
+// Profile returns the named profile, with defaults for the values it doesn't set
+func (c *ConfigFile) Profile(name string) (Profile, error) {
+	profile, ok := c.Profiles[name]
+	if !ok {
+		return Profile{}, fmt.Errorf("profile %q not found in config file", name)
+	}
+	return profile, nil
+}
+
+// ProfileNames returns the names of all profiles in a stable order
+func (c *ConfigFile) ProfileNames() []string {
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// defaultProfile has the defaults of the scrape flags; config file profiles
+// are decoded over it
+func defaultProfile() Profile {
+	return Profile{
+		Workers:       4,
+		Output:        "./docs",
+		TableFallback: scraper.TableFallbackHTML,
+		UserAgent:     defaultUserAgent,
+		ChunkTokens:   chunk.DefaultMaxSize,
+		ChunkOverlap:  chunk.DefaultOverlap,
+		OutputFormat:  formatDir,
+		Dedup:         scraper.DedupOff,
+		NearDupBits:   scraper.DefaultNearDuplicateBits,
+	}
+}
+
+// applyFlags overrides profile values with any flags set on the command line
+func (p Profile) applyFlags(flags *pflag.FlagSet, values Profile) Profile {
+	if flags.Changed("sitemap") {
+		p.Sitemap = values.Sitemap
+	}
+	if flags.Changed("selector") {
+		p.Selector = values.Selector
+	}
+	if flags.Changed("exclude") {
+		p.Exclude = values.Exclude
+	}
+	if flags.Changed("filter") {
+		p.Filter = values.Filter
+	}
+	if flags.Changed("workers") {
+		p.Workers = values.Workers
+	}
+	if flags.Changed("output") {
+		p.Output = values.Output
+	}
+	if flags.Changed("table-fallback") {
+		p.TableFallback = values.TableFallback
+	}
+	if flags.Changed("rate-limit") {
+		p.RateLimit = values.RateLimit
+	}
+	if flags.Changed("user-agent") {
+		p.UserAgent = values.UserAgent
+	}
+	if flags.Changed("header") {
+		headers := make(map[string]string, len(p.Headers)+len(values.Headers))
+		for name, value := range p.Headers {
+			headers[name] = value
+		}
+		for name, value := range values.Headers {
+			headers[name] = value
+		}
+		p.Headers = headers
+	}
+	if flags.Changed("cookies") {
+		p.Cookies = values.Cookies
+	}
+	if flags.Changed("auth-host") {
+		p.AuthHosts = values.AuthHosts
+	}
+	if flags.Changed("proxy") {
+		p.Proxy = values.Proxy
+	}
+	if flags.Changed("ca-file") {
+		p.CAFile = values.CAFile
+	}
+	if flags.Changed("client-cert") {
+		p.ClientCert = values.ClientCert
+	}
+	if flags.Changed("client-key") {
+		p.ClientKey = values.ClientKey
+	}
+	if flags.Changed("insecure") {
+		p.Insecure = values.Insecure
+	}
+	if flags.Changed("chunks") {
+		p.Chunks = values.Chunks
+	}
+	if flags.Changed("chunk-max-tokens") {
+		p.ChunkTokens = values.ChunkTokens
+	}
+	if flags.Changed("chunk-overlap") {
+		p.ChunkOverlap = values.ChunkOverlap
+	}
+	if flags.Changed("dedup") {
+		p.Dedup = values.Dedup
+	}
+	if flags.Changed("near-duplicate-bits") {
+		p.NearDupBits = values.NearDupBits
+	}
+	if flags.Changed("report") {
+		p.Report = values.Report
+	}
+	if flags.Changed("output-format") {
+		p.OutputFormat = values.OutputFormat
+	}
+	return p
+}
+
+// validate checks that the profile can be run
+func (p Profile) validate() error {
+	if p.Selector == "" {
+		return fmt.Errorf("a selector is required (use --selector or set selector in the profile)")
+	}
+	if p.TableFallback != scraper.TableFallbackHTML && p.TableFallback != scraper.TableFallbackList {
+		return fmt.Errorf("invalid table fallback %q (use html or list)", p.TableFallback)
+	}
+	if p.OutputFormat != "" && !validOutputFormat(p.OutputFormat) {
+		return fmt.Errorf("invalid output format %q (use dir, zip, tar.gz or sqlite)", p.OutputFormat)
+	}
+	if p.Dedup != "" && !scraper.ValidDedupMode(p.Dedup) {
+		return fmt.Errorf("invalid dedup mode %q (use off, record, skip or symlink)", p.Dedup)
+	}
+	if p.Chunks != "" {
+		config := chunk.Config{MaxSize: p.ChunkTokens, Overlap: p.ChunkOverlap, Unit: chunk.UnitTokens}
+		if err := config.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// withAuthHosts returns the profile with its auth hosts defaulted, unless
+// it lists some, to the hosts of the URLs the user gave: the sitemap, urls,
+// URL files and stdin. Hosts found in the sitemap get no credentials.
+func (p Profile) withAuthHosts(given []string) Profile {
+	if len(p.AuthHosts) == 0 {
+		p.AuthHosts = httpclient.URLHosts(append([]string{p.Sitemap}, given...))
+	}
+	return p
+}
+
+// httpOptions returns the HTTP client options for the profile, with
+// credentials taken from the environment. Unless the profile lists auth
+// hosts, only the hosts of its sitemap and urls receive credentials.
+func (p Profile) httpOptions() httpclient.Options {
+	authHosts := p.AuthHosts
+	if len(authHosts) == 0 {
+		authHosts = httpclient.URLHosts(append([]string{p.Sitemap}, p.URLs...))
+	}
+
+	return httpclient.Options{
+		Timeout:    30 * time.Second,
+		UserAgent:  p.UserAgent,
+		Headers:    p.Headers,
+		CookieFile: p.Cookies,
+		AuthHosts:  authHosts,
+		Proxy:      p.Proxy,
+		CAFile:     p.CAFile,
+		CertFile:   p.ClientCert,
+		KeyFile:    p.ClientKey,
+		Insecure:   p.Insecure,
+	}.FromEnv()
+}
+
+func (p Profile) hasSources() bool {
+	return p.Sitemap != "" || len(p.URLs) > 0 || p.URLsFile != ""
+}
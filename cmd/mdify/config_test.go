@@ -0,0 +1,227 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/spf13/pflag"
+
+	"mdify/pkg/chunk"
+)
+
+const testConfig = `
+profiles:
+  react-docs:
+    sitemap: https://react.dev/sitemap.xml
+    filter: /learn/
+    selector: article
+    exclude:
+      - .edit-link
+    workers: 8
+    output: ./react
+    rate_limit: 250ms
+  go-docs:
+    urls:
+      - https://go.dev/doc/
+    selector: .Article
+`
+
+const testTOMLConfig = `
+[profiles.react-docs]
+sitemap = "https://react.dev/sitemap.xml"
+filter = "/learn/"
+selector = "article"
+exclude = [".edit-link"]
+workers = 8
+output = "./react"
+rate_limit = "250ms"
+
+[profiles.go-docs]
+urls = ["https://go.dev/doc/"]
+selector = ".Article"
+`
+
+func TestParseConfigFile(t *testing.T) {
+	tests := []struct {
+		path string
+		data string
+	}{
+		{path: "mdify.yaml", data: testConfig},
+		{path: "mdify.yml", data: testConfig},
+		{path: "mdify.toml", data: testTOMLConfig},
+		{path: "profiles/Docs.TOML", data: testTOMLConfig},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			testProfiles(t, tt.path, tt.data)
+		})
+	}
+}
+
+func testProfiles(t *testing.T, path, data string) {
+	t.Helper()
+
+	config, err := parseConfigFile(path, []byte(data))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	names := config.ProfileNames()
+	if len(names) != 2 || names[0] != "go-docs" || names[1] != "react-docs" {
+		t.Errorf("expected sorted profile names, got %v", names)
+	}
+
+	react, err := config.Profile("react-docs")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if react.Sitemap != "https://react.dev/sitemap.xml" || react.Filter != "/learn/" || react.Selector != "article" {
+		t.Errorf("unexpected profile values: %+v", react)
+	}
+	if react.Workers != 8 || react.Output != "./react" || react.RateLimit != 250*time.Millisecond {
+		t.Errorf("unexpected profile values: %+v", react)
+	}
+	if len(react.Exclude) != 1 || react.Exclude[0] != ".edit-link" {
+		t.Errorf("expected exclude selectors, got %v", react.Exclude)
+	}
+
+	goDocs, err := config.Profile("go-docs")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if goDocs.Workers != 4 || goDocs.Output != "./docs" || goDocs.TableFallback != "html" {
+		t.Errorf("expected defaults to be applied, got %+v", goDocs)
+	}
+
+	if _, err := config.Profile("missing"); err == nil {
+		t.Errorf("expected error for missing profile")
+	}
+}
+
+func TestParseConfigFile_ZeroValues(t *testing.T) {
+	tests := []struct {
+		path string
+		data string
+	}{
+		{path: "mdify.yaml", data: "profiles:\n  exact:\n    workers: 0\n    chunk_overlap: 0\n    near_duplicate_bits: 0\n"},
+		{path: "mdify.toml", data: "[profiles.exact]\nworkers = 0\nchunk_overlap = 0\nnear_duplicate_bits = 0\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			config, err := parseConfigFile(tt.path, []byte(tt.data))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			profile, err := config.Profile("exact")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if profile.Workers != 0 || profile.ChunkOverlap != 0 || profile.NearDupBits != 0 {
+				t.Errorf("expected the zeros set in the profile to be kept, got %+v", profile)
+			}
+			if profile.ChunkTokens != chunk.DefaultMaxSize || profile.Output != "./docs" {
+				t.Errorf("expected defaults for values the profile doesn't set, got %+v", profile)
+			}
+		})
+	}
+}
+
+func TestParseConfigFile_Invalid(t *testing.T) {
+	if _, err := parseConfigFile("mdify.yaml", []byte("profiles: [")); err == nil {
+		t.Errorf("expected error for invalid YAML")
+	}
+	if _, err := parseConfigFile("mdify.toml", []byte("[profiles")); err == nil {
+		t.Errorf("expected error for invalid TOML")
+	}
+	if _, err := parseConfigFile("mdify.toml", []byte(testConfig)); err == nil {
+		t.Errorf("expected YAML in a .toml file to be rejected")
+	}
+}
+
+func TestLoadConfigFile_Default(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+
+	if _, err := loadConfigFile(""); err == nil {
+		t.Fatal("expected error when no config file exists")
+	}
+
+	os.WriteFile(filepath.Join(dir, "mdify.toml"), []byte(testTOMLConfig), 0644)
+	config, err := loadConfigFile("")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(config.ProfileNames()) != 2 {
+		t.Errorf("expected profiles from mdify.toml, got %v", config.ProfileNames())
+	}
+}
+
+func TestProfile_ApplyFlags(t *testing.T) {
+	flags := pflag.NewFlagSet("scrape", pflag.ContinueOnError)
+	flags.String("output", "./docs", "")
+	flags.Int("workers", 4, "")
+	flags.String("selector", "", "")
+	if err := flags.Parse([]string{"--output", "./out", "--workers", "1"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	profile := Profile{Selector: "article", Output: "./react", Workers: 8}
+	values := Profile{Output: "./out", Workers: 1}
+
+	merged := profile.applyFlags(flags, values)
+
+	if merged.Output != "./out" || merged.Workers != 1 {
+		t.Errorf("expected flags to override profile, got %+v", merged)
+	}
+	if merged.Selector != "article" {
+		t.Errorf("expected unset flags to keep profile value, got %q", merged.Selector)
+	}
+}
+
+func TestProfile_Validate(t *testing.T) {
+	if err := (Profile{TableFallback: "html"}).validate(); err == nil {
+		t.Errorf("expected error for missing selector")
+	}
+	if err := (Profile{Selector: ".content", TableFallback: "csv"}).validate(); err == nil {
+		t.Errorf("expected error for invalid table fallback")
+	}
+	if err := (Profile{Selector: ".content", TableFallback: "list"}).validate(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := (Profile{Selector: ".content", TableFallback: "html", Dedup: "merge"}).validate(); err == nil {
+		t.Errorf("expected error for invalid dedup mode")
+	}
+	if err := (Profile{Selector: ".content", TableFallback: "html", OutputFormat: "rar"}).validate(); err == nil {
+		t.Errorf("expected error for invalid output format")
+	}
+	if err := (Profile{Selector: ".content", TableFallback: "html", Chunks: "chunks.jsonl", ChunkTokens: 100, ChunkOverlap: 100}).validate(); err == nil {
+		t.Errorf("expected error for chunk overlap as large as the chunk")
+	}
+	if err := (Profile{Selector: ".content", TableFallback: "html", Chunks: "chunks.jsonl", ChunkTokens: 512, ChunkOverlap: 64}).validate(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestProfile_HTTPOptions_AuthHosts(t *testing.T) {
+	profile := Profile{Sitemap: "https://docs.internal/sitemap.xml", URLs: []string{"https://cdn.internal/b"}}
+
+	hosts := profile.httpOptions().AuthHosts
+	if !reflect.DeepEqual(hosts, []string{"docs.internal", "cdn.internal"}) {
+		t.Errorf("expected the hosts of the sitemap and urls, got %v", hosts)
+	}
+
+	given := []string{"https://cdn.internal/b", "https://files.internal/c"}
+	if hosts := profile.withAuthHosts(given).httpOptions().AuthHosts; !reflect.DeepEqual(hosts, []string{"docs.internal", "cdn.internal", "files.internal"}) {
+		t.Errorf("expected the hosts of the sitemap and given urls, got %v", hosts)
+	}
+
+	profile.AuthHosts = []string{"sso.internal"}
+	if hosts := profile.withAuthHosts(given).httpOptions().AuthHosts; !reflect.DeepEqual(hosts, []string{"sso.internal"}) {
+		t.Errorf("expected the configured auth hosts, got %v", hosts)
+	}
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+)
+
+// logger is shared by every command. It logs text to stderr until the
+// --log-* flags are applied, keeping stdout free for output and protocols.
+var logger = slog.New(slog.NewTextHandler(os.Stderr, nil))
+
+// logOptions hold the persistent --log-* flags
+type logOptions struct {
+	level  string
+	format string
+	file   string
+
+	closer io.Closer // log file opened by apply
+}
+
+// apply replaces the shared logger with one configured from the flags
+func (o *logOptions) apply() error {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(o.level)); err != nil {
+		return fmt.Errorf("invalid --log-level %q: use debug, info, warn or error", o.level)
+	}
+
+	var out io.Writer = os.Stderr
+	if o.file != "" {
+		file, err := os.OpenFile(o.file, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+		if err != nil {
+			return fmt.Errorf("failed to open log file: %w", err)
+		}
+		o.closer = file
+		out = file
+	}
+
+	handlerOptions := &slog.HandlerOptions{Level: level}
+	switch o.format {
+	case "text":
+		logger = slog.New(slog.NewTextHandler(out, handlerOptions))
+	case "json":
+		logger = slog.New(slog.NewJSONHandler(out, handlerOptions))
+	default:
+		o.close()
+		return fmt.Errorf("invalid --log-format %q: use text or json", o.format)
+	}
+	return nil
+}
+
+func (o *logOptions) close() {
+	if o.closer != nil {
+		o.closer.Close()
+		o.closer = nil
+	}
+}
@@ -2,31 +2,69 @@ package main
 
 import (
 	"bufio"
+	"context"
+	"errors"
 	"fmt"
-	"net/http"
+	"io"
 	"os"
+	"os/signal"
+	"strings"
+	"syscall"
 	"time"
 
 	"github.com/spf13/cobra"
 
 	"mdify/internal/filesystem"
+	"mdify/internal/httpclient"
+	"mdify/pkg/chunk"
+	"mdify/pkg/diff"
+	"mdify/pkg/mcp"
 	"mdify/pkg/scraper"
 	"mdify/pkg/server"
 	"mdify/pkg/sitemap"
+	"mdify/pkg/stats"
 )
 
+const version = "0.1.0"
+
+var defaultUserAgent = "mdify/" + version
+
+// authTokenEnv adds a bearer token to the serve command without putting it
+// on the command line
+const authTokenEnv = "MDIFY_AUTH_TOKEN"
+
 func main() {
 	var rootCmd = &cobra.Command{
 		Use:     "mdify",
 		Short:   "Convert web documentation to markdown files",
 		Long:    `Scrape docs sites and convert them to markdown for LLM consumption, preserving directory structure.`,
-		Version: "0.1.0",
+		Version: version,
 	}
 
 	rootCmd.AddCommand(scrapeCmd())
 	rootCmd.AddCommand(serveCmd())
+	rootCmd.AddCommand(mcpCmd())
+	rootCmd.AddCommand(chunkCmd())
+	rootCmd.AddCommand(statsCmd())
+	rootCmd.AddCommand(bundleCmd())
+	rootCmd.AddCommand(diffCmd())
+
+	// a binary made by "mdify bundle" only serves the docs appended to it
+	if exe, ok := bundledExecutable(); ok {
+		rootCmd = bundledCmd(exe)
+	}
 
-	if err := rootCmd.Execute(); err != nil {
+	var logging logOptions
+	rootCmd.PersistentFlags().StringVar(&logging.level, "log-level", "info", "Minimum level to log: debug, info, warn or error")
+	rootCmd.PersistentFlags().StringVar(&logging.format, "log-format", "text", "Log format: text or json")
+	rootCmd.PersistentFlags().StringVar(&logging.file, "log-file", "", "Append logs to this file instead of stderr")
+	rootCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		return logging.apply()
+	}
+
+	err := rootCmd.Execute()
+	logging.close()
+	if err != nil {
 		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
 	}
@@ -34,11 +72,34 @@ func main() {
 
 func scrapeCmd() *cobra.Command {
 	var (
-		selector   string
-		output     string
-		sitemapURL string
-		pathFilter string
-		workers    int
+		selector      string
+		output        string
+		sitemapURL    string
+		pathFilter    string
+		workers       int
+		tableFallback string
+		exclude       []string
+		rateLimit     time.Duration
+		userAgent     string
+		headers       []string
+		cookies       string
+		authHosts     []string
+		proxy         string
+		caFile        string
+		clientCert    string
+		clientKey     string
+		insecure      bool
+		configPath    string
+		profileName   string
+		allProfiles   bool
+		progress      progressOptions
+		chunks        string
+		chunkTokens   int
+		chunkOverlap  int
+		dedup         string
+		nearDupBits   int
+		reportPath    string
+		outputFormat  string
 	)
 
 	cmd := &cobra.Command{
@@ -55,66 +116,504 @@ Examples:
   mdify scrape --sitemap https://example.com/sitemap.xml --selector ".content"
 
   # From sitemap with path filtering
-  mdify scrape --sitemap https://example.com/sitemap.xml --filter "/docs/" --selector ".prose"`,
+  mdify scrape --sitemap https://example.com/sitemap.xml --filter "/docs/" --selector ".prose"
+
+  # From a profile in mdify.yaml, overriding its output directory
+  mdify scrape --profile react-docs --output ./react
+
+  # Every profile in mdify.yaml
+  mdify scrape --all-profiles
+
+  # Private docs: credentials come from the environment, not flags
+  MDIFY_BEARER_TOKEN=... mdify scrape --sitemap https://docs.internal/sitemap.xml --selector main
+  MDIFY_USERNAME=me MDIFY_PASSWORD=... mdify scrape --selector main urls.txt
+  mdify scrape --cookies cookies.txt --header "X-Team: docs" --selector main urls.txt
+
+  # Skip pages that duplicate another, and list them in a run report
+  mdify scrape --sitemap https://example.com/sitemap.xml --selector main --dedup skip --report report.json
+
+  # Write a single archive, docs.zip, instead of a directory
+  mdify scrape --sitemap https://example.com/sitemap.xml --selector main --output-format zip
+
+  # Also export chunks for a vector store
+  mdify scrape --sitemap https://example.com/sitemap.xml --selector main --chunks chunks.jsonl`,
 		Args: cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			var urls []string
-			var err error
+			if err := progress.validate(); err != nil {
+				return err
+			}
+
+			values := Profile{
+				Sitemap:       sitemapURL,
+				Selector:      selector,
+				Exclude:       exclude,
+				Filter:        pathFilter,
+				Workers:       workers,
+				Output:        output,
+				TableFallback: tableFallback,
+				RateLimit:     rateLimit,
+				UserAgent:     userAgent,
+				Cookies:       cookies,
+				AuthHosts:     authHosts,
+				Proxy:         proxy,
+				CAFile:        caFile,
+				ClientCert:    clientCert,
+				ClientKey:     clientKey,
+				Insecure:      insecure,
+				Chunks:        chunks,
+				ChunkTokens:   chunkTokens,
+				ChunkOverlap:  chunkOverlap,
+				Dedup:         dedup,
+				NearDupBits:   nearDupBits,
+				Report:        reportPath,
+				OutputFormat:  outputFormat,
+			}
 
-			if sitemapURL != "" {
-				if len(args) > 0 {
+			if len(headers) > 0 {
+				values.Headers = make(map[string]string, len(headers))
+				for _, header := range headers {
+					name, value, err := httpclient.ParseHeader(header)
+					if err != nil {
+						return err
+					}
+					values.Headers[name] = value
+				}
+			}
+
+			if profileName == "" && !allProfiles {
+				if sitemapURL != "" && len(args) > 0 {
 					return fmt.Errorf("cannot use both sitemap and URL file")
 				}
-				urls, err = getURLsFromSitemap(sitemapURL, pathFilter)
+				return runProfile(values, args, progress)
+			}
+
+			if profileName != "" && allProfiles {
+				return fmt.Errorf("cannot use both --profile and --all-profiles")
+			}
+
+			config, err := loadConfigFile(configPath)
+			if err != nil {
+				return err
+			}
+
+			names := []string{profileName}
+			if allProfiles {
+				names = config.ProfileNames()
+				if len(names) == 0 {
+					return fmt.Errorf("no profiles defined in config file")
+				}
+			}
+
+			var failed []string
+			for _, name := range names {
+				profile, err := config.Profile(name)
 				if err != nil {
-					return fmt.Errorf("failed to get URLs from sitemap: %w", err)
+					return err
 				}
-			} else {
-				if len(args) == 0 {
-					urls, err = readURLsFromStdin()
-				} else {
-					urls, err = readURLsFromFile(args[0])
+				profile = profile.applyFlags(cmd.Flags(), values)
+
+				if !allProfiles {
+					return runProfile(profile, args, progress)
 				}
-				if err != nil {
-					return fmt.Errorf("failed to read URLs: %w", err)
+
+				logger.Info("Running profile", "profile", name)
+				if err := runProfile(profile, args, progress); err != nil {
+					logger.Error("Profile failed", "profile", name, "error", err)
+					failed = append(failed, name)
 				}
 			}
 
-			if len(urls) == 0 {
-				return fmt.Errorf("no URLs found to scrape")
+			if len(failed) > 0 {
+				return fmt.Errorf("%d of %d profiles failed: %s", len(failed), len(names), strings.Join(failed, ", "))
 			}
-
-			return runScrapeCommand(urls, selector, output, workers)
+			return nil
 		},
 	}
 
-	cmd.Flags().StringVarP(&selector, "selector", "s", "", "CSS selector for content extraction (required)")
-	cmd.Flags().StringVarP(&output, "output", "o", "./docs", "Output directory for markdown files")
+	cmd.Flags().StringVarP(&selector, "selector", "s", "", "CSS selector for content extraction (required unless set in a profile)")
+	cmd.Flags().StringVarP(&output, "output", "o", "./docs", "Output directory for markdown files, or the file for --output-format")
+	cmd.Flags().StringVar(&outputFormat, "output-format", formatDir, "Write pages to a directory, or a single zip, tar.gz or sqlite file")
 	cmd.Flags().StringVar(&sitemapURL, "sitemap", "", "URL to sitemap.xml file")
 	cmd.Flags().StringVar(&pathFilter, "filter", "", "Filter URLs containing this path (e.g. '/docs/')")
 	cmd.Flags().IntVarP(&workers, "workers", "w", 4, "Number of concurrent workers (default: 4, use 1 for sequential)")
-	cmd.MarkFlagRequired("selector")
+	cmd.Flags().StringVar(&tableFallback, "table-fallback", scraper.TableFallbackHTML, "How to render tables with merged cells or block content: html or list")
+	cmd.Flags().StringSliceVar(&exclude, "exclude", nil, "CSS selectors to remove from the extracted content (repeatable)")
+	cmd.Flags().DurationVar(&rateLimit, "rate-limit", 0, "Minimum delay between requests (e.g. 500ms)")
+	cmd.Flags().StringVar(&userAgent, "user-agent", defaultUserAgent, "User-Agent header sent with requests")
+	cmd.Flags().StringArrayVarP(&headers, "header", "H", nil, "Extra request header as \"Name: value\" (repeatable)")
+	cmd.Flags().StringVar(&cookies, "cookies", "", "Netscape cookies.txt file to send cookies from")
+	cmd.Flags().StringSliceVar(&authHosts, "auth-host", nil, "Only send headers, credentials and cookies to these hosts (default: the hosts of the sitemap and given URLs)")
+	cmd.Flags().StringVar(&proxy, "proxy", "", "Proxy URL (http://, https:// or socks5://); defaults to HTTP_PROXY/HTTPS_PROXY")
+	cmd.Flags().StringVar(&caFile, "ca-file", "", "PEM bundle of extra CA certificates to trust")
+	cmd.Flags().StringVar(&clientCert, "client-cert", "", "PEM client certificate for mutual TLS")
+	cmd.Flags().StringVar(&clientKey, "client-key", "", "PEM private key for --client-cert")
+	cmd.Flags().BoolVar(&insecure, "insecure", false, "Skip TLS certificate verification (unsafe)")
+	cmd.Flags().StringVarP(&configPath, "config", "c", "", "Config file with scrape profiles, YAML or TOML (default: mdify.yaml or mdify.toml)")
+	cmd.Flags().StringVar(&profileName, "profile", "", "Name of the profile to run from the config file")
+	cmd.Flags().BoolVar(&allProfiles, "all-profiles", false, "Run every profile in the config file")
+	cmd.Flags().StringVar(&progress.mode, "progress", progressAuto, "How to show progress: auto, line, log or off")
+	cmd.Flags().StringVar(&chunks, "chunks", "", "Also write chunks of every saved page to this JSONL file (\"-\" for stdout)")
+	cmd.Flags().IntVar(&chunkTokens, "chunk-max-tokens", chunk.DefaultMaxSize, "Estimated token budget per chunk for --chunks")
+	cmd.Flags().IntVar(&chunkOverlap, "chunk-overlap", chunk.DefaultOverlap, "Tokens repeated from the previous chunk for --chunks")
+	cmd.Flags().StringVar(&dedup, "dedup", scraper.DedupOff, "What to do with duplicate pages: off, record, skip or symlink")
+	cmd.Flags().IntVar(&nearDupBits, "near-duplicate-bits", scraper.DefaultNearDuplicateBits, "Simhash bits (of 64) near-duplicate pages may differ by; -1 to only catch exact duplicates")
+	cmd.Flags().StringVar(&reportPath, "report", "", "Write a JSON report of the run, with failures and duplicates, to this file")
+	cmd.Flags().StringVar(&progress.metricsAddr, "metrics-addr", "", "Serve Prometheus metrics for the run at this address (e.g. 127.0.0.1:9090)")
 
 	return cmd
 }
 
+// runProfile gathers the URLs for a profile and scrapes them
+func runProfile(profile Profile, args []string, progress progressOptions) error {
+	if err := profile.validate(); err != nil {
+		return err
+	}
+
+	if profile.Insecure {
+		logger.Warn("TLS certificate verification is disabled")
+	}
+
+	urls, given, err := collectURLs(profile, args)
+	if err != nil {
+		return err
+	}
+
+	if len(urls) == 0 {
+		return fmt.Errorf("no URLs found to scrape")
+	}
+
+	return runScrapeCommand(urls, profile.withAuthHosts(given), progress)
+}
+
+// collectURLs reads URLs from every source in the profile and the optional
+// urls file argument, falling back to stdin when there are no other sources.
+// It also returns the URLs given directly, rather than found in the sitemap.
+func collectURLs(profile Profile, args []string) ([]string, []string, error) {
+	var urls, given []string
+
+	if profile.Sitemap != "" {
+		sitemapURLs, err := getURLsFromSitemap(profile.Sitemap, profile.Filter, profile.httpOptions())
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get URLs from sitemap: %w", err)
+		}
+		urls = append(urls, sitemapURLs...)
+	}
+
+	given = append(given, profile.URLs...)
+
+	files := args
+	if profile.URLsFile != "" {
+		files = append([]string{profile.URLsFile}, files...)
+	}
+	for _, file := range files {
+		fileURLs, err := readURLsFromFile(file)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read URLs: %w", err)
+		}
+		given = append(given, fileURLs...)
+	}
+
+	if !profile.hasSources() && len(args) == 0 {
+		stdinURLs, err := readURLsFromStdin()
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read URLs: %w", err)
+		}
+		given = append(given, stdinURLs...)
+	}
+
+	return append(urls, given...), given, nil
+}
+
 func serveCmd() *cobra.Command {
 	var (
-		dir  string
-		port int
+		dir          string
+		port         int
+		addr         string
+		watch        bool
+		pollInterval time.Duration
+		upstream     string
+		accessLog    string
+		config       server.Config
 	)
 
 	cmd := &cobra.Command{
 		Use:   "serve",
 		Short: "Serve markdown files via HTTP",
-		Long:  `Start an HTTP server to serve the converted markdown files.`,
+		Long: `Start an HTTP server to serve the converted markdown files.
+
+With --watch, the server polls the directory for changes, disables caching
+and reloads open browser tabs whenever a file is added, removed or modified.
+
+With --upstream, the server runs as a reverse proxy in front of the original
+site: requests for .md URLs or with "Accept: text/markdown" get the converted
+file, everything else is forwarded to the upstream site.
+
+The server listens on 127.0.0.1 only unless --addr says otherwise, and drains
+open connections before exiting on SIGINT or SIGTERM.
+
+To share docs with a team, serve HTTPS with --tls-cert/--tls-key (or
+--tls-self-signed for development) and require credentials with --auth-token
+or --htpasswd. The token can also be set with MDIFY_AUTH_TOKEN.
+
+Every request is written to the access log as a JSON line. Prometheus metrics
+are served at /metrics, and /healthz and /readyz answer liveness and readiness
+probes without authentication.
+
+--dir can also name a zip archive written by "mdify scrape --output-format zip",
+or a binary made by "mdify bundle", which are served in place without
+extracting them.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			config.Addr = addr
+			if config.Addr == "" {
+				config.Addr = fmt.Sprintf("127.0.0.1:%d", port)
+			}
+			config.Watch = watch
+			config.PollInterval = pollInterval
+			config.Upstream = upstream
+			if token := os.Getenv(authTokenEnv); token != "" {
+				config.AuthTokens = append(config.AuthTokens, token)
+			}
+			switch accessLog {
+			case "":
+			case "-":
+				config.AccessLog = os.Stdout
+			default:
+				file, err := os.OpenFile(accessLog, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+				if err != nil {
+					return fmt.Errorf("failed to open access log: %w", err)
+				}
+				defer file.Close()
+				config.AccessLog = file
+			}
+			return runServeCommand(dir, config)
+		},
+	}
+
+	cmd.Flags().StringVarP(&dir, "dir", "d", "./docs", "Directory containing markdown files, or a zip archive or bundle of them")
+	cmd.Flags().IntVarP(&port, "port", "p", 8080, "Port to serve on at 127.0.0.1")
+	cmd.Flags().StringVar(&addr, "addr", "", "Listen address as host:port or unix:/path/to.sock (e.g. :8080 for all interfaces)")
+	cmd.Flags().DurationVar(&config.ReadTimeout, "read-timeout", 30*time.Second, "Maximum duration for reading a request")
+	cmd.Flags().DurationVar(&config.WriteTimeout, "write-timeout", 60*time.Second, "Maximum duration for writing a response")
+	cmd.Flags().DurationVar(&config.IdleTimeout, "idle-timeout", 120*time.Second, "How long idle keep-alive connections stay open")
+	cmd.Flags().DurationVar(&config.ShutdownTimeout, "shutdown-timeout", 10*time.Second, "How long to wait for open requests when shutting down")
+	cmd.Flags().StringVar(&config.TLSCertFile, "tls-cert", "", "PEM certificate to serve HTTPS with")
+	cmd.Flags().StringVar(&config.TLSKeyFile, "tls-key", "", "PEM private key for --tls-cert")
+	cmd.Flags().BoolVar(&config.SelfSignedTLS, "tls-self-signed", false, "Serve HTTPS with a certificate generated on startup (development only)")
+	cmd.Flags().StringArrayVar(&config.AuthTokens, "auth-token", nil, "Bearer token that grants access (repeatable)")
+	cmd.Flags().StringVar(&config.HtpasswdFile, "htpasswd", "", "htpasswd file (bcrypt) of users allowed in with basic auth")
+	cmd.Flags().BoolVarP(&watch, "watch", "w", false, "Watch for changes and live-reload browsers")
+	cmd.Flags().BoolVar(&config.UnsafeHTML, "unsafe-html", false, "Render raw HTML in the docs, such as HTML tables, instead of omitting it (trusted docs only)")
+	cmd.Flags().DurationVar(&pollInterval, "poll-interval", 0, "How often to check for changes (default 1s with --watch, 10s otherwise)")
+	cmd.Flags().StringVar(&upstream, "upstream", "", "Reverse-proxy non-markdown requests to this site (e.g. https://docs.example.com)")
+	cmd.Flags().StringVar(&accessLog, "access-log", "-", "Write JSON access logs to this file, \"-\" for stdout or \"\" to disable")
+
+	return cmd
+}
+
+func mcpCmd() *cobra.Command {
+	var (
+		dir      string
+		httpAddr string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "mcp",
+		Short: "Expose markdown files to AI agents over MCP",
+		Long: `Run a Model Context Protocol server over the converted markdown files.
+
+The documents are exposed as resources along with search_docs, read_doc and
+list_docs tools. The server speaks MCP over stdio unless --http is given.
+
+Examples:
+  # Stdio, for agents that launch the server themselves
+  mdify mcp --dir ./docs
+
+  # Streamable HTTP at http://127.0.0.1:8090/mcp
+  mdify mcp --dir ./docs --http 127.0.0.1:8090`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runMCPCommand(dir, httpAddr)
+		},
+	}
+
+	cmd.Flags().StringVarP(&dir, "dir", "d", "./docs", "Directory containing markdown files")
+	cmd.Flags().StringVar(&httpAddr, "http", "", "Serve streamable HTTP on this address instead of stdio (e.g. 127.0.0.1:8090 or unix:/run/mdify-mcp.sock)")
+
+	return cmd
+}
+
+func chunkCmd() *cobra.Command {
+	var (
+		dir       string
+		output    string
+		maxTokens int
+		maxChars  int
+		config    chunk.Config
+	)
+
+	cmd := &cobra.Command{
+		Use:   "chunk",
+		Short: "Split markdown files into chunks for RAG ingestion",
+		Long: `Split every markdown file into chunks and export them as JSONL.
+
+Documents are split at their headings, and sections over the budget are split
+again at paragraphs, with the end of each chunk repeated at the start of the
+next. Every record has an id, the source URL, the file path, the heading
+breadcrumb and the text.
+
+Examples:
+  mdify chunk --dir ./docs > chunks.jsonl
+  mdify chunk --dir ./docs --base-url https://docs.example.com --output chunks.jsonl
+  mdify chunk --dir ./docs --max-chars 2000 --overlap 200`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runServeCommand(dir, port)
+			if cmd.Flags().Changed("max-tokens") && cmd.Flags().Changed("max-chars") {
+				return fmt.Errorf("cannot use both --max-tokens and --max-chars")
+			}
+			config.Unit, config.MaxSize = chunk.UnitTokens, maxTokens
+			if cmd.Flags().Changed("max-chars") {
+				config.Unit, config.MaxSize = chunk.UnitChars, maxChars
+			}
+			if err := config.Validate(); err != nil {
+				return err
+			}
+			return runChunkCommand(dir, output, config)
 		},
 	}
 
 	cmd.Flags().StringVarP(&dir, "dir", "d", "./docs", "Directory containing markdown files")
-	cmd.Flags().IntVarP(&port, "port", "p", 8080, "Port to serve on")
+	cmd.Flags().StringVarP(&output, "output", "o", "-", "JSONL file to write, \"-\" for stdout")
+	cmd.Flags().StringVar(&config.BaseURL, "base-url", "", "URL the directory was scraped from, used for each chunk's source URL")
+	cmd.Flags().IntVar(&maxTokens, "max-tokens", chunk.DefaultMaxSize, "Estimated token budget per chunk")
+	cmd.Flags().IntVar(&maxChars, "max-chars", 0, "Character budget per chunk, instead of tokens")
+	cmd.Flags().IntVar(&config.Overlap, "overlap", chunk.DefaultOverlap, "Text repeated from the end of the previous chunk, in the budget's unit")
+
+	return cmd
+}
+
+func statsCmd() *cobra.Command {
+	var (
+		dir       string
+		format    string
+		tokenizer string
+		config    stats.Config
+	)
+
+	cmd := &cobra.Command{
+		Use:   "stats",
+		Short: "Report word and token counts for converted docs",
+		Long: `Report word and approximate token counts for every markdown file, with the
+largest files, pages with duplicate content and pages that are empty.
+
+Use --context to check whether the whole corpus fits in a model's context window.
+
+Examples:
+  mdify stats --dir ./docs
+  mdify stats --dir ./docs --context 200000
+  mdify stats --dir ./docs --format json --tokenizer chars`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if format != stats.FormatTable && format != stats.FormatJSON {
+				return fmt.Errorf("invalid format %q (use table or json)", format)
+			}
+			t, err := stats.LookupTokenizer(tokenizer)
+			if err != nil {
+				return err
+			}
+			config.Tokenizer = t
+			return runStatsCommand(dir, format, config)
+		},
+	}
+
+	cmd.Flags().StringVarP(&dir, "dir", "d", "./docs", "Directory containing markdown files")
+	cmd.Flags().StringVarP(&format, "format", "f", stats.FormatTable, "Output format: table or json")
+	cmd.Flags().StringVar(&tokenizer, "tokenizer", "bpe", "Token estimator: bpe or chars")
+	cmd.Flags().IntVar(&config.Top, "top", stats.DefaultTop, "Number of largest files to list")
+	cmd.Flags().IntVar(&config.ContextWindow, "context", 0, "Context window in tokens to check the corpus against")
+
+	return cmd
+}
+
+func bundleCmd() *cobra.Command {
+	var (
+		dir    string
+		output string
+		binary string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "bundle",
+		Short: "Build a standalone server binary with the docs embedded",
+		Long: `Build a copy of the mdify binary with a markdown tree appended to it. The
+result needs nothing else on disk: running it serves the bundled docs like
+"mdify serve", with the same flags, search included. Hidden files are left
+out.
+
+Use --binary to bundle onto an mdify binary built for another platform, for
+example to ship docs to machines without network access.
+
+Examples:
+  mdify bundle --dir ./docs -o docs-server
+  ./docs-server --port 8080
+  mdify bundle --dir ./docs --binary ./mdify-windows-amd64.exe -o docs-server.exe`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runBundleCommand(dir, output, binary)
+		},
+	}
+
+	cmd.Flags().StringVarP(&dir, "dir", "d", "./docs", "Directory containing markdown files")
+	cmd.Flags().StringVarP(&output, "output", "o", "docs-server", "Binary to write")
+	cmd.Flags().StringVar(&binary, "binary", "", "mdify binary to bundle the docs onto (default: this one)")
+
+	return cmd
+}
+
+func diffCmd() *cobra.Command {
+	var (
+		format   string
+		exitCode bool
+		config   diff.Config
+	)
+
+	cmd := &cobra.Command{
+		Use:   "diff <old-dir> <new-dir>",
+		Short: "Compare two scrapes of the same docs",
+		Long: `Compare two scrapes of the same docs and report the pages that were added,
+removed and modified, the sections of each modified page that changed (by
+heading), and a unified diff per page. Either side may also be a zip archive
+or a binary made by "mdify bundle".
+
+Use --format json for a machine-readable report, for example to post a
+summary from CI when the upstream docs change, and --exit-code to fail the
+step when anything changed.
+
+Examples:
+  mdify diff ./docs-old ./docs
+  mdify diff ./docs-old ./docs --no-diff
+  mdify diff docs-2024-05.zip ./docs --format json --exit-code`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if format != diff.FormatText && format != diff.FormatJSON {
+				return fmt.Errorf("invalid format %q (use text or json)", format)
+			}
+			if config.Context < 0 {
+				return fmt.Errorf("invalid --unified %d (must not be negative)", config.Context)
+			}
+			changed, err := runDiffCommand(args[0], args[1], format, config, os.Stdout)
+			if err != nil {
+				return err
+			}
+			if exitCode && changed {
+				// a finding, not a usage mistake
+				cmd.SilenceUsage = true
+				cmd.SilenceErrors = true
+				return errDocsChanged
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&format, "format", "f", diff.FormatText, "Output format: text or json")
+	cmd.Flags().IntVarP(&config.Context, "unified", "U", diff.DefaultContext, "Unchanged lines to show around each change")
+	cmd.Flags().BoolVar(&config.NoDiff, "no-diff", false, "Only summarize changed pages and sections, without line diffs")
+	cmd.Flags().BoolVar(&exitCode, "exit-code", false, "Exit with status 1 when any page changed")
 
 	return cmd
 }
@@ -159,37 +658,258 @@ func (s RealSleeper) Sleep(duration time.Duration) {
 	time.Sleep(duration)
 }
 
-type RealLogger struct{}
-
-func (l RealLogger) Printf(format string, v ...interface{}) {
-	fmt.Printf(format+"\n", v...)
-}
-
-func runScrapeCommand(urls []string, selector, output string, workers int) error {
-	client := &http.Client{Timeout: 30 * time.Second}
+func runScrapeCommand(urls []string, profile Profile, options progressOptions) error {
+	client, err := httpclient.New(profile.httpOptions())
+	if err != nil {
+		return err
+	}
 	fs := filesystem.OSFileSystem{}
 	sleeper := RealSleeper{}
-	logger := RealLogger{}
+	progress := scraper.NewProgress()
+
+	var scrapeLogger scraper.Logger = logger
+	if options.mode != progressOff {
+		display := scraper.NewProgressDisplay(progress, os.Stderr, logger, options.interactive())
+		scrapeLogger = display
+
+		stop := make(chan struct{})
+		stopped := make(chan struct{})
+		go func() {
+			display.Run(stop)
+			close(stopped)
+		}()
+		defer func() {
+			close(stop)
+			<-stopped
+		}()
+	}
+
+	if options.metricsAddr != "" {
+		addr, stopMetrics, err := serveScrapeMetrics(options.metricsAddr, progress)
+		if err != nil {
+			return err
+		}
+		defer stopMetrics()
+		scrapeLogger.Info("Serving scrape metrics", "url", "http://"+addr+"/metrics")
+	}
+
+	var chunkWriter scraper.ChunkWriter
+	if profile.Chunks != "" {
+		out, err := createOutput(profile.Chunks)
+		if err != nil {
+			return err
+		}
+		defer out.Close()
+
+		chunkConfig := chunk.Config{MaxSize: profile.ChunkTokens, Overlap: profile.ChunkOverlap, Unit: chunk.UnitTokens}
+		writer := chunk.NewService(fs, logger, chunkConfig).NewWriter(out)
+		defer func() {
+			logger.Info("Wrote chunks", "file", profile.Chunks, "chunks", writer.Count())
+		}()
+		chunkWriter = writer
+	}
+
+	out, err := openOutput(profile.OutputFormat, profile.Output)
+	if err != nil {
+		return err
+	}
+
 	config := scraper.Config{
-		Timeout:    30 * time.Second,
-		MaxRetries: 3,
-		Workers:    workers,
+		Timeout:           30 * time.Second,
+		MaxRetries:        3,
+		Workers:           profile.Workers,
+		TableFallback:     profile.TableFallback,
+		Exclude:           profile.Exclude,
+		RateLimit:         profile.RateLimit,
+		Progress:          progress,
+		Chunks:            chunkWriter,
+		Dedup:             profile.Dedup,
+		NearDuplicateBits: profile.NearDupBits,
+	}
+
+	service := scraper.NewService(client, out.fs, sleeper, scrapeLogger, config)
+	err = service.ScrapeURLs(urls, profile.Selector, out.dir)
+	if closeErr := out.close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	if profile.OutputFormat != formatDir {
+		logger.Info("Wrote output", "format", profile.OutputFormat, "file", out.path)
 	}
 
-	service := scraper.NewService(client, fs, sleeper, logger, config)
-	return service.ScrapeURLs(urls, selector, output)
+	if profile.Report != "" {
+		if err := writeRunReport(profile.Report, service.Report()); err != nil {
+			return err
+		}
+		logger.Info("Wrote run report", "file", profile.Report)
+	}
+	return nil
 }
 
-func getURLsFromSitemap(sitemapURL, pathFilter string) ([]string, error) {
-	client := &http.Client{Timeout: 30 * time.Second}
-	logger := RealLogger{}
+// writeRunReport writes a scrape run's report to path
+func writeRunReport(path string, report scraper.RunReport) error {
+	out, err := createOutput(path)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	if err := report.Write(out); err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+	return nil
+}
+
+func getURLsFromSitemap(sitemapURL, pathFilter string, options httpclient.Options) ([]string, error) {
+	client, err := httpclient.New(options)
+	if err != nil {
+		return nil, err
+	}
 	service := sitemap.NewService(client, logger)
 	return service.GetURLsFromSitemap(sitemapURL, pathFilter)
 }
 
-func runServeCommand(dir string, port int) error {
+func runServeCommand(dir string, config server.Config) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	fs, closeDocs, err := openDocs(dir)
+	if err != nil {
+		return err
+	}
+	defer closeDocs()
+
+	service := server.NewService(fs, logger, config)
+	return service.ServeMarkdownFiles(ctx, dir)
+}
+
+func runBundleCommand(dir, output, binary string) error {
+	if binary == "" {
+		exe, err := os.Executable()
+		if err != nil {
+			return fmt.Errorf("failed to find the mdify binary: %w", err)
+		}
+		binary = exe
+	}
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		return fmt.Errorf("directory does not exist: %s", dir)
+	}
+
+	exe, err := os.Open(binary)
+	if err != nil {
+		return fmt.Errorf("failed to open binary: %w", err)
+	}
+	defer exe.Close()
+	exeInfo, err := exe.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to open binary: %w", err)
+	}
+	if outInfo, err := os.Stat(output); err == nil && os.SameFile(exeInfo, outInfo) {
+		return fmt.Errorf("output %s would overwrite the binary being bundled", output)
+	}
+
+	out, err := os.OpenFile(output, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0755)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", output, err)
+	}
+	files, err := filesystem.WriteBundle(out, exe, exeInfo.Size(), filesystem.OSFileSystem{}, dir)
+	if err != nil {
+		out.Close()
+		os.Remove(output)
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", output, err)
+	}
+
+	logger.Info("Bundled docs", "dir", dir, "files", files, "output", output)
+	return nil
+}
+
+func runStatsCommand(dir, format string, config stats.Config) error {
 	fs := filesystem.OSFileSystem{}
-	logger := RealLogger{}
-	service := server.NewService(fs, logger)
-	return service.ServeMarkdownFiles(dir, port)
+	service := stats.NewService(fs, logger, config)
+
+	report, err := service.Collect(dir)
+	if err != nil {
+		return err
+	}
+	return report.Write(os.Stdout, format)
+}
+
+// errDocsChanged fails "mdify diff --exit-code" when the scrapes differ
+var errDocsChanged = errors.New("docs changed")
+
+// runDiffCommand writes the changes from oldDir to newDir and reports
+// whether there were any
+func runDiffCommand(oldDir, newDir, format string, config diff.Config, w io.Writer) (bool, error) {
+	oldDocs, closeOld, err := openDocs(oldDir)
+	if err != nil {
+		return false, err
+	}
+	defer closeOld()
+	newDocs, closeNew, err := openDocs(newDir)
+	if err != nil {
+		return false, err
+	}
+	defer closeNew()
+
+	fs := filesystem.NewOverlay(oldDocs, newDocs)
+	service := diff.NewService(fs, logger, config)
+
+	report, err := service.Compare(oldDir, newDir)
+	if err != nil {
+		return false, err
+	}
+	return report.Changed(), report.Write(w, format)
+}
+
+func runChunkCommand(dir, output string, config chunk.Config) error {
+	fs := filesystem.OSFileSystem{}
+	service := chunk.NewService(fs, logger, config)
+
+	out, err := createOutput(output)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	_, err = service.ChunkDir(dir, out)
+	return err
+}
+
+// createOutput creates the file at path, or returns stdout for "-"
+func createOutput(path string) (io.WriteCloser, error) {
+	if path == "-" {
+		return nopCloser{os.Stdout}, nil
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", path, err)
+	}
+	return file, nil
+}
+
+// nopCloser keeps stdout open when an output is closed
+type nopCloser struct {
+	io.Writer
+}
+
+func (nopCloser) Close() error { return nil }
+
+func runMCPCommand(dir, httpAddr string) error {
+	fs := filesystem.OSFileSystem{}
+	service := mcp.NewService(fs, logger, version)
+	if err := service.Open(dir); err != nil {
+		return err
+	}
+
+	if httpAddr != "" {
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		return service.ListenAndServe(ctx, httpAddr)
+	}
+	return service.ServeStdio(os.Stdin, os.Stdout)
 }
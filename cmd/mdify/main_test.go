@@ -1,8 +1,19 @@
 package main
 
 import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
+
+	"mdify/pkg/diff"
+	"mdify/pkg/server"
 )
 
 func TestReadURLsFromStdin(t *testing.T) {
@@ -43,7 +54,7 @@ func TestReadURLsFromFile(t *testing.T) {
 
 func TestRunScrapeCommand(t *testing.T) {
 	t.Run("empty URLs list", func(t *testing.T) {
-		err := runScrapeCommand([]string{}, ".content", "./test_output", 1)
+		err := runScrapeCommand([]string{}, Profile{Selector: ".content", Output: "./test_output", Workers: 1}, progressOptions{mode: progressOff})
 		if err != nil {
 			t.Errorf("unexpected error for empty URLs: %v", err)
 		}
@@ -55,7 +66,7 @@ func TestRunScrapeCommand(t *testing.T) {
 
 func TestRunServeCommand(t *testing.T) {
 	t.Run("non-existent directory", func(t *testing.T) {
-		err := runServeCommand("/non/existent/path", 8080)
+		err := runServeCommand("/non/existent/path", server.Config{Addr: "127.0.0.1:0"})
 		if err == nil {
 			t.Errorf("expected error for non-existent directory")
 		}
@@ -63,4 +74,267 @@ func TestRunServeCommand(t *testing.T) {
 	
 	// Note: Testing the actual server startup would require more complex setup
 	// to avoid blocking the test or binding to actual ports
-}
\ No newline at end of file
+}
+func TestCollectURLs_AuthHosts(t *testing.T) {
+	docs := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+	<url><loc>http://%s/guide</loc></url>
+	<url><loc>https://foreign.example/page</loc></url>
+</urlset>`, r.Host)
+	}))
+	defer docs.Close()
+
+	urlsFile := filepath.Join(t.TempDir(), "urls.txt")
+	os.WriteFile(urlsFile, []byte("https://files.example/a\n"), 0644)
+
+	profile := Profile{Sitemap: docs.URL + "/sitemap.xml", URLs: []string{"https://listed.example/b"}}
+	urls, given, err := collectURLs(profile, []string{urlsFile})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(urls) != 4 {
+		t.Errorf("expected the sitemap's and the given URLs, got %v", urls)
+	}
+
+	expected := []string{"127.0.0.1", "listed.example", "files.example"}
+	if hosts := profile.withAuthHosts(given).httpOptions().AuthHosts; !reflect.DeepEqual(hosts, expected) {
+		t.Errorf("expected credentials only for the given hosts %v, got %v", expected, hosts)
+	}
+}
+
+func TestLogOptions(t *testing.T) {
+	defer func(original *slog.Logger) { logger = original }(logger)
+
+	tests := []struct {
+		name          string
+		options       logOptions
+		expectedError string
+		checkLine     func(t *testing.T, line string)
+	}{
+		{
+			name:    "json to file",
+			options: logOptions{level: "info", format: "json"},
+			checkLine: func(t *testing.T, line string) {
+				var entry map[string]interface{}
+				if err := json.Unmarshal([]byte(line), &entry); err != nil {
+					t.Fatalf("expected JSON log line, got %q", line)
+				}
+				if entry["level"] != "ERROR" || entry["msg"] != "Scrape failed" || entry["url"] != "https://example.com/a" {
+					t.Errorf("unexpected entry: %v", entry)
+				}
+			},
+		},
+		{
+			name:    "text with level filter",
+			options: logOptions{level: "WARN", format: "text"},
+			checkLine: func(t *testing.T, line string) {
+				if !strings.Contains(line, `level=ERROR msg="Scrape failed" url=https://example.com/a`) {
+					t.Errorf("unexpected text line %q", line)
+				}
+			},
+		},
+		{name: "invalid level", options: logOptions{level: "verbose", format: "text"}, expectedError: "invalid --log-level"},
+		{name: "invalid format", options: logOptions{level: "info", format: "xml"}, expectedError: "invalid --log-format"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.options.file = filepath.Join(t.TempDir(), "mdify.log")
+			err := tt.options.apply()
+			defer tt.options.close()
+
+			if tt.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectedError) {
+					t.Errorf("expected error containing %q, got %v", tt.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			logger.Info("Saved", "url", "https://example.com/b")
+			logger.Error("Scrape failed", "url", "https://example.com/a")
+
+			content, err := os.ReadFile(tt.options.file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+			if tt.options.level == "WARN" && len(lines) != 1 {
+				t.Fatalf("expected info messages to be filtered, got %q", lines)
+			}
+			tt.checkLine(t, lines[len(lines)-1])
+		})
+	}
+}
+
+func TestOpenOutput(t *testing.T) {
+	tests := []struct {
+		name         string
+		format       string
+		output       string
+		expectedPath string
+	}{
+		{name: "directory", format: formatDir, output: "docs", expectedPath: "docs"},
+		{name: "zip adds extension", format: formatZip, output: "out/docs", expectedPath: "out/docs.zip"},
+		{name: "tgz keeps extension", format: formatTarGz, output: "docs.tgz", expectedPath: "docs.tgz"},
+		{name: "sqlite keeps extension", format: formatSQLite, output: "docs.sqlite3", expectedPath: "docs.sqlite3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			out, err := openOutput(tt.format, filepath.Join(dir, tt.output))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if err := out.close(); err != nil {
+				t.Fatalf("unexpected error closing output: %v", err)
+			}
+			if out.path != filepath.Join(dir, tt.expectedPath) {
+				t.Errorf("expected path %s, got %s", filepath.Join(dir, tt.expectedPath), out.path)
+			}
+			if tt.format != formatDir {
+				if _, err := os.Stat(out.path); err != nil {
+					t.Errorf("expected %s to be written: %v", out.path, err)
+				}
+			}
+		})
+	}
+
+	if _, err := openOutput("rar", filepath.Join(t.TempDir(), "docs")); err == nil {
+		t.Error("expected error for invalid output format")
+	}
+}
+
+func TestOpenDocs(t *testing.T) {
+	dir := t.TempDir()
+	out, err := openOutput(formatZip, filepath.Join(dir, "docs"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	writer, _ := out.fs.Create("guide/setup.md")
+	writer.Write([]byte("# Setup"))
+	writer.Close()
+	if err := out.close(); err != nil {
+		t.Fatalf("unexpected error closing output: %v", err)
+	}
+	htpasswd := filepath.Join(dir, "htpasswd")
+	os.WriteFile(htpasswd, []byte("admin:$2y$05$hash"), 0600)
+	cert := filepath.Join(dir, "tls", "cert.pem")
+	os.MkdirAll(filepath.Dir(cert), 0755)
+	os.WriteFile(cert, []byte("-----BEGIN CERTIFICATE-----"), 0600)
+
+	fs, closeDocs, err := openDocs(out.path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer closeDocs()
+	if data, err := fs.ReadFile(filepath.Join(out.path, "guide", "setup.md")); err != nil || string(data) != "# Setup" {
+		t.Errorf("expected the page from the zip, got %q, %v", data, err)
+	}
+	for _, path := range []string{htpasswd, cert} {
+		if _, err := fs.ReadFile(path); err != nil {
+			t.Errorf("expected %s to be read from disk while serving a zip: %v", path, err)
+		}
+	}
+	if _, err := fs.ReadFile(filepath.Join(out.path, "missing.md")); err == nil {
+		t.Error("expected an error for a page missing from the zip")
+	}
+
+	if fs, _, err := openDocs(dir); err != nil {
+		t.Errorf("unexpected error for a directory: %v", err)
+	} else if data, err := fs.ReadFile(htpasswd); err != nil || len(data) == 0 {
+		t.Errorf("expected a directory to be served from disk, got %q, %v", data, err)
+	}
+	if _, _, err := openDocs(htpasswd); err == nil {
+		t.Error("expected an error serving a file that isn't an archive")
+	}
+}
+
+func TestRunBundleCommand(t *testing.T) {
+	dir := t.TempDir()
+	docs := filepath.Join(dir, "docs")
+	os.MkdirAll(filepath.Join(docs, "guide"), 0755)
+	os.WriteFile(filepath.Join(docs, "index.md"), []byte("# Home"), 0644)
+	os.WriteFile(filepath.Join(docs, "guide", "setup.md"), []byte("# Setup"), 0644)
+	binary := filepath.Join(dir, "mdify")
+	os.WriteFile(binary, []byte("pretend mdify binary"), 0755)
+
+	output := filepath.Join(dir, "docs-server")
+	if err := runBundleCommand(docs, output, binary); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if info, err := os.Stat(output); err != nil || info.Mode().Perm()&0100 == 0 {
+		t.Errorf("expected an executable output, got %v", err)
+	}
+
+	fs, closeDocs, err := openDocs(output)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer closeDocs()
+	if data, err := fs.ReadFile(filepath.Join(output, "guide", "setup.md")); err != nil || string(data) != "# Setup" {
+		t.Errorf("expected the bundled page, got %q, %v", data, err)
+	}
+	if data, err := fs.ReadFile(filepath.Join(docs, "index.md")); err != nil || string(data) != "# Home" {
+		t.Errorf("expected files outside the bundle to be read from disk, got %q, %v", data, err)
+	}
+
+	if _, _, err := openDocs(binary); err == nil {
+		t.Error("expected an error serving a file without bundled docs")
+	}
+	if err := runBundleCommand(docs, binary, binary); err == nil {
+		t.Error("expected an error bundling onto the output itself")
+	}
+	if err := runBundleCommand(filepath.Join(dir, "missing"), output, binary); err == nil {
+		t.Error("expected an error for a missing directory")
+	}
+}
+
+func TestBundledCmd(t *testing.T) {
+	cmd := bundledCmd("/opt/docs-server")
+	if cmd.Use != "docs-server" {
+		t.Errorf("expected the command to be named after the binary, got %q", cmd.Use)
+	}
+	dir := cmd.Flags().Lookup("dir")
+	if dir.Value.String() != "/opt/docs-server" || !dir.Hidden {
+		t.Errorf("expected a hidden --dir pointing at the binary, got %q", dir.Value.String())
+	}
+}
+
+func TestRunDiffCommand(t *testing.T) {
+	dir := t.TempDir()
+	oldDocs := filepath.Join(dir, "old")
+	newDocs := filepath.Join(dir, "new")
+	os.MkdirAll(oldDocs, 0755)
+	os.MkdirAll(newDocs, 0755)
+	os.WriteFile(filepath.Join(oldDocs, "index.md"), []byte("# Home\n\n## Install\n\nUse apt.\n"), 0644)
+	os.WriteFile(filepath.Join(newDocs, "index.md"), []byte("# Home\n\n## Install\n\nUse dnf.\n"), 0644)
+
+	var out strings.Builder
+	changed, err := runDiffCommand(oldDocs, newDocs, diff.FormatJSON, diff.Config{Context: diff.DefaultContext}, &out)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !changed {
+		t.Error("expected changes to be reported")
+	}
+
+	var report diff.Report
+	if err := json.Unmarshal([]byte(out.String()), &report); err != nil {
+		t.Fatalf("expected a JSON report: %v", err)
+	}
+	if report.Summary.Modified != 1 || len(report.Files) != 1 || report.Files[0].Sections.Modified[0] != "Home > Install" {
+		t.Errorf("unexpected report %+v", report)
+	}
+
+	changed, err = runDiffCommand(newDocs, newDocs, diff.FormatText, diff.Config{}, &out)
+	if err != nil || changed {
+		t.Errorf("expected no changes comparing a scrape with itself, got %v, %v", changed, err)
+	}
+	if _, err := runDiffCommand(oldDocs, filepath.Join(dir, "missing"), diff.FormatText, diff.Config{}, &out); err == nil {
+		t.Error("expected an error for a missing directory")
+	}
+}
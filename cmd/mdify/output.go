@@ -0,0 +1,118 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"mdify/internal/filesystem"
+	"mdify/pkg/scraper"
+	"mdify/pkg/server"
+)
+
+// Output formats for scraped pages
+const (
+	formatDir    = "dir"
+	formatZip    = "zip"
+	formatTarGz  = "tar.gz"
+	formatSQLite = "sqlite"
+)
+
+// outputExtensions are the file extensions of the single-file formats; the
+// first is added to --output when it has none of them
+var outputExtensions = map[string][]string{
+	formatZip:    {".zip"},
+	formatTarGz:  {".tar.gz", ".tgz"},
+	formatSQLite: {".db", ".sqlite", ".sqlite3"},
+}
+
+func validOutputFormat(format string) bool {
+	_, ok := outputExtensions[format]
+	return ok || format == formatDir
+}
+
+// scrapeOutput is where a scrape run writes its pages
+type scrapeOutput struct {
+	fs    scraper.FileSystem
+	dir   string // output directory passed to the scraper
+	path  string // directory or file written
+	close func() error
+}
+
+// openOutput prepares the directory, archive or database a run writes to
+func openOutput(format, output string) (*scrapeOutput, error) {
+	if format == formatDir || format == "" {
+		return &scrapeOutput{
+			fs:    filesystem.OSFileSystem{},
+			dir:   output,
+			path:  output,
+			close: func() error { return nil },
+		}, nil
+	}
+
+	extensions, ok := outputExtensions[format]
+	if !ok {
+		return nil, fmt.Errorf("invalid output format %q (use dir, zip, tar.gz or sqlite)", format)
+	}
+	path := output
+	if !hasExtension(path, extensions) {
+		path += extensions[0]
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create directory for %s: %w", path, err)
+	}
+
+	if format == formatSQLite {
+		db, err := filesystem.OpenSQLite(path)
+		if err != nil {
+			return nil, err
+		}
+		return &scrapeOutput{fs: db, dir: ".", path: path, close: db.Close}, nil
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", path, err)
+	}
+	archive := filesystem.NewZipArchive(file)
+	if format == formatTarGz {
+		archive = filesystem.NewTarGzArchive(file)
+	}
+	return &scrapeOutput{fs: archive, dir: ".", path: path, close: archive.Close}, nil
+}
+
+// openDocs returns the file system to serve dir from: the disk for a
+// directory, or the archive for a zip file or a binary made by "mdify
+// bundle", over the disk so TLS keys and htpasswd files are still found
+func openDocs(dir string) (server.FileSystem, func() error, error) {
+	disk := filesystem.OSFileSystem{}
+	info, err := os.Stat(dir)
+	if err != nil || info.IsDir() {
+		return disk, func() error { return nil }, nil
+	}
+
+	var archive *filesystem.ZipFileSystem
+	if hasExtension(dir, outputExtensions[formatZip]) {
+		archive, err = filesystem.OpenZip(dir)
+	} else {
+		archive, err = filesystem.OpenBundle(dir)
+	}
+	if errors.Is(err, filesystem.ErrNoBundle) {
+		return nil, nil, fmt.Errorf("%s is not a directory, zip archive or bundle", dir)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return filesystem.NewOverlay(archive, disk), archive.Close, nil
+}
+
+func hasExtension(path string, extensions []string) bool {
+	for _, extension := range extensions {
+		if strings.HasSuffix(strings.ToLower(path), extension) {
+			return true
+		}
+	}
+	return false
+}
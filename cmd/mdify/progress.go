@@ -0,0 +1,71 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+
+	"mdify/pkg/scraper"
+)
+
+// Progress display modes for the scrape command
+const (
+	progressAuto = "auto" // a status line on terminals, log lines otherwise
+	progressLine = "line"
+	progressLog  = "log"
+	progressOff  = "off"
+)
+
+// progressOptions control how a scrape run reports its progress
+type progressOptions struct {
+	mode        string
+	metricsAddr string
+}
+
+func (o progressOptions) validate() error {
+	switch o.mode {
+	case progressAuto, progressLine, progressLog, progressOff:
+		return nil
+	}
+	return fmt.Errorf("invalid --progress %q: use auto, line, log or off", o.mode)
+}
+
+// interactive reports whether progress should be drawn as a status line
+func (o progressOptions) interactive() bool {
+	switch o.mode {
+	case progressLine:
+		return true
+	case progressAuto:
+		return isTerminal(os.Stderr)
+	}
+	return false
+}
+
+// isTerminal reports whether f is a terminal that understands cursor control
+func isTerminal(f *os.File) bool {
+	if os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// serveScrapeMetrics exposes a run's progress at /metrics until the returned
+// function is called
+func serveScrapeMetrics(addr string, progress *scraper.Progress) (string, func(), error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", progress)
+	srv := &http.Server{Handler: mux}
+	go srv.Serve(listener)
+
+	return listener.Addr().String(), func() { srv.Close() }, nil
+}
@@ -0,0 +1,169 @@
+package docs
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// FileSystem is the part of a file system documents are listed and read from
+type FileSystem interface {
+	ReadFile(filename string) ([]byte, error)
+	ReadDir(name string) ([]os.DirEntry, error)
+}
+
+// Document is a markdown file in a docs directory
+type Document struct {
+	Path  string `json:"path"`  // URL path the document is served at
+	File  string `json:"file"`  // slash-separated path relative to the docs directory
+	Title string `json:"title"` // front matter title or first heading
+}
+
+// List returns every markdown document below baseDir with its title
+func List(fs FileSystem, baseDir string) ([]Document, error) {
+	files, err := Walk(fs, baseDir)
+	if err != nil {
+		return nil, err
+	}
+
+	documents := make([]Document, 0, len(files))
+	for _, file := range files {
+		doc := Document{Path: URL(file.Rel), File: file.Rel}
+		if content, err := fs.ReadFile(file.Path); err == nil {
+			doc.Title = Title(content)
+		}
+		documents = append(documents, doc)
+	}
+	return documents, nil
+}
+
+// ResolvePath maps a slash-separated request path onto baseDir, reporting
+// false for paths that would escape it
+func ResolvePath(baseDir, requestPath string) (string, bool) {
+	filePath := filepath.Join(baseDir, filepath.FromSlash(requestPath))
+	if filePath != baseDir && !strings.HasPrefix(filePath, baseDir+string(filepath.Separator)) {
+		return "", false
+	}
+	return filePath, true
+}
+
+// IsHidden reports whether any segment of a slash-separated request path is
+// a dotfile; hidden files and directories are never served
+func IsHidden(requestPath string) bool {
+	for _, segment := range strings.Split(requestPath, "/") {
+		// "." and ".." are left to ResolvePath, which rejects paths escaping the root
+		if strings.HasPrefix(segment, ".") && segment != "." && segment != ".." {
+			return true
+		}
+	}
+	return false
+}
+
+// File is a markdown file found while walking a docs directory
+type File struct {
+	Path    string // path on disk
+	Rel     string // slash-separated path relative to the docs directory
+	Size    int64
+	ModTime int64 // in nanoseconds since the epoch
+}
+
+// Walk returns every markdown file below dir, skipping hidden entries
+func Walk(fs FileSystem, dir string) ([]File, error) {
+	var files []File
+
+	var walk func(dir, rel string) error
+	walk = func(dir, rel string) error {
+		entries, err := fs.ReadDir(dir)
+		if err != nil {
+			return fmt.Errorf("failed to read directory %s: %w", dir, err)
+		}
+
+		for _, entry := range entries {
+			name := entry.Name()
+			if strings.HasPrefix(name, ".") {
+				continue
+			}
+
+			if entry.IsDir() {
+				if err := walk(filepath.Join(dir, name), path.Join(rel, name)); err != nil {
+					return err
+				}
+				continue
+			}
+
+			if !strings.HasSuffix(name, ".md") {
+				continue
+			}
+
+			file := File{Path: filepath.Join(dir, name), Rel: path.Join(rel, name)}
+			if info, err := entry.Info(); err == nil {
+				file.Size = info.Size()
+				file.ModTime = info.ModTime().UnixNano()
+			}
+			files = append(files, file)
+		}
+		return nil
+	}
+
+	if err := walk(dir, ""); err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+// URL returns the URL path a markdown file is served at
+func URL(rel string) string {
+	rel = strings.TrimSuffix(rel, ".md")
+	if rel == "index" {
+		return "/"
+	}
+	if dir, ok := strings.CutSuffix(rel, "/index"); ok {
+		return "/" + dir + "/"
+	}
+	return "/" + rel
+}
+
+// Title returns the title from a document's front matter, or its first
+// level-one heading
+func Title(content []byte) string {
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+
+	inFrontMatter := false
+	inCodeBlock := false
+	for lineNumber := 0; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+
+		if lineNumber == 0 && line == "---" {
+			inFrontMatter = true
+			continue
+		}
+		if inFrontMatter {
+			if line == "---" {
+				inFrontMatter = false
+			} else if value, ok := strings.CutPrefix(line, "title:"); ok {
+				if title := strings.Trim(strings.TrimSpace(value), `"'`); title != "" {
+					return title
+				}
+			}
+			continue
+		}
+
+		if strings.HasPrefix(line, "```") || strings.HasPrefix(line, "~~~") {
+			inCodeBlock = !inCodeBlock
+			continue
+		}
+		if inCodeBlock {
+			continue
+		}
+
+		if title, ok := strings.CutPrefix(line, "# "); ok {
+			return strings.TrimSpace(title)
+		}
+	}
+
+	return ""
+}
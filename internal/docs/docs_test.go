@@ -0,0 +1,123 @@
+package docs
+
+import (
+	"reflect"
+	"testing"
+
+	"mdify/internal/filesystem"
+)
+
+func TestList(t *testing.T) {
+	fs := filesystem.NewMemFileSystem()
+	fs.WriteFile("/base/index.md", []byte("# Home"))
+	fs.WriteFile("/base/docs/api.md", []byte("---\ntitle: \"API Reference\"\n---\n\n# Ignored"))
+	fs.WriteFile("/base/docs/guides/index.md", []byte("no title here"))
+	fs.WriteFile("/base/docs/.hidden.md", []byte("# Hidden"))
+	fs.WriteFile("/base/.drafts/next.md", []byte("# Next"))
+	fs.WriteFile("/base/docs/logo.png", []byte("png"))
+
+	documents, err := List(fs, "/base")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []Document{
+		{Path: "/docs/api", File: "docs/api.md", Title: "API Reference"},
+		{Path: "/docs/guides/", File: "docs/guides/index.md"},
+		{Path: "/", File: "index.md", Title: "Home"},
+	}
+	if !reflect.DeepEqual(documents, expected) {
+		t.Errorf("expected %+v, got %+v", expected, documents)
+	}
+
+	if _, err := List(fs, "/missing"); err == nil {
+		t.Error("expected an error for a missing directory")
+	}
+}
+
+func TestResolvePath(t *testing.T) {
+	tests := []struct {
+		requestPath string
+		expected    string
+		ok          bool
+	}{
+		{requestPath: "docs/api.md", expected: "/base/docs/api.md", ok: true},
+		{requestPath: "", expected: "/base", ok: true},
+		{requestPath: "docs/../index.md", expected: "/base/index.md", ok: true},
+		{requestPath: "../secret.md"},
+		{requestPath: "../base2/index.md"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.requestPath, func(t *testing.T) {
+			filePath, ok := ResolvePath("/base", tt.requestPath)
+			if filePath != tt.expected || ok != tt.ok {
+				t.Errorf("expected (%q, %v), got (%q, %v)", tt.expected, tt.ok, filePath, ok)
+			}
+		})
+	}
+}
+
+func TestIsHidden(t *testing.T) {
+	tests := []struct {
+		requestPath string
+		expected    bool
+	}{
+		{requestPath: "docs/api.md"},
+		{requestPath: ".git/config", expected: true},
+		{requestPath: "docs/.drafts/next.md", expected: true},
+		{requestPath: "docs/.hidden.md", expected: true},
+		{requestPath: "docs/../index.md"},
+		{requestPath: "./index.md"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.requestPath, func(t *testing.T) {
+			if got := IsHidden(tt.requestPath); got != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestURL(t *testing.T) {
+	tests := []struct {
+		rel      string
+		expected string
+	}{
+		{rel: "index.md", expected: "/"},
+		{rel: "guides/index.md", expected: "/guides/"},
+		{rel: "guides/setup.md", expected: "/guides/setup"},
+		{rel: "reindex.md", expected: "/reindex"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.rel, func(t *testing.T) {
+			if got := URL(tt.rel); got != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestTitle(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected string
+	}{
+		{"front matter", "---\ntitle: Getting Started\n---\n# Other", "Getting Started"},
+		{"quoted front matter", "---\ntitle: 'Quoted'\n---\n", "Quoted"},
+		{"first heading", "Intro text\n\n# Heading\n\n# Second", "Heading"},
+		{"heading in code block ignored", "```\n# comment\n```\n# Real", "Real"},
+		{"no title", "## Subheading only", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Title([]byte(tt.content)); got != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
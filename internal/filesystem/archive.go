@@ -0,0 +1,234 @@
+package filesystem
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Archive writes files into a single zip or tar.gz archive instead of a
+// directory. It implements the scraper's FileSystem interface: each file is
+// buffered until it is closed and then added as a complete entry, so
+// concurrent workers can write at the same time. Close finishes the archive.
+type Archive struct {
+	mu      sync.Mutex
+	entries entryWriter
+	names   map[string]bool
+	now     func() time.Time
+}
+
+// entryWriter adds complete entries to an archive format
+type entryWriter interface {
+	writeFile(name string, data []byte, modTime time.Time) error
+	writeSymlink(name, target string, modTime time.Time) error
+	close() error
+}
+
+// NewZipArchive creates an archive that writes a zip file to w
+func NewZipArchive(w io.WriteCloser) *Archive {
+	return newArchive(&zipEntries{out: w, zw: zip.NewWriter(w)})
+}
+
+// NewTarGzArchive creates an archive that writes a gzipped tarball to w
+func NewTarGzArchive(w io.WriteCloser) *Archive {
+	gz := gzip.NewWriter(w)
+	return newArchive(&tarEntries{out: w, gz: gz, tw: tar.NewWriter(gz)})
+}
+
+func newArchive(entries entryWriter) *Archive {
+	return &Archive{
+		entries: entries,
+		names:   make(map[string]bool),
+		now:     time.Now,
+	}
+}
+
+// Create returns a file that is added to the archive when it is closed
+func (a *Archive) Create(name string) (io.WriteCloser, error) {
+	entry, err := entryName(name)
+	if err != nil {
+		return nil, err
+	}
+	return &archiveFile{archive: a, name: entry}, nil
+}
+
+// MkdirAll does nothing; directories are implied by the entry names
+func (a *Archive) MkdirAll(path string, perm int) error {
+	return nil
+}
+
+// Symlink adds newname as a symbolic link to oldname
+func (a *Archive) Symlink(oldname, newname string) error {
+	entry, err := entryName(newname)
+	if err != nil {
+		return err
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if err := a.claim(entry); err != nil {
+		return err
+	}
+	return a.entries.writeSymlink(entry, filepath.ToSlash(oldname), a.now())
+}
+
+// Close writes the archive's index and closes the underlying writer
+func (a *Archive) Close() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return a.entries.close()
+}
+
+func (a *Archive) add(name string, data []byte) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if err := a.claim(name); err != nil {
+		return err
+	}
+	return a.entries.writeFile(name, data, a.now())
+}
+
+// claim reserves an entry name; entries cannot be replaced once written.
+// Callers hold a.mu.
+func (a *Archive) claim(name string) error {
+	if a.names[name] {
+		return fmt.Errorf("%s is already in the archive", name)
+	}
+	a.names[name] = true
+	return nil
+}
+
+// entryName turns a file path into a slash-separated archive entry name,
+// rejecting paths that would escape the archive
+func entryName(name string) (string, error) {
+	entry := path.Clean(strings.TrimLeft(filepath.ToSlash(name), "/"))
+	if entry == "." || entry == ".." || strings.HasPrefix(entry, "../") {
+		return "", fmt.Errorf("invalid archive entry name %q", name)
+	}
+	return entry, nil
+}
+
+// archiveFile buffers a file's content until it is closed
+type archiveFile struct {
+	archive *Archive
+	name    string
+	buf     bytes.Buffer
+	closed  bool
+}
+
+func (f *archiveFile) Write(p []byte) (int, error) {
+	if f.closed {
+		return 0, os.ErrClosed
+	}
+	return f.buf.Write(p)
+}
+
+func (f *archiveFile) Close() error {
+	if f.closed {
+		return nil
+	}
+	f.closed = true
+	return f.archive.add(f.name, f.buf.Bytes())
+}
+
+type zipEntries struct {
+	out io.Closer
+	zw  *zip.Writer
+}
+
+func (z *zipEntries) writeFile(name string, data []byte, modTime time.Time) error {
+	header := &zip.FileHeader{Name: name, Method: zip.Deflate, Modified: modTime}
+	header.SetMode(0644)
+	w, err := z.zw.CreateHeader(header)
+	if err != nil {
+		return fmt.Errorf("failed to add %s to zip: %w", name, err)
+	}
+	if _, err := w.Write(data); err != nil {
+		return fmt.Errorf("failed to add %s to zip: %w", name, err)
+	}
+	return nil
+}
+
+func (z *zipEntries) writeSymlink(name, target string, modTime time.Time) error {
+	header := &zip.FileHeader{Name: name, Method: zip.Store, Modified: modTime}
+	header.SetMode(os.ModeSymlink | 0777)
+	w, err := z.zw.CreateHeader(header)
+	if err != nil {
+		return fmt.Errorf("failed to add %s to zip: %w", name, err)
+	}
+	if _, err := io.WriteString(w, target); err != nil {
+		return fmt.Errorf("failed to add %s to zip: %w", name, err)
+	}
+	return nil
+}
+
+func (z *zipEntries) close() error {
+	if err := z.zw.Close(); err != nil {
+		z.out.Close()
+		return fmt.Errorf("failed to finish zip: %w", err)
+	}
+	return z.out.Close()
+}
+
+type tarEntries struct {
+	out io.Closer
+	gz  *gzip.Writer
+	tw  *tar.Writer
+}
+
+func (t *tarEntries) writeFile(name string, data []byte, modTime time.Time) error {
+	header := &tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     name,
+		Mode:     0644,
+		Size:     int64(len(data)),
+		ModTime:  modTime,
+	}
+	if err := t.tw.WriteHeader(header); err != nil {
+		return fmt.Errorf("failed to add %s to tarball: %w", name, err)
+	}
+	if _, err := t.tw.Write(data); err != nil {
+		return fmt.Errorf("failed to add %s to tarball: %w", name, err)
+	}
+	return nil
+}
+
+func (t *tarEntries) writeSymlink(name, target string, modTime time.Time) error {
+	header := &tar.Header{
+		Typeflag: tar.TypeSymlink,
+		Name:     name,
+		Linkname: target,
+		Mode:     0777,
+		ModTime:  modTime,
+	}
+	if err := t.tw.WriteHeader(header); err != nil {
+		return fmt.Errorf("failed to add %s to tarball: %w", name, err)
+	}
+	return nil
+}
+
+func (t *tarEntries) close() error {
+	err := t.tw.Close()
+	if gzErr := t.gz.Close(); err == nil {
+		err = gzErr
+	}
+	if closeErr := t.out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to finish tarball: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,227 @@
+package filesystem
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func writeEntries(t *testing.T, archive interface {
+	Create(name string) (io.WriteCloser, error)
+	Symlink(oldname, newname string) error
+}) {
+	t.Helper()
+
+	files := map[string]string{
+		"./index.md":     "# Home\n\nWelcome.\n",
+		"guide/setup.md": "# Setup\n\nInstall it.\n",
+	}
+	for name, content := range files {
+		w, err := archive.Create(name)
+		if err != nil {
+			t.Fatalf("unexpected error creating %s: %v", name, err)
+		}
+		if _, err := io.WriteString(w, content); err != nil {
+			t.Fatalf("unexpected error writing %s: %v", name, err)
+		}
+		if err := w.Close(); err != nil {
+			t.Fatalf("unexpected error closing %s: %v", name, err)
+		}
+	}
+	if err := archive.Symlink("setup.md", "guide/install.md"); err != nil {
+		t.Fatalf("unexpected error adding symlink: %v", err)
+	}
+}
+
+func TestEntryName(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected string
+		wantErr  bool
+	}{
+		{name: "docs/a.md", expected: "docs/a.md"},
+		{name: "./docs/../b.md", expected: "b.md"},
+		{name: "/abs/c.md", expected: "abs/c.md"},
+		{name: "../escape.md", wantErr: true},
+		{name: ".", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := entryName(tt.name)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("expected an error, got %q", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestArchive_Zip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "docs.zip")
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	archive := NewZipArchive(file)
+	writeEntries(t, archive)
+
+	w, _ := archive.Create("index.md")
+	if err := w.Close(); err == nil {
+		t.Error("expected an error writing an entry twice")
+	}
+	if err := archive.Close(); err != nil {
+		t.Fatalf("unexpected error closing archive: %v", err)
+	}
+
+	zfs, err := OpenZip(path)
+	if err != nil {
+		t.Fatalf("unexpected error opening zip: %v", err)
+	}
+	defer zfs.Close()
+
+	data, err := zfs.ReadFile(filepath.Join(path, "guide", "install.md"))
+	if err != nil {
+		t.Fatalf("unexpected error following symlink: %v", err)
+	}
+	if string(data) != "# Setup\n\nInstall it.\n" {
+		t.Errorf("unexpected content %q", data)
+	}
+
+	entries, err := zfs.ReadDir(filepath.Join(path, "guide"))
+	if err != nil {
+		t.Fatalf("unexpected error reading directory: %v", err)
+	}
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	if expected := []string{"install.md", "setup.md"}; !reflect.DeepEqual(names, expected) {
+		t.Errorf("expected %v, got %v", expected, names)
+	}
+
+	info, err := zfs.Stat(path)
+	if err != nil || !info.IsDir() {
+		t.Errorf("expected the archive root to be a directory, got %v", err)
+	}
+	if info, _ := zfs.Stat(filepath.Join(path, "missing.md")); info.IsExist() {
+		t.Error("expected a missing entry not to exist")
+	}
+	if _, err := zfs.ReadFile(filepath.Join(filepath.Dir(path), "other.md")); err == nil {
+		t.Error("expected an error reading outside the archive")
+	}
+}
+
+func TestArchive_TarGz(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "docs.tar.gz")
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	archive := NewTarGzArchive(file)
+	writeEntries(t, archive)
+	if err := archive.Close(); err != nil {
+		t.Fatalf("unexpected error closing archive: %v", err)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatalf("invalid gzip: %v", err)
+	}
+
+	entries := map[string]string{}
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("invalid tarball: %v", err)
+		}
+		if header.Typeflag == tar.TypeSymlink {
+			entries[header.Name] = "-> " + header.Linkname
+			continue
+		}
+		data, _ := io.ReadAll(tr)
+		entries[header.Name] = string(data)
+	}
+
+	expected := map[string]string{
+		"index.md":         "# Home\n\nWelcome.\n",
+		"guide/setup.md":   "# Setup\n\nInstall it.\n",
+		"guide/install.md": "-> setup.md",
+	}
+	if !reflect.DeepEqual(entries, expected) {
+		t.Errorf("expected %v, got %v", expected, entries)
+	}
+}
+
+func TestSQLiteDatabase(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "docs.db")
+	db, err := OpenSQLite(path)
+	if err != nil {
+		t.Fatalf("unexpected error opening database: %v", err)
+	}
+	db.RecordSource("guide/setup.md", "https://example.com/guide/setup")
+	writeEntries(t, db)
+	if err := db.Close(); err != nil {
+		t.Fatalf("unexpected error closing database: %v", err)
+	}
+
+	// Reopening keeps existing rows
+	db, err = OpenSQLite(path)
+	if err != nil {
+		t.Fatalf("unexpected error reopening database: %v", err)
+	}
+	defer db.Close()
+
+	rows, err := db.db.Query(`SELECT path, COALESCE(url, ''), COALESCE(title, ''), bytes, COALESCE(duplicate_of, '') FROM pages`)
+	if err != nil {
+		t.Fatalf("unexpected error querying pages: %v", err)
+	}
+	defer rows.Close()
+
+	var got []string
+	for rows.Next() {
+		var path, url, title, duplicateOf string
+		var size int
+		if err := rows.Scan(&path, &url, &title, &size, &duplicateOf); err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, path+"|"+url+"|"+title+"|"+duplicateOf)
+		if duplicateOf == "" && size == 0 {
+			t.Errorf("expected %s to record its size", path)
+		}
+	}
+	sort.Strings(got)
+
+	expected := []string{
+		"guide/install.md|||guide/setup.md",
+		"guide/setup.md|https://example.com/guide/setup|Setup|",
+		"index.md||Home|",
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+}
@@ -0,0 +1,178 @@
+package filesystem
+
+import (
+	"archive/zip"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// bundleMagic ends an executable that has docs appended by WriteBundle. The
+// 8 bytes before it hold the size of the zip archive in front of them.
+const bundleMagic = "mdify-bundle-v1\n"
+
+const bundleTrailerSize = 8 + len(bundleMagic)
+
+// ErrNoBundle is returned by OpenBundle for files without appended docs
+var ErrNoBundle = errors.New("no bundled docs")
+
+// WriteBundle writes the executable read from exe, followed by a zip of the
+// files below dir and a trailer that lets OpenBundle find the zip again. A
+// bundle already appended to exe is replaced, and hidden files are left out.
+// It returns the number of files bundled.
+func WriteBundle(w io.Writer, exe io.ReaderAt, exeSize int64, fs ReadOnlyFileSystem, dir string) (int, error) {
+	offset, _, err := bundleOffset(exe, exeSize)
+	if errors.Is(err, ErrNoBundle) {
+		offset = exeSize
+	} else if err != nil {
+		return 0, err
+	}
+	if _, err := io.Copy(w, io.NewSectionReader(exe, 0, offset)); err != nil {
+		return 0, fmt.Errorf("failed to copy executable: %w", err)
+	}
+
+	counter := &countingWriter{w: w}
+	zw := zip.NewWriter(counter)
+	files, err := addTree(zw, fs, dir, "")
+	if err != nil {
+		return 0, err
+	}
+	if err := zw.Close(); err != nil {
+		return 0, fmt.Errorf("failed to finish bundle: %w", err)
+	}
+
+	trailer := binary.BigEndian.AppendUint64(nil, uint64(counter.n))
+	trailer = append(trailer, bundleMagic...)
+	if _, err := w.Write(trailer); err != nil {
+		return 0, fmt.Errorf("failed to finish bundle: %w", err)
+	}
+	return files, nil
+}
+
+// OpenBundle opens the docs appended to the executable at path, read as if
+// they were a directory at the executable's own path
+func OpenBundle(exePath string) (*ZipFileSystem, error) {
+	root, err := filepath.Abs(exePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get absolute path for %s: %w", exePath, err)
+	}
+	file, err := os.Open(root)
+	if err != nil {
+		return nil, err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	if info.IsDir() {
+		file.Close()
+		return nil, ErrNoBundle
+	}
+
+	offset, size, err := bundleOffset(file, info.Size())
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	reader, err := zip.NewReader(io.NewSectionReader(file, offset, size), size)
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to read docs bundled in %s: %w", exePath, err)
+	}
+	return &ZipFileSystem{
+		IOFileSystem: &IOFileSystem{fsys: reader, root: root},
+		closer:       file,
+	}, nil
+}
+
+// bundleOffset finds where the zip appended to an executable starts and how
+// long it is
+func bundleOffset(r io.ReaderAt, size int64) (int64, int64, error) {
+	if size < int64(bundleTrailerSize) {
+		return 0, 0, ErrNoBundle
+	}
+	trailer := make([]byte, bundleTrailerSize)
+	if _, err := r.ReadAt(trailer, size-int64(bundleTrailerSize)); err != nil {
+		return 0, 0, fmt.Errorf("failed to read bundle trailer: %w", err)
+	}
+	if string(trailer[8:]) != bundleMagic {
+		return 0, 0, ErrNoBundle
+	}
+
+	zipSize := int64(binary.BigEndian.Uint64(trailer[:8]))
+	offset := size - int64(bundleTrailerSize) - zipSize
+	if zipSize < 0 || offset < 0 {
+		return 0, 0, fmt.Errorf("invalid bundle trailer")
+	}
+	return offset, zipSize, nil
+}
+
+// addTree adds the files below dir to zw under prefix, following symlinks
+// to files
+func addTree(zw *zip.Writer, fs ReadOnlyFileSystem, dir, prefix string) (int, error) {
+	entries, err := fs.ReadDir(dir)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read directory %s: %w", dir, err)
+	}
+
+	files := 0
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		filePath := filepath.Join(dir, entry.Name())
+		name := path.Join(prefix, entry.Name())
+
+		if entry.IsDir() {
+			n, err := addTree(zw, fs, filePath, name)
+			if err != nil {
+				return 0, err
+			}
+			files += n
+			continue
+		}
+
+		info, err := fs.Stat(filePath)
+		if err != nil {
+			return 0, fmt.Errorf("failed to stat %s: %w", filePath, err)
+		}
+		if info.IsDir() {
+			// symlinked directories could loop; bundle what they point at instead
+			continue
+		}
+		data, err := fs.ReadFile(filePath)
+		if err != nil {
+			return 0, fmt.Errorf("failed to read %s: %w", filePath, err)
+		}
+
+		header := &zip.FileHeader{Name: name, Method: zip.Deflate, Modified: info.ModTime()}
+		header.SetMode(0644)
+		w, err := zw.CreateHeader(header)
+		if err != nil {
+			return 0, fmt.Errorf("failed to bundle %s: %w", filePath, err)
+		}
+		if _, err := w.Write(data); err != nil {
+			return 0, fmt.Errorf("failed to bundle %s: %w", filePath, err)
+		}
+		files++
+	}
+	return files, nil
+}
+
+// countingWriter counts the bytes written through it
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
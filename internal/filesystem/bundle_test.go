@@ -0,0 +1,139 @@
+package filesystem
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// writeTestBundle bundles the tree at /docs of fs onto exe and returns the
+// path of the result
+func writeTestBundle(t *testing.T, exe []byte, fs ReadOnlyFileSystem) string {
+	t.Helper()
+
+	var out bytes.Buffer
+	if _, err := WriteBundle(&out, bytes.NewReader(exe), int64(len(exe)), fs, "/docs"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "docs-server")
+	if err := os.WriteFile(path, out.Bytes(), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestBundle(t *testing.T) {
+	modTime := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	docs := NewMemFileSystem()
+	docs.WriteFile("/docs/index.md", []byte("# Home"))
+	docs.WriteFile("/docs/guide/setup.md", []byte("# Setup"))
+	docs.WriteFile("/docs/.htpasswd", []byte("secret"))
+	docs.Symlink("setup.md", "/docs/guide/install.md")
+	docs.Symlink("guide", "/docs/latest")
+	docs.Chtimes("/docs/index.md", modTime)
+
+	exe := []byte("\x7fELF pretend executable")
+	var out bytes.Buffer
+	files, err := WriteBundle(&out, bytes.NewReader(exe), int64(len(exe)), docs, "/docs")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if files != 3 {
+		t.Errorf("expected 3 files, got %d", files)
+	}
+	if !bytes.HasPrefix(out.Bytes(), exe) {
+		t.Error("expected the bundle to start with the executable")
+	}
+
+	path := filepath.Join(t.TempDir(), "docs-server")
+	os.WriteFile(path, out.Bytes(), 0755)
+	bundle, err := OpenBundle(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer bundle.Close()
+
+	tests := []struct {
+		path     string
+		expected string
+		wantErr  bool
+	}{
+		{path: "index.md", expected: "# Home"},
+		{path: "guide/setup.md", expected: "# Setup"},
+		{path: "guide/install.md", expected: "# Setup"},
+		{path: ".htpasswd", wantErr: true},
+		{path: "latest/setup.md", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			data, err := bundle.ReadFile(filepath.Join(path, tt.path))
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("expected %s not to be bundled", tt.path)
+				}
+				return
+			}
+			if err != nil || string(data) != tt.expected {
+				t.Errorf("expected %q, got %q, %v", tt.expected, data, err)
+			}
+		})
+	}
+
+	info, err := bundle.Stat(filepath.Join(path, "index.md"))
+	if err != nil || !info.ModTime().Equal(modTime) {
+		t.Errorf("expected the modification time to be kept, got %v, %v", info.ModTime(), err)
+	}
+	entries, err := bundle.ReadDir(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := []string{"guide", "index.md"}; !reflect.DeepEqual(entryNames(entries), expected) {
+		t.Errorf("expected %v, got %v", expected, entryNames(entries))
+	}
+}
+
+func TestBundle_Rebundle(t *testing.T) {
+	first := NewMemFileSystem()
+	first.WriteFile("/docs/old.md", []byte("# Old"))
+	second := NewMemFileSystem()
+	second.WriteFile("/docs/new.md", []byte("# New"))
+
+	exe := []byte("pretend executable")
+	bundled, err := os.ReadFile(writeTestBundle(t, exe, first))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := writeTestBundle(t, bundled, second)
+	data, _ := os.ReadFile(path)
+	if !bytes.HasPrefix(data, exe) || bytes.Contains(data, []byte("old.md")) {
+		t.Error("expected the previous bundle to be replaced")
+	}
+
+	bundle, err := OpenBundle(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer bundle.Close()
+	if _, err := bundle.ReadFile(filepath.Join(path, "new.md")); err != nil {
+		t.Errorf("expected the new docs, got %v", err)
+	}
+}
+
+func TestOpenBundle_NoBundle(t *testing.T) {
+	dir := t.TempDir()
+	plain := filepath.Join(dir, "mdify")
+	os.WriteFile(plain, []byte("an executable without docs appended to it"), 0755)
+
+	for _, path := range []string{plain, dir} {
+		if _, err := OpenBundle(path); !errors.Is(err, ErrNoBundle) {
+			t.Errorf("expected ErrNoBundle for %s, got %v", path, err)
+		}
+	}
+}
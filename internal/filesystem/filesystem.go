@@ -3,19 +3,25 @@ package filesystem
 import (
 	"io"
 	"os"
+	"time"
 )
 
 // FileSystem interface for abstracting file system operations
 type FileSystem interface {
 	Create(name string) (io.WriteCloser, error)
 	MkdirAll(path string, perm int) error
+	Symlink(oldname, newname string) error
 	ReadFile(filename string) ([]byte, error)
+	ReadDir(name string) ([]os.DirEntry, error)
 	Stat(name string) (FileInfo, error)
 }
 
 // FileInfo interface for file information
 type FileInfo interface {
 	IsExist() bool
+	IsDir() bool
+	Size() int64
+	ModTime() time.Time
 }
 
 // OSFileSystem implements FileSystem using the actual OS
@@ -29,10 +35,23 @@ func (fs OSFileSystem) MkdirAll(path string, perm int) error {
 	return os.MkdirAll(path, os.FileMode(perm))
 }
 
+// Symlink creates newname as a symbolic link to oldname, replacing any file
+// already there the way Create truncates one
+func (fs OSFileSystem) Symlink(oldname, newname string) error {
+	if err := os.Remove(newname); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return os.Symlink(oldname, newname)
+}
+
 func (fs OSFileSystem) ReadFile(filename string) ([]byte, error) {
 	return os.ReadFile(filename)
 }
 
+func (fs OSFileSystem) ReadDir(name string) ([]os.DirEntry, error) {
+	return os.ReadDir(name)
+}
+
 func (fs OSFileSystem) Stat(name string) (FileInfo, error) {
 	info, err := os.Stat(name)
 	if err != nil {
@@ -52,4 +71,21 @@ type OSFileInfo struct {
 
 func (fi *OSFileInfo) IsExist() bool {
 	return fi.exists
-}
\ No newline at end of file
+}
+
+func (fi *OSFileInfo) IsDir() bool {
+	return fi.exists && fi.info.IsDir()
+}
+func (fi *OSFileInfo) Size() int64 {
+	if !fi.exists {
+		return 0
+	}
+	return fi.info.Size()
+}
+
+func (fi *OSFileInfo) ModTime() time.Time {
+	if !fi.exists {
+		return time.Time{}
+	}
+	return fi.info.ModTime()
+}
@@ -0,0 +1,96 @@
+package filesystem
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// maxSymlinks bounds how many symlinks are followed resolving one name
+const maxSymlinks = 8
+
+// IOFileSystem reads an fs.FS, such as an embed.FS or a zip archive, as if
+// it were a directory at root, so the server can serve embedded files just
+// like files on disk. Symlink entries, whose content is their target, are
+// followed.
+type IOFileSystem struct {
+	fsys fs.FS
+	root string // absolute path the fs.FS appears at
+}
+
+// NewIOFileSystem reads fsys as if it were the directory at root
+func NewIOFileSystem(fsys fs.FS, root string) (*IOFileSystem, error) {
+	abs, err := filepath.Abs(root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get absolute path for %s: %w", root, err)
+	}
+	return &IOFileSystem{fsys: fsys, root: abs}, nil
+}
+
+func (f *IOFileSystem) ReadFile(filename string) ([]byte, error) {
+	name, err := f.resolve(filename)
+	if err != nil {
+		return nil, err
+	}
+	return fs.ReadFile(f.fsys, name)
+}
+
+func (f *IOFileSystem) ReadDir(dirname string) ([]os.DirEntry, error) {
+	name, err := f.resolve(dirname)
+	if err != nil {
+		return nil, err
+	}
+	return fs.ReadDir(f.fsys, name)
+}
+
+func (f *IOFileSystem) Stat(filename string) (FileInfo, error) {
+	name, err := f.resolve(filename)
+	if err != nil {
+		return &OSFileInfo{exists: false}, err
+	}
+	info, err := fs.Stat(f.fsys, name)
+	if err != nil {
+		return &OSFileInfo{exists: false}, err
+	}
+	return &OSFileInfo{exists: true, info: info}, nil
+}
+
+// resolve maps a path below root to a name in the fs.FS, following symlinks
+func (f *IOFileSystem) resolve(filename string) (string, error) {
+	name, err := f.entry(filename)
+	if err != nil {
+		return "", err
+	}
+
+	for i := 0; i < maxSymlinks; i++ {
+		info, err := fs.Stat(f.fsys, name)
+		if err != nil || info.Mode()&fs.ModeSymlink == 0 {
+			return name, nil
+		}
+		target, err := fs.ReadFile(f.fsys, name)
+		if err != nil {
+			return "", err
+		}
+		name = path.Join(path.Dir(name), string(target))
+		if !fs.ValidPath(name) {
+			return "", &fs.PathError{Op: "open", Path: filename, Err: fs.ErrNotExist}
+		}
+	}
+	return "", &fs.PathError{Op: "open", Path: filename, Err: errTooManySymlinks}
+}
+
+// entry maps a path below root to a name in the fs.FS
+func (f *IOFileSystem) entry(filename string) (string, error) {
+	abs, err := filepath.Abs(filename)
+	if err != nil {
+		return "", &fs.PathError{Op: "open", Path: filename, Err: err}
+	}
+	rel, err := filepath.Rel(f.root, abs)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", &fs.PathError{Op: "open", Path: filename, Err: fs.ErrNotExist}
+	}
+	return filepath.ToSlash(rel), nil
+}
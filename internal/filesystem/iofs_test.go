@@ -0,0 +1,84 @@
+package filesystem
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"testing/fstest"
+)
+
+func TestIOFileSystem(t *testing.T) {
+	fsys := fstest.MapFS{
+		"index.md":       {Data: []byte("# Home")},
+		"guide/setup.md": {Data: []byte("# Setup")},
+		"guide/alias.md": {Data: []byte("setup.md"), Mode: fs.ModeSymlink},
+		"escape.md":      {Data: []byte("../outside.md"), Mode: fs.ModeSymlink},
+	}
+	root := filepath.Join(t.TempDir(), "docs")
+	iofs, err := NewIOFileSystem(fsys, root)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		path     string
+		expected string
+		wantErr  bool
+	}{
+		{name: "file", path: filepath.Join(root, "index.md"), expected: "# Home"},
+		{name: "nested file", path: filepath.Join(root, "guide", "setup.md"), expected: "# Setup"},
+		{name: "symlink", path: filepath.Join(root, "guide", "alias.md"), expected: "# Setup"},
+		{name: "symlink out of the tree", path: filepath.Join(root, "escape.md"), wantErr: true},
+		{name: "missing", path: filepath.Join(root, "missing.md"), wantErr: true},
+		{name: "outside root", path: filepath.Join(filepath.Dir(root), "index.md"), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := iofs.ReadFile(tt.path)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("expected an error, got %q", data)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if string(data) != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, data)
+			}
+		})
+	}
+
+	info, err := iofs.Stat(root)
+	if err != nil || !info.IsDir() {
+		t.Errorf("expected root to be a directory, got %v", err)
+	}
+	if info, err := iofs.Stat(filepath.Join(root, "missing.md")); info.IsExist() || !os.IsNotExist(err) {
+		t.Errorf("expected a missing file not to exist, got %v", err)
+	}
+
+	entries, err := iofs.ReadDir(filepath.Join(root, "guide"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := []string{"alias.md", "setup.md"}; !reflect.DeepEqual(entryNames(entries), expected) {
+		t.Errorf("expected %v, got %v", expected, entryNames(entries))
+	}
+}
+
+func TestIOFileSystem_RelativePaths(t *testing.T) {
+	iofs, err := NewIOFileSystem(fstest.MapFS{"index.md": {Data: []byte("# Home")}}, "docs")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	data, err := iofs.ReadFile("docs/index.md")
+	if err != nil || string(data) != "# Home" {
+		t.Errorf("expected relative paths to resolve against the working directory, got %q, %v", data, err)
+	}
+}
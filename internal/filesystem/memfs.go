@@ -0,0 +1,330 @@
+package filesystem
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+var (
+	errIsDir           = errors.New("is a directory")
+	errNotDir          = errors.New("not a directory")
+	errNotEmpty        = errors.New("directory not empty")
+	errTooManySymlinks = errors.New("too many levels of symbolic links")
+)
+
+// MemFileSystem is a FileSystem kept entirely in memory. It behaves like the
+// OS: files need their parent directory, directories list their entries in
+// name order, files carry modification times and symlinks are followed. It
+// is safe for concurrent use.
+type MemFileSystem struct {
+	mu    sync.RWMutex
+	nodes map[string]*memNode
+	now   func() time.Time
+}
+
+// memNode is a file, directory or symlink
+type memNode struct {
+	mode    fs.FileMode
+	data    []byte
+	target  string // symlink target
+	modTime time.Time
+}
+
+// NewMemFileSystem creates an empty in-memory file system
+func NewMemFileSystem() *MemFileSystem {
+	return &MemFileSystem{
+		nodes: make(map[string]*memNode),
+		now:   time.Now,
+	}
+}
+
+// Create creates or truncates the named file
+func (m *MemFileSystem) Create(name string) (io.WriteCloser, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	name, err := m.parent("open", name)
+	if err != nil {
+		return nil, err
+	}
+	if node, ok := m.nodes[name]; ok && node.mode.IsDir() {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: errIsDir}
+	}
+
+	node := &memNode{mode: 0644, modTime: m.now()}
+	m.nodes[name] = node
+	return &memFile{fs: m, node: node}, nil
+}
+
+// MkdirAll creates a directory and any missing parents
+func (m *MemFileSystem) MkdirAll(path string, perm int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.mkdirAll(filepath.Clean(path), fs.FileMode(perm))
+}
+
+// Symlink creates newname as a symbolic link to oldname, replacing any file
+// already there
+func (m *MemFileSystem) Symlink(oldname, newname string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	newname, err := m.parent("symlink", newname)
+	if err != nil {
+		return err
+	}
+	if node, ok := m.nodes[newname]; ok && node.mode.IsDir() {
+		return &fs.PathError{Op: "symlink", Path: newname, Err: errIsDir}
+	}
+	m.nodes[newname] = &memNode{mode: fs.ModeSymlink | 0777, target: oldname, modTime: m.now()}
+	return nil
+}
+
+func (m *MemFileSystem) ReadFile(filename string) ([]byte, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	_, node, err := m.resolve("open", filename)
+	if err != nil {
+		return nil, err
+	}
+	if node.mode.IsDir() {
+		return nil, &fs.PathError{Op: "read", Path: filename, Err: errIsDir}
+	}
+	return append([]byte(nil), node.data...), nil
+}
+
+func (m *MemFileSystem) ReadDir(name string) ([]os.DirEntry, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	dir, node, err := m.resolve("open", name)
+	if err != nil {
+		return nil, err
+	}
+	if !node.mode.IsDir() {
+		return nil, &fs.PathError{Op: "readdirent", Path: name, Err: errNotDir}
+	}
+
+	var entries []os.DirEntry
+	for path, child := range m.nodes {
+		if path != dir && filepath.Dir(path) == dir {
+			entries = append(entries, fs.FileInfoToDirEntry(child.info(filepath.Base(path))))
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Name() < entries[j].Name()
+	})
+	return entries, nil
+}
+
+func (m *MemFileSystem) Stat(name string) (FileInfo, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	path, node, err := m.resolve("stat", name)
+	if err != nil {
+		return &OSFileInfo{exists: false}, err
+	}
+	return &OSFileInfo{exists: true, info: node.info(filepath.Base(path))}, nil
+}
+
+// WriteFile writes data to the named file, creating its parent directories
+func (m *MemFileSystem) WriteFile(name string, data []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.mkdirAll(filepath.Dir(filepath.Clean(name)), 0755); err != nil {
+		return err
+	}
+	name, err := m.parent("open", name)
+	if err != nil {
+		return err
+	}
+	if node, ok := m.nodes[name]; ok && node.mode.IsDir() {
+		return &fs.PathError{Op: "open", Path: name, Err: errIsDir}
+	}
+	m.nodes[name] = &memNode{mode: 0644, data: append([]byte(nil), data...), modTime: m.now()}
+	return nil
+}
+
+// Chtimes sets the modification time of the named file or directory
+func (m *MemFileSystem) Chtimes(name string, modTime time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	_, node, err := m.resolve("chtimes", name)
+	if err != nil {
+		return err
+	}
+	node.modTime = modTime
+	return nil
+}
+
+// Remove removes the named file, symlink or empty directory
+func (m *MemFileSystem) Remove(name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	name, err := m.parent("remove", name)
+	if err != nil {
+		return err
+	}
+	node, ok := m.nodes[name]
+	if !ok {
+		return &fs.PathError{Op: "remove", Path: name, Err: fs.ErrNotExist}
+	}
+	if node.mode.IsDir() {
+		for path := range m.nodes {
+			if path != name && filepath.Dir(path) == name {
+				return &fs.PathError{Op: "remove", Path: name, Err: errNotEmpty}
+			}
+		}
+	}
+	delete(m.nodes, name)
+	return nil
+}
+
+// mkdirAll creates path and its parents; callers hold m.mu
+func (m *MemFileSystem) mkdirAll(path string, perm fs.FileMode) error {
+	if _, node, err := m.resolve("mkdir", path); err == nil {
+		if node.mode.IsDir() {
+			return nil
+		}
+		return &fs.PathError{Op: "mkdir", Path: path, Err: errNotDir}
+	}
+	if err := m.mkdirAll(filepath.Dir(path), perm); err != nil {
+		return err
+	}
+
+	name, err := m.parent("mkdir", path)
+	if err != nil {
+		return err
+	}
+	if _, ok := m.nodes[name]; ok {
+		// a symlink to nothing
+		return &fs.PathError{Op: "mkdir", Path: path, Err: fs.ErrExist}
+	}
+	m.nodes[name] = &memNode{mode: fs.ModeDir | perm.Perm(), modTime: m.now()}
+	return nil
+}
+
+// parent resolves the directory name is in, which must exist, and returns
+// the path name has inside it; callers hold m.mu
+func (m *MemFileSystem) parent(op, name string) (string, error) {
+	name = filepath.Clean(name)
+	dir, node, err := m.resolve(op, filepath.Dir(name))
+	if err != nil {
+		return "", &fs.PathError{Op: op, Path: name, Err: fs.ErrNotExist}
+	}
+	if !node.mode.IsDir() {
+		return "", &fs.PathError{Op: op, Path: name, Err: errNotDir}
+	}
+	return filepath.Join(dir, filepath.Base(name)), nil
+}
+
+// resolve looks up name, following symlinks in every component, and returns
+// the path it resolved to; callers hold m.mu
+func (m *MemFileSystem) resolve(op, name string) (string, *memNode, error) {
+	hops := 0
+	path, node, err := m.lookup(filepath.Clean(name), &hops)
+	if err != nil {
+		return "", nil, &fs.PathError{Op: op, Path: name, Err: err}
+	}
+	return path, node, nil
+}
+
+// lookup resolves a clean path, counting symlinks followed in hops
+func (m *MemFileSystem) lookup(path string, hops *int) (string, *memNode, error) {
+	if isRoot(path) {
+		return path, &memNode{mode: fs.ModeDir | 0755}, nil
+	}
+
+	dir, parent, err := m.lookup(filepath.Dir(path), hops)
+	if err != nil {
+		return "", nil, err
+	}
+	if !parent.mode.IsDir() {
+		return "", nil, errNotDir
+	}
+
+	path = filepath.Join(dir, filepath.Base(path))
+	node, ok := m.nodes[path]
+	if !ok {
+		return "", nil, fs.ErrNotExist
+	}
+	if node.mode&fs.ModeSymlink == 0 {
+		return path, node, nil
+	}
+
+	if *hops++; *hops > maxSymlinks {
+		return "", nil, errTooManySymlinks
+	}
+	target := node.target
+	if !filepath.IsAbs(target) {
+		target = filepath.Join(dir, target)
+	}
+	return m.lookup(filepath.Clean(target), hops)
+}
+
+// isRoot reports whether path is the root of an absolute or relative tree,
+// which always exists
+func isRoot(path string) bool {
+	return filepath.Dir(path) == path
+}
+
+func (n *memNode) info(name string) fs.FileInfo {
+	return memFileInfo{name: name, size: int64(len(n.data)), mode: n.mode, modTime: n.modTime}
+}
+
+// memFile writes through to its node
+type memFile struct {
+	fs     *MemFileSystem
+	node   *memNode
+	closed bool
+}
+
+func (f *memFile) Write(p []byte) (int, error) {
+	f.fs.mu.Lock()
+	defer f.fs.mu.Unlock()
+
+	if f.closed {
+		return 0, os.ErrClosed
+	}
+	f.node.data = append(f.node.data, p...)
+	f.node.modTime = f.fs.now()
+	return len(p), nil
+}
+
+func (f *memFile) Close() error {
+	f.fs.mu.Lock()
+	defer f.fs.mu.Unlock()
+
+	if f.closed {
+		return os.ErrClosed
+	}
+	f.closed = true
+	return nil
+}
+
+// memFileInfo implements fs.FileInfo
+type memFileInfo struct {
+	name    string
+	size    int64
+	mode    fs.FileMode
+	modTime time.Time
+}
+
+func (i memFileInfo) Name() string       { return i.name }
+func (i memFileInfo) Size() int64        { return i.size }
+func (i memFileInfo) Mode() fs.FileMode  { return i.mode }
+func (i memFileInfo) ModTime() time.Time { return i.modTime }
+func (i memFileInfo) IsDir() bool        { return i.mode.IsDir() }
+func (i memFileInfo) Sys() interface{}   { return nil }
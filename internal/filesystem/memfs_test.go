@@ -0,0 +1,253 @@
+package filesystem
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func entryNames(entries []os.DirEntry) []string {
+	names := []string{}
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	return names
+}
+
+func TestMemFileSystem_Create(t *testing.T) {
+	m := NewMemFileSystem()
+
+	if _, err := m.Create("/docs/a.md"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected a missing parent to fail with ErrNotExist, got %v", err)
+	}
+
+	if err := m.MkdirAll("/docs/guide", 0755); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	w, err := m.Create("/docs/guide/setup.md")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	io.WriteString(w, "# Setup\n")
+	io.WriteString(w, "Install it.\n")
+	if err := w.Close(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := w.Write([]byte("late")); err == nil {
+		t.Error("expected an error writing a closed file")
+	}
+
+	data, err := m.ReadFile("/docs/guide/../guide/setup.md")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(data) != "# Setup\nInstall it.\n" {
+		t.Errorf("unexpected content %q", data)
+	}
+
+	// Create truncates like os.Create
+	w, _ = m.Create("/docs/guide/setup.md")
+	w.Close()
+	if info, _ := m.Stat("/docs/guide/setup.md"); info.Size() != 0 {
+		t.Errorf("expected an empty file, got %d bytes", info.Size())
+	}
+
+	if _, err := m.Create("/docs/guide"); err == nil {
+		t.Error("expected an error creating a file over a directory")
+	}
+	if err := m.MkdirAll("/docs/guide/setup.md/more", 0755); err == nil {
+		t.Error("expected an error creating a directory below a file")
+	}
+}
+
+func TestMemFileSystem_ReadDir(t *testing.T) {
+	m := NewMemFileSystem()
+	m.WriteFile("/docs/b.md", []byte("b"))
+	m.WriteFile("/docs/a.md", []byte("a"))
+	m.WriteFile("/docs/guide/setup.md", []byte("setup"))
+	m.WriteFile("/other/c.md", []byte("c"))
+	m.Symlink("a.md", "/docs/alias.md")
+
+	entries, err := m.ReadDir("/docs")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := []string{"a.md", "alias.md", "b.md", "guide"}; !reflect.DeepEqual(entryNames(entries), expected) {
+		t.Errorf("expected %v, got %v", expected, entryNames(entries))
+	}
+	if !entries[3].IsDir() || entries[0].IsDir() {
+		t.Error("expected only guide to be a directory")
+	}
+	if entries[1].Type()&fs.ModeSymlink == 0 {
+		t.Error("expected alias.md to be listed as a symlink")
+	}
+
+	root, err := m.ReadDir("/")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := []string{"docs", "other"}; !reflect.DeepEqual(entryNames(root), expected) {
+		t.Errorf("expected %v, got %v", expected, entryNames(root))
+	}
+
+	if _, err := m.ReadDir("/docs/a.md"); err == nil {
+		t.Error("expected an error listing a file")
+	}
+	if _, err := m.ReadDir("/missing"); !os.IsNotExist(err) {
+		t.Errorf("expected a not-exist error, got %v", err)
+	}
+}
+
+func TestMemFileSystem_Stat(t *testing.T) {
+	modTime := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	m := NewMemFileSystem()
+	m.now = func() time.Time { return modTime }
+	m.WriteFile("docs/a.md", []byte("hello"))
+
+	tests := []struct {
+		name    string
+		path    string
+		exists  bool
+		isDir   bool
+		size    int64
+		modTime time.Time
+	}{
+		{name: "file", path: "docs/a.md", exists: true, size: 5, modTime: modTime},
+		{name: "directory", path: "docs", exists: true, isDir: true, modTime: modTime},
+		{name: "relative root", path: ".", exists: true, isDir: true},
+		{name: "missing", path: "docs/b.md"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info, err := m.Stat(tt.path)
+			if tt.exists != (err == nil) {
+				t.Fatalf("expected exists=%v, got error %v", tt.exists, err)
+			}
+			if !tt.exists && !os.IsNotExist(err) {
+				t.Errorf("expected a not-exist error, got %v", err)
+			}
+			if info.IsExist() != tt.exists || info.IsDir() != tt.isDir || info.Size() != tt.size || !info.ModTime().Equal(tt.modTime) {
+				t.Errorf("unexpected info exists=%v dir=%v size=%d modTime=%v", info.IsExist(), info.IsDir(), info.Size(), info.ModTime())
+			}
+		})
+	}
+
+	later := modTime.Add(time.Hour)
+	if err := m.Chtimes("docs/a.md", later); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if info, _ := m.Stat("docs/a.md"); !info.ModTime().Equal(later) {
+		t.Errorf("expected modification time %v, got %v", later, info.ModTime())
+	}
+}
+
+func TestMemFileSystem_Symlink(t *testing.T) {
+	m := NewMemFileSystem()
+	m.WriteFile("/docs/v3/api.md", []byte("# API"))
+	m.MkdirAll("/docs/latest", 0755)
+
+	if err := m.Symlink("../v3/api.md", "/docs/latest/api.md"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := m.Symlink("/docs/v3", "/docs/current"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	m.Symlink("loop-b", "/docs/loop-a")
+	m.Symlink("loop-a", "/docs/loop-b")
+
+	for _, path := range []string{"/docs/latest/api.md", "/docs/current/api.md"} {
+		data, err := m.ReadFile(path)
+		if err != nil || string(data) != "# API" {
+			t.Errorf("expected %s to read the original, got %q, %v", path, data, err)
+		}
+	}
+	if info, _ := m.Stat("/docs/current"); !info.IsDir() {
+		t.Error("expected a link to a directory to stat as a directory")
+	}
+	if _, err := m.ReadFile("/docs/loop-a"); err == nil {
+		t.Error("expected an error following a symlink loop")
+	}
+	if err := m.Symlink("x", "/missing/link"); err == nil {
+		t.Error("expected an error linking into a missing directory")
+	}
+}
+
+func TestMemFileSystem_Remove(t *testing.T) {
+	m := NewMemFileSystem()
+	m.WriteFile("/docs/a.md", []byte("a"))
+
+	if err := m.Remove("/docs"); err == nil {
+		t.Error("expected an error removing a directory that isn't empty")
+	}
+	if err := m.Remove("/docs/a.md"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if info, _ := m.Stat("/docs/a.md"); info.IsExist() {
+		t.Error("expected the file to be removed")
+	}
+	if err := m.Remove("/docs"); err != nil {
+		t.Errorf("unexpected error removing an empty directory: %v", err)
+	}
+	if err := m.Remove("/docs"); !os.IsNotExist(err) {
+		t.Errorf("expected a not-exist error, got %v", err)
+	}
+}
+
+func TestMemFileSystem_Concurrent(t *testing.T) {
+	m := NewMemFileSystem()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			dir := fmt.Sprintf("/docs/section%d", i%3)
+			m.MkdirAll(dir, 0755)
+			w, err := m.Create(fmt.Sprintf("%s/page%d.md", dir, i))
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+				return
+			}
+			fmt.Fprintf(w, "# Page %d", i)
+			w.Close()
+			m.ReadDir("/docs")
+		}(i)
+	}
+	wg.Wait()
+
+	total := 0
+	sections, _ := m.ReadDir("/docs")
+	for _, section := range sections {
+		pages, _ := m.ReadDir("/docs/" + section.Name())
+		total += len(pages)
+	}
+	if total != 8 {
+		t.Errorf("expected 8 pages, got %d", total)
+	}
+}
+
+func TestMemFileSystem_WriteThroughSymlinkedDirectory(t *testing.T) {
+	m := NewMemFileSystem()
+	m.MkdirAll("/docs/v3", 0755)
+	m.Symlink("v3", "/docs/latest")
+
+	if err := m.MkdirAll("/docs/latest/guide", 0755); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := m.WriteFile("/docs/latest/guide/setup.md", []byte("# Setup")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	data, err := m.ReadFile("/docs/v3/guide/setup.md")
+	if err != nil || string(data) != "# Setup" {
+		t.Errorf("expected the file to be written below the link's target, got %q, %v", data, err)
+	}
+}
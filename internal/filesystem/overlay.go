@@ -0,0 +1,91 @@
+package filesystem
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"sort"
+	"syscall"
+)
+
+// ReadOnlyFileSystem is the part of FileSystem that readers such as the
+// server need
+type ReadOnlyFileSystem interface {
+	ReadFile(filename string) ([]byte, error)
+	ReadDir(name string) ([]os.DirEntry, error)
+	Stat(name string) (FileInfo, error)
+}
+
+// Overlay stacks read-only file systems: a file is read from the first
+// layer that has it, and directories list the entries of every layer, with
+// earlier layers hiding later ones of the same name. It can put local edits
+// over an embedded or archived docs set without changing it, or fall back
+// to the disk for paths outside an archive.
+type Overlay struct {
+	layers []ReadOnlyFileSystem
+}
+
+// NewOverlay stacks layers, the first on top
+func NewOverlay(layers ...ReadOnlyFileSystem) *Overlay {
+	return &Overlay{layers: layers}
+}
+
+func (o *Overlay) ReadFile(filename string) ([]byte, error) {
+	for _, layer := range o.layers {
+		data, err := layer.ReadFile(filename)
+		if err == nil || !missing(err) {
+			return data, err
+		}
+	}
+	return nil, &fs.PathError{Op: "open", Path: filename, Err: fs.ErrNotExist}
+}
+
+func (o *Overlay) ReadDir(name string) ([]os.DirEntry, error) {
+	seen := make(map[string]bool)
+	var entries []os.DirEntry
+	found := false
+
+	for _, layer := range o.layers {
+		layerEntries, err := layer.ReadDir(name)
+		if err != nil {
+			if missing(err) {
+				continue
+			}
+			return nil, err
+		}
+		found = true
+		for _, entry := range layerEntries {
+			if !seen[entry.Name()] {
+				seen[entry.Name()] = true
+				entries = append(entries, entry)
+			}
+		}
+	}
+
+	if !found {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Name() < entries[j].Name()
+	})
+	return entries, nil
+}
+
+func (o *Overlay) Stat(name string) (FileInfo, error) {
+	for _, layer := range o.layers {
+		info, err := layer.Stat(name)
+		if err == nil && info.IsExist() {
+			return info, nil
+		}
+		if err != nil && !missing(err) {
+			return info, err
+		}
+	}
+	return &OSFileInfo{exists: false}, &fs.PathError{Op: "stat", Path: name, Err: fs.ErrNotExist}
+}
+
+// missing reports whether err means a layer doesn't have a path, including
+// when a file in the layer stands where the path needs a directory
+func missing(err error) bool {
+	return errors.Is(err, fs.ErrNotExist) || errors.Is(err, syscall.ENOTDIR)
+}
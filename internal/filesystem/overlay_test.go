@@ -0,0 +1,125 @@
+package filesystem
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// failingFileSystem fails every read
+type failingFileSystem struct{ err error }
+
+func (f failingFileSystem) ReadFile(string) ([]byte, error)       { return nil, f.err }
+func (f failingFileSystem) ReadDir(string) ([]os.DirEntry, error) { return nil, f.err }
+func (f failingFileSystem) Stat(string) (FileInfo, error)         { return nil, f.err }
+
+func TestOverlay(t *testing.T) {
+	upper := NewMemFileSystem()
+	upper.WriteFile("/docs/index.md", []byte("# Local home"))
+	upper.WriteFile("/docs/notes.md", []byte("# Notes"))
+
+	lower := NewMemFileSystem()
+	lower.WriteFile("/docs/index.md", []byte("# Home"))
+	lower.WriteFile("/docs/guide/setup.md", []byte("# Setup"))
+
+	overlay := NewOverlay(upper, lower)
+
+	tests := []struct {
+		path     string
+		expected string
+		wantErr  bool
+	}{
+		{path: "/docs/index.md", expected: "# Local home"},
+		{path: "/docs/notes.md", expected: "# Notes"},
+		{path: "/docs/guide/setup.md", expected: "# Setup"},
+		{path: "/docs/missing.md", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			data, err := overlay.ReadFile(tt.path)
+			if tt.wantErr {
+				if !os.IsNotExist(err) {
+					t.Errorf("expected a not-exist error, got %v", err)
+				}
+				if info, err := overlay.Stat(tt.path); info.IsExist() || !os.IsNotExist(err) {
+					t.Errorf("expected stat to report a missing file, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if string(data) != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, data)
+			}
+			if info, err := overlay.Stat(tt.path); err != nil || info.Size() != int64(len(tt.expected)) {
+				t.Errorf("expected stat of the visible file, got %v", err)
+			}
+		})
+	}
+
+	entries, err := overlay.ReadDir("/docs")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := []string{"guide", "index.md", "notes.md"}; !reflect.DeepEqual(entryNames(entries), expected) {
+		t.Errorf("expected %v, got %v", expected, entryNames(entries))
+	}
+	if _, err := overlay.ReadDir("/missing"); !os.IsNotExist(err) {
+		t.Errorf("expected a not-exist error, got %v", err)
+	}
+}
+
+func TestOverlay_Errors(t *testing.T) {
+	failure := errors.New("permission denied")
+	lower := NewMemFileSystem()
+	lower.WriteFile("/docs/index.md", []byte("# Home"))
+
+	overlay := NewOverlay(failingFileSystem{err: failure}, lower)
+	if _, err := overlay.ReadFile("/docs/index.md"); !errors.Is(err, failure) {
+		t.Errorf("expected errors other than not-exist to stop the lookup, got %v", err)
+	}
+	if _, err := overlay.ReadDir("/docs"); !errors.Is(err, failure) {
+		t.Errorf("expected errors other than not-exist to stop the listing, got %v", err)
+	}
+	if _, err := overlay.Stat("/docs/index.md"); !errors.Is(err, failure) {
+		t.Errorf("expected errors other than not-exist to stop stat, got %v", err)
+	}
+}
+
+func TestOverlay_ArchiveOverDisk(t *testing.T) {
+	dir := t.TempDir()
+	archivePath := filepath.Join(dir, "docs.zip")
+	file, err := os.Create(archivePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	archive := NewZipArchive(file)
+	writeEntries(t, archive)
+	archive.Close()
+	os.WriteFile(filepath.Join(dir, "users.htpasswd"), []byte("alice:hash"), 0644)
+
+	zfs, err := OpenZip(archivePath)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer zfs.Close()
+	overlay := NewOverlay(zfs, OSFileSystem{})
+
+	if data, err := overlay.ReadFile(filepath.Join(dir, "users.htpasswd")); err != nil || string(data) != "alice:hash" {
+		t.Errorf("expected files outside the archive to come from disk, got %q, %v", data, err)
+	}
+	entries, err := overlay.ReadDir(archivePath)
+	if err != nil {
+		t.Fatalf("expected the archive to list as a directory, got %v", err)
+	}
+	if expected := []string{"guide", "index.md"}; !reflect.DeepEqual(entryNames(entries), expected) {
+		t.Errorf("expected %v, got %v", expected, entryNames(entries))
+	}
+	if info, err := overlay.Stat(filepath.Join(archivePath, "missing.md")); info.IsExist() || !os.IsNotExist(err) {
+		t.Errorf("expected a missing page to not exist, got %v", err)
+	}
+}
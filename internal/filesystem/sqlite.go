@@ -0,0 +1,164 @@
+package filesystem
+
+import (
+	"bytes"
+	"database/sql"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+const sqliteSchema = `CREATE TABLE IF NOT EXISTS pages (
+	path         TEXT PRIMARY KEY,
+	url          TEXT,
+	title        TEXT,
+	markdown     TEXT NOT NULL,
+	bytes        INTEGER NOT NULL,
+	duplicate_of TEXT,
+	scraped_at   TEXT NOT NULL
+)`
+
+// SQLiteDatabase writes files as rows of a pages table instead of to a
+// directory: one row per page with its path, source URL, title, markdown,
+// size and the time it was scraped. It implements the scraper's FileSystem
+// interface; symlinks become rows whose duplicate_of names the original.
+type SQLiteDatabase struct {
+	db  *sql.DB
+	now func() time.Time
+
+	mu      sync.Mutex
+	sources map[string]string // source URLs recorded for paths not yet written
+}
+
+// OpenSQLite opens or creates the database at path and its pages table
+func OpenSQLite(dbPath string) (*SQLiteDatabase, error) {
+	db, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database %s: %w", dbPath, err)
+	}
+	// SQLite allows a single writer; queue workers on one connection
+	db.SetMaxOpenConns(1)
+
+	if _, err := db.Exec(sqliteSchema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create pages table in %s: %w", dbPath, err)
+	}
+
+	return &SQLiteDatabase{
+		db:      db,
+		now:     time.Now,
+		sources: make(map[string]string),
+	}, nil
+}
+
+// RecordSource remembers the URL the file at path is scraped from
+func (d *SQLiteDatabase) RecordSource(filePath, sourceURL string) {
+	name, err := entryName(filePath)
+	if err != nil {
+		return
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.sources[name] = sourceURL
+}
+
+// Create returns a file that is stored as a row when it is closed
+func (d *SQLiteDatabase) Create(name string) (io.WriteCloser, error) {
+	entry, err := entryName(name)
+	if err != nil {
+		return nil, err
+	}
+	return &sqliteFile{db: d, name: entry}, nil
+}
+
+// MkdirAll does nothing; rows are keyed by their full path
+func (d *SQLiteDatabase) MkdirAll(path string, perm int) error {
+	return nil
+}
+
+// Symlink stores newname as a duplicate of the page at oldname, relative to
+// newname's directory
+func (d *SQLiteDatabase) Symlink(oldname, newname string) error {
+	entry, err := entryName(newname)
+	if err != nil {
+		return err
+	}
+	original := path.Join(path.Dir(entry), strings.ReplaceAll(oldname, `\`, "/"))
+	return d.insert(entry, "", original)
+}
+
+// Close closes the database
+func (d *SQLiteDatabase) Close() error {
+	return d.db.Close()
+}
+
+func (d *SQLiteDatabase) insert(name, markdown, duplicateOf string) error {
+	d.mu.Lock()
+	sourceURL := d.sources[name]
+	delete(d.sources, name)
+	d.mu.Unlock()
+
+	_, err := d.db.Exec(
+		`INSERT OR REPLACE INTO pages (path, url, title, markdown, bytes, duplicate_of, scraped_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		name,
+		nullable(sourceURL),
+		nullable(markdownTitle(markdown)),
+		markdown,
+		len(markdown),
+		nullable(duplicateOf),
+		d.now().UTC().Format(time.RFC3339),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to store %s: %w", name, err)
+	}
+	return nil
+}
+
+// nullable stores empty strings as NULL
+func nullable(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+// markdownTitle returns the text of the first level-one heading
+func markdownTitle(markdown string) string {
+	for _, line := range strings.Split(markdown, "\n") {
+		if title, ok := strings.CutPrefix(strings.TrimSpace(line), "# "); ok {
+			return strings.TrimSpace(title)
+		}
+	}
+	return ""
+}
+
+// sqliteFile buffers a page until it is closed
+type sqliteFile struct {
+	db     *SQLiteDatabase
+	name   string
+	buf    bytes.Buffer
+	closed bool
+}
+
+func (f *sqliteFile) Write(p []byte) (int, error) {
+	if f.closed {
+		return 0, os.ErrClosed
+	}
+	return f.buf.Write(p)
+}
+
+func (f *sqliteFile) Close() error {
+	if f.closed {
+		return nil
+	}
+	f.closed = true
+	return f.db.insert(f.name, f.buf.String(), "")
+}
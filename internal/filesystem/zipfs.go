@@ -0,0 +1,37 @@
+package filesystem
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"path/filepath"
+)
+
+// ZipFileSystem reads a zip archive as if it were extracted to a directory
+// at the archive's own path, so the server can be pointed at docs.zip just
+// like at a docs directory. Symlink entries are followed.
+type ZipFileSystem struct {
+	*IOFileSystem
+	closer io.Closer
+}
+
+// OpenZip opens the zip archive at path for reading
+func OpenZip(archivePath string) (*ZipFileSystem, error) {
+	root, err := filepath.Abs(archivePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get absolute path for %s: %w", archivePath, err)
+	}
+	reader, err := zip.OpenReader(root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open zip %s: %w", archivePath, err)
+	}
+	return &ZipFileSystem{
+		IOFileSystem: &IOFileSystem{fsys: reader, root: root},
+		closer:       reader,
+	}, nil
+}
+
+// Close closes the archive
+func (z *ZipFileSystem) Close() error {
+	return z.closer.Close()
+}
@@ -0,0 +1,84 @@
+package httpclient
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// httpOnlyPrefix marks HttpOnly cookies in files exported by curl and browsers
+const httpOnlyPrefix = "#HttpOnly_"
+
+// LoadCookieFile reads a Netscape cookies.txt file into a cookie jar
+func LoadCookieFile(path string) (http.CookieJar, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open cookie file: %w", err)
+	}
+	defer file.Close()
+
+	return ParseCookies(file)
+}
+
+// ParseCookies parses Netscape cookies.txt content into a cookie jar
+func ParseCookies(r io.Reader) (http.CookieJar, error) {
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	scanner := bufio.NewScanner(r)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+
+		httpOnly := strings.HasPrefix(line, httpOnlyPrefix)
+		line = strings.TrimPrefix(line, httpOnlyPrefix)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Split(line, "\t")
+		if len(fields) != 7 {
+			return nil, fmt.Errorf("cookie file line %d: expected 7 tab-separated fields, got %d", lineNumber, len(fields))
+		}
+
+		domain, path, secure, expiry, name, value := fields[0], fields[2], fields[3], fields[4], fields[5], fields[6]
+
+		cookie := &http.Cookie{
+			Name:     name,
+			Value:    value,
+			Path:     path,
+			Secure:   strings.EqualFold(secure, "TRUE"),
+			HttpOnly: httpOnly,
+		}
+		// a leading dot (or the include-subdomains flag) makes it a domain cookie
+		if strings.HasPrefix(domain, ".") || strings.EqualFold(fields[1], "TRUE") {
+			cookie.Domain = domain
+		}
+		if seconds, err := strconv.ParseInt(expiry, 10, 64); err == nil && seconds > 0 {
+			cookie.Expires = time.Unix(seconds, 0)
+		}
+
+		scheme := "http"
+		if cookie.Secure {
+			scheme = "https"
+		}
+		host := strings.TrimPrefix(domain, ".")
+		jar.SetCookies(&url.URL{Scheme: scheme, Host: host, Path: path}, []*http.Cookie{cookie})
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read cookie file: %w", err)
+	}
+
+	return jar, nil
+}
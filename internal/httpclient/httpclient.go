@@ -0,0 +1,155 @@
+package httpclient
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// Environment variables holding credentials, so they stay out of shell history
+const (
+	EnvBearerToken = "MDIFY_BEARER_TOKEN"
+	EnvUsername    = "MDIFY_USERNAME"
+	EnvPassword    = "MDIFY_PASSWORD"
+)
+
+// Options configures the HTTP client shared by the scraper and sitemap services
+type Options struct {
+	Timeout     time.Duration
+	UserAgent   string
+	Headers     map[string]string
+	BearerToken string
+	Username    string
+	Password    string
+	CookieFile  string   // Netscape cookies.txt file
+	AuthHosts   []string // hosts that receive headers, credentials and cookies; none when empty
+
+	Proxy    string // http, https or socks5 proxy URL; the environment's proxy settings when empty
+	CAFile   string // PEM bundle trusted in addition to the system roots
+	CertFile string // PEM client certificate for mutual TLS
+	KeyFile  string // PEM key for CertFile
+	Insecure bool   // skip TLS certificate verification
+}
+
+// FromEnv fills in credentials from the MDIFY_* environment variables
+func (o Options) FromEnv() Options {
+	if token := os.Getenv(EnvBearerToken); token != "" {
+		o.BearerToken = token
+	}
+	if username := os.Getenv(EnvUsername); username != "" {
+		o.Username = username
+		o.Password = os.Getenv(EnvPassword)
+	}
+	return o
+}
+
+// New creates an http.Client that adds the configured headers, credentials
+// and cookies to requests for AuthHosts
+func New(opts Options) (*http.Client, error) {
+	transport, err := newTransport(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	client := &http.Client{
+		Timeout: opts.Timeout,
+		Transport: &headerTransport{
+			base:    transport,
+			options: opts,
+		},
+	}
+
+	if opts.CookieFile != "" {
+		jar, err := LoadCookieFile(opts.CookieFile)
+		if err != nil {
+			return nil, err
+		}
+		client.Jar = jar
+	}
+
+	return client, nil
+}
+
+// ParseHeader parses a "Name: value" header flag
+func ParseHeader(header string) (string, string, error) {
+	name, value, ok := strings.Cut(header, ":")
+	name = strings.TrimSpace(name)
+	if !ok || name == "" {
+		return "", "", fmt.Errorf("invalid header %q (expected \"Name: value\")", header)
+	}
+	return name, strings.TrimSpace(value), nil
+}
+
+// headerTransport sets headers on outgoing requests before handing them to base
+type headerTransport struct {
+	base    http.RoundTripper
+	options Options
+}
+
+func (t *headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// RoundTrippers must not modify the caller's request
+	req = req.Clone(req.Context())
+
+	if t.options.UserAgent != "" {
+		req.Header.Set("User-Agent", t.options.UserAgent)
+	}
+
+	if !t.allowedHost(req.URL.Hostname()) {
+		req.Header.Del("Cookie")
+		return t.base.RoundTrip(req)
+	}
+	if crossHostRedirect(req) {
+		return t.base.RoundTrip(req)
+	}
+
+	for name, value := range t.options.Headers {
+		req.Header.Set(name, value)
+	}
+	if t.options.BearerToken != "" {
+		req.Header.Set("Authorization", "Bearer "+t.options.BearerToken)
+	} else if t.options.Username != "" {
+		req.SetBasicAuth(t.options.Username, t.options.Password)
+	}
+
+	return t.base.RoundTrip(req)
+}
+
+// allowedHost reports whether host may receive headers, credentials and cookies
+func (t *headerTransport) allowedHost(host string) bool {
+	for _, allowed := range t.options.AuthHosts {
+		if strings.EqualFold(host, allowed) {
+			return true
+		}
+	}
+	return false
+}
+
+// crossHostRedirect reports whether req follows a redirect from another
+// host, which never gets headers or credentials even when both are allowed
+func crossHostRedirect(req *http.Request) bool {
+	if req.Response == nil || req.Response.Request == nil {
+		return false
+	}
+	return !strings.EqualFold(req.URL.Hostname(), req.Response.Request.URL.Hostname())
+}
+
+// URLHosts returns the distinct hosts of urls, for use as AuthHosts
+func URLHosts(urls []string) []string {
+	var hosts []string
+	seen := make(map[string]bool)
+	for _, raw := range urls {
+		u, err := url.Parse(raw)
+		if err != nil || u.Hostname() == "" {
+			continue
+		}
+		host := strings.ToLower(u.Hostname())
+		if !seen[host] {
+			seen[host] = true
+			hosts = append(hosts, host)
+		}
+	}
+	return hosts
+}
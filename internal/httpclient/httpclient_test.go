@@ -0,0 +1,182 @@
+package httpclient
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestNew_Headers(t *testing.T) {
+	var received http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header.Clone()
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name     string
+		options  Options
+		expected map[string]string
+	}{
+		{
+			name:    "user agent and custom headers",
+			options: Options{UserAgent: "mdify-test", Headers: map[string]string{"X-Docs-Key": "abc"}, AuthHosts: []string{"127.0.0.1"}},
+			expected: map[string]string{
+				"User-Agent": "mdify-test",
+				"X-Docs-Key": "abc",
+			},
+		},
+		{
+			name:     "bearer token",
+			options:  Options{BearerToken: "secret", AuthHosts: []string{"127.0.0.1"}},
+			expected: map[string]string{"Authorization": "Bearer secret"},
+		},
+		{
+			name:     "basic auth",
+			options:  Options{Username: "user", Password: "pass", AuthHosts: []string{"127.0.0.1"}},
+			expected: map[string]string{"Authorization": "Basic dXNlcjpwYXNz"},
+		},
+		{
+			name:     "credentials withheld from other hosts",
+			options:  Options{BearerToken: "secret", Headers: map[string]string{"X-Docs-Key": "abc"}, AuthHosts: []string{"docs.internal"}},
+			expected: map[string]string{"Authorization": "", "X-Docs-Key": ""},
+		},
+		{
+			name:     "credentials withheld without auth hosts",
+			options:  Options{UserAgent: "mdify-test", BearerToken: "secret"},
+			expected: map[string]string{"User-Agent": "mdify-test", "Authorization": ""},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := New(tt.options)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			resp, err := client.Get(server.URL)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			resp.Body.Close()
+
+			for name, value := range tt.expected {
+				if got := received.Get(name); got != value {
+					t.Errorf("expected %s header %q, got %q", name, value, got)
+				}
+			}
+		})
+	}
+}
+
+func TestNew_Redirects(t *testing.T) {
+	var received http.Header
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header.Clone()
+	}))
+	defer target.Close()
+	// the same server under another host name
+	otherHost := strings.Replace(target.URL, "127.0.0.1", "localhost", 1)
+
+	origin := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, r.URL.Query().Get("to"), http.StatusFound)
+	}))
+	defer origin.Close()
+
+	client, err := New(Options{
+		BearerToken: "secret",
+		Headers:     map[string]string{"X-Docs-Key": "abc"},
+		AuthHosts:   []string{"127.0.0.1", "localhost"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		to       string
+		expected string
+	}{
+		{name: "same host", to: target.URL, expected: "Bearer secret"},
+		{name: "other allowed host", to: otherHost, expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			received = nil
+			resp, err := client.Get(origin.URL + "/?to=" + url.QueryEscape(tt.to))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			resp.Body.Close()
+
+			if got := received.Get("Authorization"); got != tt.expected {
+				t.Errorf("expected Authorization %q after the redirect, got %q", tt.expected, got)
+			}
+			if tt.expected == "" && received.Get("X-Docs-Key") != "" {
+				t.Error("expected custom headers to be withheld after a cross-host redirect")
+			}
+		})
+	}
+}
+
+func TestURLHosts(t *testing.T) {
+	hosts := URLHosts([]string{"", "https://Docs.Example.com/sitemap.xml", "https://docs.example.com/a", "http://api.example.com:8080/b", "not a url"})
+	if len(hosts) != 2 || hosts[0] != "docs.example.com" || hosts[1] != "api.example.com" {
+		t.Errorf("unexpected hosts %v", hosts)
+	}
+}
+
+func TestOptions_FromEnv(t *testing.T) {
+	t.Setenv(EnvBearerToken, "token")
+	t.Setenv(EnvUsername, "user")
+	t.Setenv(EnvPassword, "pass")
+
+	options := Options{}.FromEnv()
+
+	if options.BearerToken != "token" || options.Username != "user" || options.Password != "pass" {
+		t.Errorf("expected credentials from environment, got %+v", options)
+	}
+}
+
+func TestParseHeader(t *testing.T) {
+	name, value, err := ParseHeader("X-Api-Version:  2 ")
+	if err != nil || name != "X-Api-Version" || value != "2" {
+		t.Errorf("unexpected result: %q %q %v", name, value, err)
+	}
+
+	if _, _, err := ParseHeader("no-colon"); err == nil {
+		t.Errorf("expected error for header without colon")
+	}
+}
+
+func TestParseCookies(t *testing.T) {
+	content := strings.Join([]string{
+		"# Netscape HTTP Cookie File",
+		"",
+		".example.com\tTRUE\t/\tTRUE\t0\tsession\tabc123",
+		"#HttpOnly_docs.internal\tFALSE\t/docs\tFALSE\t0\tsso\txyz",
+	}, "\n")
+
+	jar, err := ParseCookies(strings.NewReader(content))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	cookies := jar.Cookies(&url.URL{Scheme: "https", Host: "www.example.com", Path: "/"})
+	if len(cookies) != 1 || cookies[0].Name != "session" || cookies[0].Value != "abc123" {
+		t.Errorf("expected domain cookie for subdomain, got %v", cookies)
+	}
+
+	cookies = jar.Cookies(&url.URL{Scheme: "http", Host: "docs.internal", Path: "/docs/api"})
+	if len(cookies) != 1 || cookies[0].Name != "sso" {
+		t.Errorf("expected HttpOnly host cookie, got %v", cookies)
+	}
+
+	if _, err := ParseCookies(strings.NewReader("bad line")); err == nil {
+		t.Errorf("expected error for malformed line")
+	}
+}
@@ -0,0 +1,107 @@
+package httpclient
+
+import (
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNew_Proxy(t *testing.T) {
+	var proxied string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = r.URL.String()
+	}))
+	defer proxy.Close()
+
+	client, err := New(Options{Proxy: proxy.URL})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	resp, err := client.Get("http://docs.example.invalid/guide")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp.Body.Close()
+
+	if proxied != "http://docs.example.invalid/guide" {
+		t.Errorf("expected request to go through proxy, got %q", proxied)
+	}
+}
+
+func TestParseProxy(t *testing.T) {
+	tests := []struct {
+		url      string
+		hasError bool
+	}{
+		{"http://proxy.corp:3128", false},
+		{"socks5://127.0.0.1:1080", false},
+		{"ftp://proxy.corp", true},
+		{"http://", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			_, err := ParseProxy(tt.url)
+			if tt.hasError && err == nil {
+				t.Errorf("expected error but got none")
+			}
+			if !tt.hasError && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestNew_TLS(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(caFile, caPEM, 0644); err != nil {
+		t.Fatalf("failed to write CA file: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		options  Options
+		hasError bool
+	}{
+		{name: "untrusted certificate", options: Options{}, hasError: true},
+		{name: "custom CA bundle", options: Options{CAFile: caFile}, hasError: false},
+		{name: "insecure mode", options: Options{Insecure: true}, hasError: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := New(tt.options)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			resp, err := client.Get(server.URL)
+			if err == nil {
+				resp.Body.Close()
+			}
+			if tt.hasError && err == nil {
+				t.Errorf("expected error but got none")
+			}
+			if !tt.hasError && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestNew_InvalidTLSOptions(t *testing.T) {
+	if _, err := New(Options{CAFile: "/non/existent/ca.pem"}); err == nil {
+		t.Errorf("expected error for missing CA file")
+	}
+	if _, err := New(Options{CertFile: "client.pem"}); err == nil {
+		t.Errorf("expected error for cert without key")
+	}
+}
@@ -0,0 +1,215 @@
+package chunk
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"mdify/internal/docs"
+	"mdify/internal/filesystem"
+)
+
+// Units a chunk budget can be measured in
+const (
+	UnitTokens = "tokens" // estimated with EstimateTokens
+	UnitChars  = "chars"
+)
+
+// Defaults for the chunk budget, in tokens
+const (
+	DefaultMaxSize = 512
+	DefaultOverlap = 64
+)
+
+// charsPerToken is the average number of characters per token in English
+// prose, used to estimate token counts without a model-specific tokenizer
+const charsPerToken = 4
+
+// FileSystem interface for reading the docs corpus
+type FileSystem interface {
+	ReadFile(filename string) ([]byte, error)
+	ReadDir(name string) ([]os.DirEntry, error)
+	Stat(name string) (filesystem.FileInfo, error)
+}
+
+// Logger interface for leveled, structured logging; *slog.Logger satisfies it
+type Logger interface {
+	Debug(msg string, args ...interface{})
+	Info(msg string, args ...interface{})
+	Warn(msg string, args ...interface{})
+	Error(msg string, args ...interface{})
+}
+
+// Config holds configuration for chunking
+type Config struct {
+	MaxSize int    // budget per chunk, in Unit
+	Overlap int    // text repeated from the end of the previous chunk, in Unit
+	Unit    string // tokens or chars
+	BaseURL string // prefix for the source URLs of documents read from disk
+}
+
+// Validate checks that the budget can be met
+func (c Config) Validate() error {
+	if c.Unit != UnitTokens && c.Unit != UnitChars {
+		return fmt.Errorf("invalid chunk unit %q (use tokens or chars)", c.Unit)
+	}
+	if c.MaxSize <= 0 {
+		return fmt.Errorf("chunk size must be positive, got %d", c.MaxSize)
+	}
+	if c.Overlap < 0 || c.Overlap >= c.MaxSize {
+		return fmt.Errorf("chunk overlap must be between 0 and the chunk size, got %d", c.Overlap)
+	}
+	return nil
+}
+
+// Chunk is one piece of a document, ready to be embedded
+type Chunk struct {
+	ID          string   `json:"id"`
+	SourceURL   string   `json:"source_url,omitempty"`
+	FilePath    string   `json:"file_path"`
+	HeadingPath []string `json:"heading_path"`
+	Text        string   `json:"text"`
+}
+
+// Service splits markdown documents into chunks
+type Service struct {
+	fs     FileSystem
+	logger Logger
+	config Config
+}
+
+// NewService creates a new chunking service
+func NewService(fs FileSystem, logger Logger, config Config) *Service {
+	if config.Unit == "" {
+		config.Unit = UnitTokens
+	}
+	if config.MaxSize <= 0 {
+		config.MaxSize = DefaultMaxSize
+	}
+	config.BaseURL = strings.TrimSuffix(config.BaseURL, "/")
+
+	return &Service{
+		fs:     fs,
+		logger: logger,
+		config: config,
+	}
+}
+
+// EstimateTokens approximates how many tokens an LLM tokenizer produces for text
+func EstimateTokens(text string) int {
+	return (utf8.RuneCountInString(text) + charsPerToken - 1) / charsPerToken
+}
+
+// size measures text in the configured unit
+func (s *Service) size(text string) int {
+	if s.config.Unit == UnitChars {
+		return utf8.RuneCountInString(text)
+	}
+	return EstimateTokens(text)
+}
+
+// ChunkDocument splits a markdown document along its headings. filePath is
+// the slash-separated path the document is stored at and identifies its
+// chunks.
+func (s *Service) ChunkDocument(sourceURL, filePath, markdown string) []Chunk {
+	body, frontMatterURL := splitFrontMatter(markdown)
+	if sourceURL == "" {
+		sourceURL = frontMatterURL
+	}
+
+	var chunks []Chunk
+	for _, section := range splitSections(body) {
+		for _, text := range s.pack(section.Text) {
+			chunks = append(chunks, Chunk{
+				ID:          fmt.Sprintf("%s#%d", filePath, len(chunks)),
+				SourceURL:   sourceURL,
+				FilePath:    filePath,
+				HeadingPath: section.Headings,
+				Text:        text,
+			})
+		}
+	}
+	return chunks
+}
+
+// ChunkDir writes the chunks of every markdown document below dir to w as
+// JSONL and returns how many were written
+func (s *Service) ChunkDir(dir string, w io.Writer) (int, error) {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get absolute path for %s: %w", dir, err)
+	}
+	if info, err := s.fs.Stat(absDir); err != nil || !info.IsExist() {
+		return 0, fmt.Errorf("directory does not exist: %s", absDir)
+	}
+
+	documents, err := docs.List(s.fs, absDir)
+	if err != nil {
+		return 0, err
+	}
+
+	writer := s.NewWriter(w)
+	for _, doc := range documents {
+		content, err := s.fs.ReadFile(filepath.Join(absDir, filepath.FromSlash(doc.File)))
+		if err != nil {
+			return writer.Count(), fmt.Errorf("failed to read %s: %w", doc.File, err)
+		}
+
+		sourceURL := ""
+		if s.config.BaseURL != "" {
+			sourceURL = s.config.BaseURL + doc.Path
+		}
+		if err := writer.WriteDocument(sourceURL, doc.File, string(content)); err != nil {
+			return writer.Count(), err
+		}
+	}
+
+	s.logger.Info("Chunked documents", "dir", absDir, "documents", len(documents), "chunks", writer.Count())
+	return writer.Count(), nil
+}
+
+// Writer exports the chunks of each document it is given as JSONL. It is
+// safe for concurrent use, so scraper workers can share one.
+type Writer struct {
+	service *Service
+
+	mu    sync.Mutex
+	out   *json.Encoder
+	count int
+}
+
+// NewWriter creates a writer that encodes chunks to w
+func (s *Service) NewWriter(w io.Writer) *Writer {
+	out := json.NewEncoder(w)
+	out.SetEscapeHTML(false)
+	return &Writer{service: s, out: out}
+}
+
+// WriteDocument chunks a markdown document and writes one JSON line per chunk
+func (w *Writer) WriteDocument(sourceURL, filePath, markdown string) error {
+	chunks := w.service.ChunkDocument(sourceURL, filePath, markdown)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for _, chunk := range chunks {
+		if err := w.out.Encode(chunk); err != nil {
+			return fmt.Errorf("failed to write chunks for %s: %w", filePath, err)
+		}
+		w.count++
+	}
+	w.service.logger.Debug("Chunked document", "path", filePath, "chunks", len(chunks))
+	return nil
+}
+
+// Count returns how many chunks have been written
+func (w *Writer) Count() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.count
+}
@@ -0,0 +1,216 @@
+package chunk
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"mdify/internal/filesystem"
+)
+
+func TestConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name        string
+		config      Config
+		expectError bool
+	}{
+		{name: "tokens", config: Config{Unit: UnitTokens, MaxSize: 512, Overlap: 64}},
+		{name: "chars without overlap", config: Config{Unit: UnitChars, MaxSize: 2000}},
+		{name: "unknown unit", config: Config{Unit: "words", MaxSize: 100}, expectError: true},
+		{name: "zero size", config: Config{Unit: UnitTokens}, expectError: true},
+		{name: "negative overlap", config: Config{Unit: UnitTokens, MaxSize: 100, Overlap: -1}, expectError: true},
+		{name: "overlap as large as the chunk", config: Config{Unit: UnitTokens, MaxSize: 100, Overlap: 100}, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.Validate()
+			if tt.expectError && err == nil {
+				t.Error("expected error but got none")
+			}
+			if !tt.expectError && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestEstimateTokens(t *testing.T) {
+	tests := []struct {
+		text     string
+		expected int
+	}{
+		{text: "", expected: 0},
+		{text: "abc", expected: 1},
+		{text: "abcd", expected: 1},
+		{text: "abcde", expected: 2},
+		{text: "héllo wörld!", expected: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			if got := EstimateTokens(tt.text); got != tt.expected {
+				t.Errorf("expected %d, got %d", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestService_ChunkDocument(t *testing.T) {
+	service := NewService(filesystem.NewMemFileSystem(), NewMockLogger(), Config{})
+	markdown := "---\nurl: https://example.com/guide\n---\n# Guide\n\nIntro.\n\n## Install\n\nSteps."
+
+	tests := []struct {
+		name        string
+		sourceURL   string
+		expectedURL string
+	}{
+		{name: "source URL from front matter", expectedURL: "https://example.com/guide"},
+		{name: "explicit source URL wins", sourceURL: "https://docs.example.com/guide", expectedURL: "https://docs.example.com/guide"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chunks := service.ChunkDocument(tt.sourceURL, "guide.md", markdown)
+
+			expected := []Chunk{
+				{ID: "guide.md#0", SourceURL: tt.expectedURL, FilePath: "guide.md", HeadingPath: []string{"Guide"}, Text: "# Guide\n\nIntro."},
+				{ID: "guide.md#1", SourceURL: tt.expectedURL, FilePath: "guide.md", HeadingPath: []string{"Guide", "Install"}, Text: "## Install\n\nSteps."},
+			}
+			if !reflect.DeepEqual(chunks, expected) {
+				t.Errorf("expected %#v, got %#v", expected, chunks)
+			}
+		})
+	}
+}
+
+func TestService_ChunkDocument_SplitsLongSections(t *testing.T) {
+	service := NewService(filesystem.NewMemFileSystem(), NewMockLogger(), Config{MaxSize: 50, Overlap: 10})
+	markdown := "# Long\n\n" + strings.Repeat("Some sentence about the topic at hand.\n\n", 30)
+
+	chunks := service.ChunkDocument("", "long.md", markdown)
+	if len(chunks) < 5 {
+		t.Fatalf("expected the section to be split, got %d chunks", len(chunks))
+	}
+	for i, chunk := range chunks {
+		if !reflect.DeepEqual(chunk.HeadingPath, []string{"Long"}) {
+			t.Errorf("chunk %d: expected heading path [Long], got %v", i, chunk.HeadingPath)
+		}
+		if tokens := EstimateTokens(chunk.Text); tokens > 50 {
+			t.Errorf("chunk %d: %d tokens over the budget", i, tokens)
+		}
+	}
+}
+
+func TestService_ChunkDir(t *testing.T) {
+	tests := []struct {
+		name            string
+		baseURL         string
+		createDir       bool
+		expectError     bool
+		expectedSources []string
+	}{
+		{
+			name:            "with base URL",
+			baseURL:         "https://docs.example.com/",
+			createDir:       true,
+			expectedSources: []string{"https://docs.example.com/guide/install", "https://docs.example.com/"},
+		},
+		{
+			name:            "without base URL",
+			createDir:       true,
+			expectedSources: []string{"", ""},
+		},
+		{
+			name:        "missing directory",
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := filesystem.NewMemFileSystem()
+			logger := NewMockLogger()
+			if tt.createDir {
+				fs.WriteFile("/docs/index.md", []byte("# Home\n\nWelcome."))
+				fs.WriteFile("/docs/guide/install.md", []byte("# Install\n\nRun the installer."))
+			}
+
+			var out bytes.Buffer
+			service := NewService(fs, logger, Config{BaseURL: tt.baseURL})
+			count, err := service.ChunkDir("/docs", &out)
+
+			if tt.expectError {
+				if err == nil {
+					t.Error("expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+			if count != 2 || len(lines) != 2 {
+				t.Fatalf("expected 2 chunks, got count %d and %d lines", count, len(lines))
+			}
+
+			var sources, files []string
+			for _, line := range lines {
+				var chunk Chunk
+				if err := json.Unmarshal([]byte(line), &chunk); err != nil {
+					t.Fatalf("invalid JSON line %q: %v", line, err)
+				}
+				sources = append(sources, chunk.SourceURL)
+				files = append(files, chunk.FilePath)
+			}
+			if !reflect.DeepEqual(files, []string{"guide/install.md", "index.md"}) {
+				t.Errorf("unexpected file paths %v", files)
+			}
+			if !reflect.DeepEqual(sources, tt.expectedSources) {
+				t.Errorf("expected sources %v, got %v", tt.expectedSources, sources)
+			}
+
+			messages := logger.GetMessages()
+			if len(messages) == 0 || !strings.Contains(messages[len(messages)-1], "INFO Chunked documents dir=/docs documents=2 chunks=2") {
+				t.Errorf("expected summary log, got %v", messages)
+			}
+		})
+	}
+}
+
+func TestWriter_Concurrent(t *testing.T) {
+	var out bytes.Buffer
+	writer := NewService(filesystem.NewMemFileSystem(), NewMockLogger(), Config{}).NewWriter(&out)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := writer.WriteDocument("", "page.md", "# A\n\nOne.\n\n# B\n\nTwo <b>&</b>."); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if writer.Count() != 20 {
+		t.Errorf("expected 20 chunks, got %d", writer.Count())
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 20 {
+		t.Fatalf("expected 20 lines, got %d", len(lines))
+	}
+	for _, line := range lines {
+		if !json.Valid([]byte(line)) {
+			t.Errorf("interleaved or invalid line %q", line)
+		}
+	}
+	if !strings.Contains(out.String(), `"text":"# B\n\nTwo <b>&</b>."`) {
+		t.Errorf("expected HTML to be written unescaped, got %s", out.String())
+	}
+}
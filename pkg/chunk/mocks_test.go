@@ -0,0 +1,29 @@
+package chunk
+
+import "fmt"
+
+type MockLogger struct {
+	messages []string
+}
+
+func NewMockLogger() *MockLogger {
+	return &MockLogger{}
+}
+
+func (m *MockLogger) Debug(msg string, args ...interface{}) { m.log("DEBUG", msg, args) }
+func (m *MockLogger) Info(msg string, args ...interface{})  { m.log("INFO", msg, args) }
+func (m *MockLogger) Warn(msg string, args ...interface{})  { m.log("WARN", msg, args) }
+func (m *MockLogger) Error(msg string, args ...interface{}) { m.log("ERROR", msg, args) }
+
+// log records "LEVEL msg key=value ..." so tests can match on attributes
+func (m *MockLogger) log(level, msg string, args []interface{}) {
+	message := level + " " + msg
+	for i := 0; i+1 < len(args); i += 2 {
+		message += fmt.Sprintf(" %v=%v", args[i], args[i+1])
+	}
+	m.messages = append(m.messages, message)
+}
+
+func (m *MockLogger) GetMessages() []string {
+	return m.messages
+}
@@ -0,0 +1,289 @@
+package chunk
+
+import (
+	"strings"
+)
+
+// Section is a heading and the text below it, up to the next heading
+type Section struct {
+	Headings []string // breadcrumb from the top-level heading down to this one
+	Text     string   // the heading line followed by its body
+}
+
+type heading struct {
+	level int
+	title string
+}
+
+// Sections splits a document after its front matter at its ATX headings,
+// the way chunks are split before they are packed to size
+func Sections(markdown string) []Section {
+	body, _ := splitFrontMatter(markdown)
+	return splitSections(body)
+}
+
+// splitFrontMatter removes a leading YAML front matter block, returning the
+// rest of the document and the front matter's url or source, if any
+func splitFrontMatter(markdown string) (string, string) {
+	lines := strings.SplitAfter(markdown, "\n")
+	if strings.TrimSpace(lines[0]) != "---" {
+		return markdown, ""
+	}
+
+	sourceURL := ""
+	for i := 1; i < len(lines); i++ {
+		line := strings.TrimSpace(lines[i])
+		if line == "---" {
+			return strings.Join(lines[i+1:], ""), sourceURL
+		}
+		key, value, ok := strings.Cut(line, ":")
+		if ok && (key == "url" || key == "source") {
+			sourceURL = strings.Trim(strings.TrimSpace(value), `"'`)
+		}
+	}
+	return markdown, ""
+}
+
+// splitSections splits a document at its ATX headings, ignoring lines in
+// fenced code blocks. Sections that contain nothing but their heading are
+// dropped; their titles still appear in the breadcrumbs below them.
+func splitSections(markdown string) []Section {
+	var (
+		sections []Section
+		stack    []heading
+		lines    []string
+		hasBody  bool
+		fence    string
+	)
+
+	flush := func() {
+		if hasBody {
+			headings := make([]string, len(stack))
+			for i, h := range stack {
+				headings[i] = h.title
+			}
+			sections = append(sections, Section{
+				Headings: headings,
+				Text:     strings.TrimSpace(strings.Join(lines, "\n")),
+			})
+		}
+		lines, hasBody = nil, false
+	}
+
+	for _, line := range strings.Split(markdown, "\n") {
+		if marker := fenceMarker(line); marker != "" {
+			switch {
+			case fence == "":
+				fence = marker
+			case strings.HasPrefix(marker, fence):
+				fence = ""
+			}
+		} else if fence == "" {
+			if level, title := parseHeading(line); level > 0 {
+				flush()
+				for len(stack) > 0 && stack[len(stack)-1].level >= level {
+					stack = stack[:len(stack)-1]
+				}
+				stack = append(stack, heading{level: level, title: title})
+				lines = []string{line}
+				continue
+			}
+		}
+
+		lines = append(lines, line)
+		if strings.TrimSpace(line) != "" {
+			hasBody = true
+		}
+	}
+	flush()
+
+	return sections
+}
+
+// parseHeading returns the level and title of an ATX heading line, or 0
+func parseHeading(line string) (int, string) {
+	trimmed := strings.TrimLeft(line, " ")
+	if len(line)-len(trimmed) > 3 {
+		return 0, ""
+	}
+
+	level := 0
+	for level < len(trimmed) && trimmed[level] == '#' {
+		level++
+	}
+	if level == 0 || level > 6 {
+		return 0, ""
+	}
+
+	rest := trimmed[level:]
+	if rest != "" && rest[0] != ' ' && rest[0] != '\t' {
+		return 0, ""
+	}
+
+	title := strings.TrimSpace(rest)
+	// closing sequence, as in "## Title ##"
+	if stripped := strings.TrimRight(title, "#"); stripped == "" || strings.HasSuffix(stripped, " ") {
+		title = strings.TrimSpace(stripped)
+	}
+	return level, title
+}
+
+// fenceMarker returns the ``` or ~~~ run opening or closing a fenced code block
+func fenceMarker(line string) string {
+	trimmed := strings.TrimLeft(line, " ")
+	if len(line)-len(trimmed) > 3 {
+		return ""
+	}
+	for _, char := range []string{"`", "~"} {
+		if strings.HasPrefix(trimmed, char+char+char) {
+			n := len(trimmed) - len(strings.TrimLeft(trimmed, char))
+			return strings.Repeat(char, n)
+		}
+	}
+	return ""
+}
+
+// pack splits a section's text into chunks within the budget. Paragraphs
+// are kept whole where possible, and each chunk after the first starts with
+// the tail of the one before it.
+func (s *Service) pack(text string) []string {
+	if s.size(text) <= s.config.MaxSize {
+		return []string{text}
+	}
+
+	var pieces []string
+	for _, block := range splitBlocks(text) {
+		pieces = append(pieces, s.fit(block)...)
+	}
+
+	var chunks []string
+	current := ""
+	for _, piece := range pieces {
+		if current == "" {
+			current = piece
+			continue
+		}
+		if s.size(current+"\n\n"+piece) <= s.config.MaxSize {
+			current += "\n\n" + piece
+			continue
+		}
+
+		chunks = append(chunks, current)
+		current = piece
+		// estimates round up, so check the combined size again
+		if overlap := s.tail(piece, chunks[len(chunks)-1]); overlap != "" && s.size(overlap+"\n\n"+piece) <= s.config.MaxSize {
+			current = overlap + "\n\n" + piece
+		}
+	}
+	return append(chunks, current)
+}
+
+// tail returns the end of previous to repeat before next, as many whole
+// words as fit in the overlap without pushing next over the budget
+func (s *Service) tail(next, previous string) string {
+	budget := s.config.Overlap
+	if room := s.config.MaxSize - s.size(next+"\n\n"); room < budget {
+		budget = room
+	}
+	if budget <= 0 {
+		return ""
+	}
+
+	start := len(previous)
+	for i := len(previous) - 1; i > 0; i-- {
+		if isSpace(previous[i-1]) && !isSpace(previous[i]) {
+			if s.size(previous[i:]) > budget {
+				break
+			}
+			start = i
+		}
+	}
+	return strings.TrimSpace(previous[start:])
+}
+
+func isSpace(b byte) bool {
+	return b == ' ' || b == '\n' || b == '\t' || b == '\r'
+}
+
+// splitBlocks splits text into paragraphs at blank lines, keeping fenced
+// code blocks whole
+func splitBlocks(text string) []string {
+	var (
+		blocks  []string
+		current []string
+		fence   string
+	)
+
+	for _, line := range strings.Split(text, "\n") {
+		if marker := fenceMarker(line); marker != "" {
+			switch {
+			case fence == "":
+				fence = marker
+			case strings.HasPrefix(marker, fence):
+				fence = ""
+			}
+		}
+		if fence == "" && strings.TrimSpace(line) == "" {
+			if len(current) > 0 {
+				blocks = append(blocks, strings.Join(current, "\n"))
+				current = nil
+			}
+			continue
+		}
+		current = append(current, line)
+	}
+	if len(current) > 0 {
+		blocks = append(blocks, strings.Join(current, "\n"))
+	}
+	return blocks
+}
+
+// fit breaks a block that exceeds the budget at line breaks, then at spaces
+// for lines that are still too long, and as a last resort mid-word
+func (s *Service) fit(text string) []string {
+	if s.size(text) <= s.config.MaxSize {
+		return []string{text}
+	}
+	if strings.Contains(text, "\n") {
+		return s.join(strings.Split(text, "\n"), "\n")
+	}
+	if words := strings.Fields(text); len(words) > 1 {
+		return s.join(words, " ")
+	}
+
+	maxRunes := s.config.MaxSize
+	if s.config.Unit == UnitTokens {
+		maxRunes *= charsPerToken
+	}
+	runes := []rune(text)
+	var parts []string
+	for len(runes) > maxRunes {
+		parts = append(parts, string(runes[:maxRunes]))
+		runes = runes[maxRunes:]
+	}
+	return append(parts, string(runes))
+}
+
+// join packs parts back together with sep into as few pieces as the budget
+// allows
+func (s *Service) join(parts []string, sep string) []string {
+	var pieces []string
+	current, started := "", false
+	for _, part := range parts {
+		for _, p := range s.fit(part) {
+			switch {
+			case !started:
+				current, started = p, true
+			case s.size(current+sep+p) <= s.config.MaxSize:
+				current += sep + p
+			default:
+				pieces = append(pieces, current)
+				current = p
+			}
+		}
+	}
+	if started {
+		pieces = append(pieces, current)
+	}
+	return pieces
+}
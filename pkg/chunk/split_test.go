@@ -0,0 +1,226 @@
+package chunk
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"mdify/internal/filesystem"
+)
+
+func TestSplitFrontMatter(t *testing.T) {
+	tests := []struct {
+		name        string
+		markdown    string
+		expectedURL string
+		expected    string
+	}{
+		{
+			name:     "no front matter",
+			markdown: "# Title\n\nBody",
+			expected: "# Title\n\nBody",
+		},
+		{
+			name:        "url field",
+			markdown:    "---\ntitle: Page\nurl: \"https://example.com/page\"\n---\n# Title\n",
+			expectedURL: "https://example.com/page",
+			expected:    "# Title\n",
+		},
+		{
+			name:        "source field",
+			markdown:    "---\nsource: https://example.com/other\n---\nBody",
+			expectedURL: "https://example.com/other",
+			expected:    "Body",
+		},
+		{
+			name:     "unterminated block is kept",
+			markdown: "---\nurl: https://example.com\nBody",
+			expected: "---\nurl: https://example.com\nBody",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, sourceURL := splitFrontMatter(tt.markdown)
+			if body != tt.expected {
+				t.Errorf("expected body %q, got %q", tt.expected, body)
+			}
+			if sourceURL != tt.expectedURL {
+				t.Errorf("expected URL %q, got %q", tt.expectedURL, sourceURL)
+			}
+		})
+	}
+}
+
+func TestSplitSections(t *testing.T) {
+	tests := []struct {
+		name     string
+		markdown string
+		expected []Section
+	}{
+		{
+			name:     "no headings",
+			markdown: "Just text.",
+			expected: []Section{{Headings: []string{}, Text: "Just text."}},
+		},
+		{
+			name:     "nested breadcrumbs",
+			markdown: "# Guide\n\nIntro.\n\n## Install\n\nSteps.\n\n### Linux\n\napt.\n\n## Usage\n\nRun it.",
+			expected: []Section{
+				{Headings: []string{"Guide"}, Text: "# Guide\n\nIntro."},
+				{Headings: []string{"Guide", "Install"}, Text: "## Install\n\nSteps."},
+				{Headings: []string{"Guide", "Install", "Linux"}, Text: "### Linux\n\napt."},
+				{Headings: []string{"Guide", "Usage"}, Text: "## Usage\n\nRun it."},
+			},
+		},
+		{
+			name:     "heading-only sections are dropped",
+			markdown: "# Guide\n\n## Install\n\nSteps.",
+			expected: []Section{
+				{Headings: []string{"Guide", "Install"}, Text: "## Install\n\nSteps."},
+			},
+		},
+		{
+			name:     "headings in code fences are ignored",
+			markdown: "# Shell\n\n```sh\n# not a heading\n```\n\nAfter.",
+			expected: []Section{
+				{Headings: []string{"Shell"}, Text: "# Shell\n\n```sh\n# not a heading\n```\n\nAfter."},
+			},
+		},
+		{
+			name:     "closing sequence is stripped",
+			markdown: "## API ##\n\nText.",
+			expected: []Section{{Headings: []string{"API"}, Text: "## API ##\n\nText."}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sections := splitSections(tt.markdown)
+			if !reflect.DeepEqual(sections, tt.expected) {
+				t.Errorf("expected %#v, got %#v", tt.expected, sections)
+			}
+		})
+	}
+}
+
+func TestSections(t *testing.T) {
+	markdown := "---\ntitle: Guide\n---\n# Guide\n\nIntro.\n\n## Install\n\n```sh\n# not a heading\n```\n"
+
+	expected := []Section{
+		{Headings: []string{"Guide"}, Text: "# Guide\n\nIntro."},
+		{Headings: []string{"Guide", "Install"}, Text: "## Install\n\n```sh\n# not a heading\n```"},
+	}
+	if got := Sections(markdown); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %+v, got %+v", expected, got)
+	}
+}
+
+func TestParseHeading(t *testing.T) {
+	tests := []struct {
+		line          string
+		expectedLevel int
+		expectedTitle string
+	}{
+		{line: "# Title", expectedLevel: 1, expectedTitle: "Title"},
+		{line: "### Deep  ", expectedLevel: 3, expectedTitle: "Deep"},
+		{line: "   ## Indented", expectedLevel: 2, expectedTitle: "Indented"},
+		{line: "    # Code block", expectedLevel: 0},
+		{line: "#hashtag", expectedLevel: 0},
+		{line: "####### Seven", expectedLevel: 0},
+		{line: "## C# ##", expectedLevel: 2, expectedTitle: "C#"},
+		{line: "Plain text", expectedLevel: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			level, title := parseHeading(tt.line)
+			if level != tt.expectedLevel || title != tt.expectedTitle {
+				t.Errorf("expected (%d, %q), got (%d, %q)", tt.expectedLevel, tt.expectedTitle, level, title)
+			}
+		})
+	}
+}
+
+func TestPack(t *testing.T) {
+	paragraph := strings.Repeat("word ", 20) // 100 chars
+	tests := []struct {
+		name    string
+		config  Config
+		text    string
+		minimum int // chunks expected at least
+	}{
+		{
+			name:    "fits in one chunk",
+			config:  Config{Unit: UnitChars, MaxSize: 1000},
+			text:    "Short text.",
+			minimum: 1,
+		},
+		{
+			name:    "paragraphs over the budget",
+			config:  Config{Unit: UnitChars, MaxSize: 250, Overlap: 30},
+			text:    strings.Repeat(paragraph+"\n\n", 6),
+			minimum: 3,
+		},
+		{
+			name:    "single long line",
+			config:  Config{Unit: UnitTokens, MaxSize: 20, Overlap: 5},
+			text:    strings.Repeat("token ", 200),
+			minimum: 10,
+		},
+		{
+			name:    "word longer than the budget",
+			config:  Config{Unit: UnitChars, MaxSize: 10},
+			text:    strings.Repeat("x", 35),
+			minimum: 4,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := NewService(filesystem.NewMemFileSystem(), NewMockLogger(), tt.config)
+			chunks := service.pack(strings.TrimSpace(tt.text))
+
+			if len(chunks) < tt.minimum {
+				t.Fatalf("expected at least %d chunks, got %d", tt.minimum, len(chunks))
+			}
+			for i, chunk := range chunks {
+				if size := service.size(chunk); size > tt.config.MaxSize {
+					t.Errorf("chunk %d has size %d over the budget of %d", i, size, tt.config.MaxSize)
+				}
+				if strings.TrimSpace(chunk) == "" {
+					t.Errorf("chunk %d is empty", i)
+				}
+			}
+		})
+	}
+}
+
+func TestPack_Overlap(t *testing.T) {
+	service := NewService(filesystem.NewMemFileSystem(), NewMockLogger(), Config{Unit: UnitChars, MaxSize: 60, Overlap: 15})
+	text := "First paragraph ends with alpha beta.\n\nSecond paragraph ends with gamma delta.\n\nThird one."
+
+	chunks := service.pack(text)
+	if len(chunks) < 2 {
+		t.Fatalf("expected several chunks, got %q", chunks)
+	}
+	if !strings.HasPrefix(chunks[1], "alpha beta.") {
+		t.Errorf("expected second chunk to start with the end of the first, got %q", chunks[1])
+	}
+
+	service = NewService(filesystem.NewMemFileSystem(), NewMockLogger(), Config{Unit: UnitChars, MaxSize: 60})
+	chunks = service.pack(text)
+	if !strings.HasPrefix(chunks[1], "Second paragraph") {
+		t.Errorf("expected no overlap without a budget for it, got %q", chunks[1])
+	}
+}
+
+func TestSplitBlocks_KeepsFences(t *testing.T) {
+	text := "Intro.\n\n```\nline one\n\nline two\n```\n\nOutro."
+
+	blocks := splitBlocks(text)
+	expected := []string{"Intro.", "```\nline one\n\nline two\n```", "Outro."}
+	if !reflect.DeepEqual(blocks, expected) {
+		t.Errorf("expected %q, got %q", expected, blocks)
+	}
+}
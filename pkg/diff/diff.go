@@ -0,0 +1,272 @@
+package diff
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"mdify/internal/docs"
+	"mdify/internal/filesystem"
+	"mdify/pkg/chunk"
+)
+
+// DefaultContext is how many unchanged lines surround each change by default
+const DefaultContext = 3
+
+// Statuses of a changed file
+const (
+	StatusAdded    = "added"
+	StatusRemoved  = "removed"
+	StatusModified = "modified"
+)
+
+// topSection names the text above a page's first heading
+const topSection = "(top of page)"
+
+// FileSystem interface for reading the docs being compared
+type FileSystem interface {
+	ReadFile(filename string) ([]byte, error)
+	ReadDir(name string) ([]os.DirEntry, error)
+	Stat(name string) (filesystem.FileInfo, error)
+}
+
+// Logger interface for leveled, structured logging; *slog.Logger satisfies it
+type Logger interface {
+	Debug(msg string, args ...interface{})
+	Info(msg string, args ...interface{})
+	Warn(msg string, args ...interface{})
+	Error(msg string, args ...interface{})
+}
+
+// Config holds configuration for comparing scrapes
+type Config struct {
+	Context int  // unchanged lines around each change in a diff, such as DefaultContext
+	NoDiff  bool // only summarize, without line diffs
+}
+
+// SectionChanges lists the sections of a page, by heading breadcrumb, that
+// were added, removed or modified
+type SectionChanges struct {
+	Added    []string `json:"added"`
+	Removed  []string `json:"removed"`
+	Modified []string `json:"modified"`
+}
+
+// FileChange describes one added, removed or modified page
+type FileChange struct {
+	Path         string         `json:"path"`
+	Status       string         `json:"status"`
+	LinesAdded   int            `json:"lines_added"`
+	LinesRemoved int            `json:"lines_removed"`
+	Sections     SectionChanges `json:"sections"`
+	Diff         string         `json:"diff,omitempty"`
+}
+
+// Summary counts the changes between two scrapes
+type Summary struct {
+	Added        int `json:"added"`
+	Removed      int `json:"removed"`
+	Modified     int `json:"modified"`
+	Unchanged    int `json:"unchanged"`
+	LinesAdded   int `json:"lines_added"`
+	LinesRemoved int `json:"lines_removed"`
+}
+
+// Report describes what changed from one scrape to the next
+type Report struct {
+	Old     string       `json:"old"`
+	New     string       `json:"new"`
+	Summary Summary      `json:"summary"`
+	Files   []FileChange `json:"files"` // changed files by path
+}
+
+// Changed reports whether any page was added, removed or modified
+func (r *Report) Changed() bool {
+	return len(r.Files) > 0
+}
+
+// Service compares two directories of converted docs
+type Service struct {
+	fs     FileSystem
+	logger Logger
+	config Config
+}
+
+// NewService creates a new diff service
+func NewService(fs FileSystem, logger Logger, config Config) *Service {
+	return &Service{
+		fs:     fs,
+		logger: logger,
+		config: config,
+	}
+}
+
+// Compare reports the pages added, removed and modified from oldDir to newDir
+func (s *Service) Compare(oldDir, newDir string) (*Report, error) {
+	oldAbs, oldFiles, err := s.list(oldDir)
+	if err != nil {
+		return nil, err
+	}
+	newAbs, newFiles, err := s.list(newDir)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Report{Old: oldAbs, New: newAbs, Files: []FileChange{}}
+
+	paths := make(map[string]bool)
+	for path := range oldFiles {
+		paths[path] = true
+	}
+	for path := range newFiles {
+		paths[path] = true
+	}
+	sorted := make([]string, 0, len(paths))
+	for path := range paths {
+		sorted = append(sorted, path)
+	}
+	sort.Strings(sorted)
+
+	for _, path := range sorted {
+		var oldContent, newContent string
+		_, inOld := oldFiles[path]
+		_, inNew := newFiles[path]
+		if inOld {
+			if oldContent, err = s.read(oldAbs, path); err != nil {
+				return nil, err
+			}
+		}
+		if inNew {
+			if newContent, err = s.read(newAbs, path); err != nil {
+				return nil, err
+			}
+		}
+
+		if inOld && inNew && oldContent == newContent {
+			report.Summary.Unchanged++
+			continue
+		}
+
+		change := s.compareFile(path, inOld, inNew, oldContent, newContent)
+		switch change.Status {
+		case StatusAdded:
+			report.Summary.Added++
+		case StatusRemoved:
+			report.Summary.Removed++
+		case StatusModified:
+			report.Summary.Modified++
+		}
+		report.Summary.LinesAdded += change.LinesAdded
+		report.Summary.LinesRemoved += change.LinesRemoved
+		report.Files = append(report.Files, change)
+		s.logger.Debug("Changed", "path", path, "status", change.Status, "added", change.LinesAdded, "removed", change.LinesRemoved)
+	}
+
+	return report, nil
+}
+
+// list returns dir's absolute path and the markdown files below it
+func (s *Service) list(dir string) (string, map[string]bool, error) {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to get absolute path for %s: %w", dir, err)
+	}
+	if info, err := s.fs.Stat(absDir); err != nil || !info.IsExist() {
+		return "", nil, fmt.Errorf("directory does not exist: %s", absDir)
+	}
+
+	documents, err := docs.List(s.fs, absDir)
+	if err != nil {
+		return "", nil, err
+	}
+	files := make(map[string]bool, len(documents))
+	for _, doc := range documents {
+		files[doc.File] = true
+	}
+	return absDir, files, nil
+}
+
+func (s *Service) read(dir, path string) (string, error) {
+	content, err := s.fs.ReadFile(filepath.Join(dir, filepath.FromSlash(path)))
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return string(content), nil
+}
+
+// compareFile describes how a page changed
+func (s *Service) compareFile(path string, inOld, inNew bool, oldContent, newContent string) FileChange {
+	change := FileChange{Path: path, Status: StatusModified}
+	oldName, newName := "a/"+path, "b/"+path
+	switch {
+	case !inOld:
+		change.Status = StatusAdded
+		oldName = "/dev/null"
+	case !inNew:
+		change.Status = StatusRemoved
+		newName = "/dev/null"
+	}
+
+	edits := diffLines(splitLines(oldContent), splitLines(newContent))
+	for _, e := range edits {
+		switch e.kind {
+		case opInsert:
+			change.LinesAdded++
+		case opDelete:
+			change.LinesRemoved++
+		}
+	}
+	if !s.config.NoDiff {
+		change.Diff = unified(oldName, newName, edits, s.config.Context)
+	}
+	change.Sections = compareSections(oldContent, newContent)
+	return change
+}
+
+// compareSections matches the sections of two versions of a page by their
+// heading breadcrumbs
+func compareSections(oldContent, newContent string) SectionChanges {
+	changes := SectionChanges{Added: []string{}, Removed: []string{}, Modified: []string{}}
+
+	oldNames, oldText := sectionsByName(oldContent)
+	newNames, newText := sectionsByName(newContent)
+
+	for _, name := range newNames {
+		text, ok := oldText[name]
+		switch {
+		case !ok:
+			changes.Added = append(changes.Added, name)
+		case text != newText[name]:
+			changes.Modified = append(changes.Modified, name)
+		}
+	}
+	for _, name := range oldNames {
+		if _, ok := newText[name]; !ok {
+			changes.Removed = append(changes.Removed, name)
+		}
+	}
+	return changes
+}
+
+// sectionsByName names each section of a page by its heading breadcrumb,
+// numbering repeated breadcrumbs, and returns the names in page order
+func sectionsByName(content string) ([]string, map[string]string) {
+	var names []string
+	texts := make(map[string]string)
+	seen := make(map[string]int)
+
+	for _, section := range chunk.Sections(content) {
+		name := strings.Join(section.Headings, " > ")
+		if name == "" {
+			name = topSection
+		}
+		if seen[name]++; seen[name] > 1 {
+			name = fmt.Sprintf("%s (%d)", name, seen[name])
+		}
+		names = append(names, name)
+		texts[name] = section.Text
+	}
+	return names, texts
+}
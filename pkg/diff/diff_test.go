@@ -0,0 +1,191 @@
+package diff
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"mdify/internal/filesystem"
+)
+
+func newScrapes() *filesystem.MemFileSystem {
+	fs := filesystem.NewMemFileSystem()
+	fs.WriteFile("/old/index.md", []byte("# Home\n\nWelcome to the docs.\n"))
+	fs.WriteFile("/old/legacy.md", []byte("# Legacy\n\nDeprecated.\n"))
+	fs.WriteFile("/old/guide/install.md", []byte("---\ntitle: Install\n---\nIntro.\n\n# Install\n\n## Linux\n\nUse apt.\n\n## Windows\n\nUse the installer.\n\n## Notes\n\nNone.\n\n## Notes\n\nFirst.\n"))
+
+	fs.WriteFile("/new/index.md", []byte("# Home\n\nWelcome to the docs.\n"))
+	fs.WriteFile("/new/changelog.md", []byte("# Changelog\n\n- First release\n"))
+	fs.WriteFile("/new/guide/install.md", []byte("---\ntitle: Install\n---\nIntro.\n\n# Install\n\n## Linux\n\nUse apt or dnf.\n\n## macOS\n\nUse brew.\n\n## Notes\n\nNone.\n\n## Notes\n\nSecond.\n"))
+	return fs
+}
+
+func TestService_Compare(t *testing.T) {
+	logger := NewMockLogger()
+	service := NewService(newScrapes(), logger, Config{Context: DefaultContext})
+
+	report, err := service.Compare("/old", "/new")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := Summary{Added: 1, Removed: 1, Modified: 1, Unchanged: 1, LinesAdded: 7, LinesRemoved: 7}
+	if report.Summary != expected {
+		t.Errorf("expected summary %+v, got %+v", expected, report.Summary)
+	}
+	if !report.Changed() {
+		t.Error("expected the report to show changes")
+	}
+
+	var files []string
+	for _, file := range report.Files {
+		files = append(files, file.Status+" "+file.Path)
+	}
+	if !reflect.DeepEqual(files, []string{"added changelog.md", "modified guide/install.md", "removed legacy.md"}) {
+		t.Errorf("unexpected files %v", files)
+	}
+
+	added := report.Files[0]
+	if added.LinesAdded != 3 || added.LinesRemoved != 0 || !strings.HasPrefix(added.Diff, "--- /dev/null\n+++ b/changelog.md\n") {
+		t.Errorf("unexpected added file %+v", added)
+	}
+	removed := report.Files[2]
+	if removed.LinesAdded != 0 || removed.LinesRemoved != 3 || !strings.HasPrefix(removed.Diff, "--- a/legacy.md\n+++ /dev/null\n") {
+		t.Errorf("unexpected removed file %+v", removed)
+	}
+
+	sections := SectionChanges{
+		Added:    []string{"Install > macOS"},
+		Removed:  []string{"Install > Windows"},
+		Modified: []string{"Install > Linux", "Install > Notes (2)"},
+	}
+	if modified := report.Files[1]; !reflect.DeepEqual(modified.Sections, sections) {
+		t.Errorf("expected sections %+v, got %+v", sections, modified.Sections)
+	}
+	if !strings.Contains(report.Files[1].Diff, "-Use apt.\n+Use apt or dnf.\n") {
+		t.Errorf("expected the changed line in the diff, got:\n%s", report.Files[1].Diff)
+	}
+
+	messages := logger.GetMessages()
+	if len(messages) != 3 || messages[0] != "DEBUG Changed path=changelog.md status=added added=3 removed=0" {
+		t.Errorf("expected a debug log per changed file, got %v", messages)
+	}
+}
+
+func TestService_Compare_Unchanged(t *testing.T) {
+	fs := filesystem.NewMemFileSystem()
+	fs.WriteFile("/old/index.md", []byte("# Home\n"))
+	fs.WriteFile("/new/index.md", []byte("# Home\n"))
+
+	report, err := NewService(fs, NewMockLogger(), Config{}).Compare("/old", "/new")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if report.Changed() || report.Summary.Unchanged != 1 {
+		t.Errorf("expected no changes, got %+v", report.Summary)
+	}
+}
+
+func TestService_Compare_NoDiff(t *testing.T) {
+	service := NewService(newScrapes(), NewMockLogger(), Config{NoDiff: true})
+
+	report, err := service.Compare("/old", "/new")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, file := range report.Files {
+		if file.Diff != "" {
+			t.Errorf("expected no diff for %s", file.Path)
+		}
+	}
+	if report.Summary.LinesAdded != 7 {
+		t.Errorf("expected lines to be counted without diffs, got %+v", report.Summary)
+	}
+}
+
+func TestService_Compare_MissingDir(t *testing.T) {
+	service := NewService(newScrapes(), NewMockLogger(), Config{})
+	if _, err := service.Compare("/old", "/missing"); err == nil {
+		t.Error("expected error for a missing directory")
+	}
+}
+
+func TestReport_Write(t *testing.T) {
+	service := NewService(newScrapes(), NewMockLogger(), Config{Context: DefaultContext})
+	report, err := service.Compare("/old", "/new")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		format      string
+		contains    []string
+		expectError bool
+	}{
+		{
+			name:   "text",
+			format: FormatText,
+			contains: []string{
+				"1 added, 1 removed, 1 modified, 1 unchanged (+7 -7 lines)\n\n",
+				"A changelog.md (+3 -0)\n",
+				"M guide/install.md (+4 -4)\n    + Install > macOS\n    - Install > Windows\n    ~ Install > Linux\n",
+				"D legacy.md (+0 -3)\n",
+				"\n--- a/guide/install.md\n+++ b/guide/install.md\n@@ ",
+			},
+		},
+		{
+			name:     "json",
+			format:   FormatJSON,
+			contains: []string{`"status": "added"`, `"lines_added": 7`, `"Install > Notes (2)"`},
+		},
+		{
+			name:        "unknown format",
+			format:      "yaml",
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			err := report.Write(&buf, tt.format)
+			if tt.expectError {
+				if err == nil {
+					t.Error("expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			for _, want := range tt.contains {
+				if !strings.Contains(buf.String(), want) {
+					t.Errorf("expected output to contain %q, got:\n%s", want, buf.String())
+				}
+			}
+		})
+	}
+}
+
+func TestReport_WriteJSON_RoundTrip(t *testing.T) {
+	service := NewService(newScrapes(), NewMockLogger(), Config{})
+	report, err := service.Compare("/old", "/new")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := report.WriteJSON(&buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var decoded Report
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("expected valid JSON: %v", err)
+	}
+	if !reflect.DeepEqual(&decoded, report) {
+		t.Errorf("expected %+v, got %+v", report, decoded)
+	}
+}
@@ -0,0 +1,83 @@
+package diff
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Output formats for a report
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// Write renders the report in the given format
+func (r *Report) Write(w io.Writer, format string) error {
+	switch format {
+	case FormatText:
+		return r.WriteText(w)
+	case FormatJSON:
+		return r.WriteJSON(w)
+	default:
+		return fmt.Errorf("invalid format %q (use text or json)", format)
+	}
+}
+
+// WriteJSON writes the report as an indented JSON object
+func (r *Report) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+	return encoder.Encode(r)
+}
+
+// WriteText writes a summary line, the changed files with their changed
+// sections, and then the unified diffs
+func (r *Report) WriteText(w io.Writer) error {
+	var b strings.Builder
+	s := r.Summary
+	fmt.Fprintf(&b, "%d added, %d removed, %d modified, %d unchanged (+%d -%d lines)\n",
+		s.Added, s.Removed, s.Modified, s.Unchanged, s.LinesAdded, s.LinesRemoved)
+
+	if len(r.Files) > 0 {
+		b.WriteString("\n")
+	}
+	for _, file := range r.Files {
+		fmt.Fprintf(&b, "%s %s (+%d -%d)\n", statusLetter(file.Status), file.Path, file.LinesAdded, file.LinesRemoved)
+		if file.Status != StatusModified {
+			continue
+		}
+		for _, name := range file.Sections.Added {
+			fmt.Fprintf(&b, "    + %s\n", name)
+		}
+		for _, name := range file.Sections.Removed {
+			fmt.Fprintf(&b, "    - %s\n", name)
+		}
+		for _, name := range file.Sections.Modified {
+			fmt.Fprintf(&b, "    ~ %s\n", name)
+		}
+	}
+
+	for _, file := range r.Files {
+		if file.Diff != "" {
+			b.WriteString("\n")
+			b.WriteString(file.Diff)
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func statusLetter(status string) string {
+	switch status {
+	case StatusAdded:
+		return "A"
+	case StatusRemoved:
+		return "D"
+	default:
+		return "M"
+	}
+}
@@ -0,0 +1,145 @@
+package diff
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDiffLines(t *testing.T) {
+	tests := []struct {
+		name     string
+		old      string
+		new      string
+		expected string // one letter per edit: = equal, - delete, + insert
+	}{
+		{name: "identical", old: "a\nb\n", new: "a\nb\n", expected: "=="},
+		{name: "both empty", old: "", new: "", expected: ""},
+		{name: "added file", old: "", new: "a\nb\n", expected: "++"},
+		{name: "removed file", old: "a\nb\n", new: "", expected: "--"},
+		{name: "changed line", old: "a\nb\nc\n", new: "a\nx\nc\n", expected: "=-+="},
+		{name: "inserted line", old: "a\nc\n", new: "a\nb\nc\n", expected: "=+="},
+		{name: "missing final newline", old: "a\nb\n", new: "a\nb", expected: "=-+"},
+		{name: "moved line", old: "a\nb\nc\nd\n", new: "b\nc\nd\na\n", expected: "-===+"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			edits := diffLines(splitLines(tt.old), splitLines(tt.new))
+
+			var kinds, oldText, newText strings.Builder
+			for _, e := range edits {
+				switch e.kind {
+				case opEqual:
+					kinds.WriteString("=")
+					oldText.WriteString(e.line)
+					newText.WriteString(e.line)
+				case opDelete:
+					kinds.WriteString("-")
+					oldText.WriteString(e.line)
+				case opInsert:
+					kinds.WriteString("+")
+					newText.WriteString(e.line)
+				}
+			}
+			if kinds.String() != tt.expected {
+				t.Errorf("expected edits %q, got %q", tt.expected, kinds.String())
+			}
+			if oldText.String() != tt.old || newText.String() != tt.new {
+				t.Errorf("expected the edits to reproduce both files, got %q and %q", oldText.String(), newText.String())
+			}
+		})
+	}
+}
+
+func TestDiffLines_MaxEditDistance(t *testing.T) {
+	var a, b []string
+	for i := 0; i < maxEditDistance; i++ {
+		a = append(a, "old\n", "same\n")
+		b = append(b, "new\n", "same\n")
+	}
+
+	edits := diffLines(a, b)
+	if len(edits) != len(a)+len(b)-1 {
+		t.Fatalf("expected the middle to be replaced in one piece, got %d edits", len(edits))
+	}
+	if edits[0].kind != opDelete || edits[len(edits)-1].kind != opEqual {
+		t.Errorf("expected deletions first and the common suffix kept, got %v and %v", edits[0], edits[len(edits)-1])
+	}
+}
+
+func TestUnified(t *testing.T) {
+	lines := func(from, to int) string {
+		var b strings.Builder
+		for i := from; i <= to; i++ {
+			b.WriteString(string(rune('a'+i-1)) + "\n")
+		}
+		return b.String()
+	}
+
+	tests := []struct {
+		name     string
+		old      string
+		new      string
+		context  int
+		expected string
+	}{
+		{
+			name:     "unchanged",
+			old:      "a\n",
+			new:      "a\n",
+			context:  3,
+			expected: "",
+		},
+		{
+			name:     "changed line",
+			old:      lines(1, 10),
+			new:      strings.Replace(lines(1, 10), "e\n", "E\n", 1),
+			context:  3,
+			expected: "--- a/page.md\n+++ b/page.md\n@@ -2,7 +2,7 @@\n b\n c\n d\n-e\n+E\n f\n g\n h\n",
+		},
+		{
+			name:     "nearby changes share a hunk",
+			old:      lines(1, 10),
+			new:      strings.NewReplacer("b\n", "B\n", "i\n", "I\n").Replace(lines(1, 10)),
+			context:  3,
+			expected: "--- a/page.md\n+++ b/page.md\n@@ -1,10 +1,10 @@\n a\n-b\n+B\n c\n d\n e\n f\n g\n h\n-i\n+I\n j\n",
+		},
+		{
+			name:     "distant changes get their own hunks",
+			old:      lines(1, 10),
+			new:      strings.NewReplacer("b\n", "B\n", "i\n", "I\n").Replace(lines(1, 10)),
+			context:  1,
+			expected: "--- a/page.md\n+++ b/page.md\n@@ -1,3 +1,3 @@\n a\n-b\n+B\n c\n@@ -8,3 +8,3 @@\n h\n-i\n+I\n j\n",
+		},
+		{
+			name:     "insertion without context",
+			old:      "a\nc\n",
+			new:      "a\nb\nc\n",
+			context:  0,
+			expected: "--- a/page.md\n+++ b/page.md\n@@ -1,0 +2 @@\n+b\n",
+		},
+		{
+			name:     "missing final newline",
+			old:      "a\nb\n",
+			new:      "a\nb",
+			context:  3,
+			expected: "--- a/page.md\n+++ b/page.md\n@@ -1,2 +1,2 @@\n a\n-b\n+b\n\\ No newline at end of file\n",
+		},
+		{
+			name:     "added file",
+			old:      "",
+			new:      "# New\n",
+			context:  3,
+			expected: "--- a/page.md\n+++ b/page.md\n@@ -0,0 +1 @@\n+# New\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			edits := diffLines(splitLines(tt.old), splitLines(tt.new))
+			if result := unified("a/page.md", "b/page.md", edits, tt.context); result != tt.expected {
+				t.Errorf("expected:\n%s\ngot:\n%s", tt.expected, result)
+			}
+		})
+	}
+}
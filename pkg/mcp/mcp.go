@@ -0,0 +1,231 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"mdify/internal/filesystem"
+	"mdify/pkg/server"
+)
+
+// LatestProtocolVersion is the newest MCP revision this server speaks
+const LatestProtocolVersion = "2025-06-18"
+
+// supportedProtocolVersions are the MCP revisions a client may negotiate
+var supportedProtocolVersions = map[string]bool{
+	"2025-06-18": true,
+	"2025-03-26": true,
+	"2024-11-05": true,
+}
+
+// JSON-RPC error codes
+const (
+	codeParseError     = -32700
+	codeInvalidRequest = -32600
+	codeMethodNotFound = -32601
+	codeInvalidParams  = -32602
+	codeInternalError  = -32603
+)
+
+// FileSystem interface for reading the docs corpus
+type FileSystem interface {
+	ReadFile(filename string) ([]byte, error)
+	ReadDir(name string) ([]os.DirEntry, error)
+	Stat(name string) (filesystem.FileInfo, error)
+}
+
+// Logger interface for leveled, structured logging; *slog.Logger satisfies it
+type Logger interface {
+	Debug(msg string, args ...interface{})
+	Info(msg string, args ...interface{})
+	Warn(msg string, args ...interface{})
+	Error(msg string, args ...interface{})
+}
+
+// Service exposes a directory of markdown files over the Model Context Protocol
+type Service struct {
+	fs      FileSystem
+	logger  Logger
+	version string
+
+	baseDir string
+	index   *server.SearchIndex
+}
+
+// Request is a JSON-RPC request or notification
+type Request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// Response is a JSON-RPC response
+type Response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  interface{}     `json:"result,omitempty"`
+	Error   *Error          `json:"error,omitempty"`
+}
+
+// Error is a JSON-RPC error object
+type Error struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// NewService creates a new MCP service
+func NewService(fs FileSystem, logger Logger, version string) *Service {
+	return &Service{
+		fs:      fs,
+		logger:  logger,
+		version: version,
+	}
+}
+
+// Open points the service at a directory of markdown files and indexes it
+func (s *Service) Open(dir string) error {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return fmt.Errorf("failed to get absolute path for %s: %w", dir, err)
+	}
+
+	if info, err := s.fs.Stat(absDir); err != nil || !info.IsExist() {
+		return fmt.Errorf("directory does not exist: %s", absDir)
+	}
+
+	s.baseDir = absDir
+	s.index = server.NewSearchIndex(s.fs, absDir, s.logger)
+	if err := s.index.Build(); err != nil {
+		return fmt.Errorf("failed to build search index: %w", err)
+	}
+
+	s.logger.Info("Indexed documents", "count", s.index.Len(), "dir", absDir)
+	return nil
+}
+
+// Handle processes one JSON-RPC message and returns the encoded response,
+// or nil when the message was a notification
+func (s *Service) Handle(message []byte) []byte {
+	var req Request
+	if err := json.Unmarshal(message, &req); err != nil {
+		return encodeResponse(Response{
+			JSONRPC: "2.0",
+			ID:      json.RawMessage("null"),
+			Error:   &Error{Code: codeParseError, Message: "parse error: " + err.Error()},
+		})
+	}
+
+	if req.JSONRPC != "2.0" || req.Method == "" {
+		if len(req.ID) == 0 {
+			return nil
+		}
+		return encodeResponse(Response{
+			JSONRPC: "2.0",
+			ID:      req.ID,
+			Error:   &Error{Code: codeInvalidRequest, Message: "invalid request"},
+		})
+	}
+
+	result, err := s.dispatch(req)
+
+	// notifications never get a response, even when they fail
+	if len(req.ID) == 0 {
+		return nil
+	}
+
+	resp := Response{JSONRPC: "2.0", ID: req.ID, Result: result}
+	if err != nil {
+		rpcErr, ok := err.(*Error)
+		if !ok {
+			rpcErr = &Error{Code: codeInternalError, Message: err.Error()}
+		}
+		resp.Result = nil
+		resp.Error = rpcErr
+	}
+	return encodeResponse(resp)
+}
+
+func (s *Service) dispatch(req Request) (result interface{}, err error) {
+	// a bug in one handler must not end a stdio session
+	defer func() {
+		if r := recover(); r != nil {
+			s.logger.Error("Request failed", "method", req.Method, "panic", r)
+			result, err = nil, &Error{Code: codeInternalError, Message: "internal error"}
+		}
+	}()
+
+	switch req.Method {
+	case "initialize":
+		return s.initialize(req.Params)
+	case "ping":
+		return struct{}{}, nil
+	case "tools/list":
+		return map[string]interface{}{"tools": toolDefinitions}, nil
+	case "tools/call":
+		return s.callTool(req.Params)
+	case "resources/list":
+		return s.listResources()
+	case "resources/read":
+		return s.readResource(req.Params)
+	case "notifications/initialized", "notifications/cancelled":
+		return nil, nil
+	}
+
+	return nil, &Error{Code: codeMethodNotFound, Message: "method not found: " + req.Method}
+}
+
+func (s *Service) initialize(params json.RawMessage) (interface{}, error) {
+	var p struct {
+		ProtocolVersion string `json:"protocolVersion"`
+	}
+	if err := decodeParams(params, &p); err != nil {
+		return nil, err
+	}
+
+	version := LatestProtocolVersion
+	if supportedProtocolVersions[p.ProtocolVersion] {
+		version = p.ProtocolVersion
+	}
+
+	return map[string]interface{}{
+		"protocolVersion": version,
+		"capabilities": map[string]interface{}{
+			"tools":     map[string]interface{}{},
+			"resources": map[string]interface{}{},
+		},
+		"serverInfo": map[string]string{
+			"name":    "mdify",
+			"version": s.version,
+		},
+		"instructions": "Documentation converted to markdown. Use search_docs to find pages, list_docs to browse, and read_doc to fetch a page.",
+	}, nil
+}
+
+func decodeParams(params json.RawMessage, v interface{}) error {
+	if len(params) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(params, v); err != nil {
+		return &Error{Code: codeInvalidParams, Message: "invalid params: " + err.Error()}
+	}
+	return nil
+}
+
+func encodeResponse(resp Response) []byte {
+	data, err := json.Marshal(resp)
+	if err != nil {
+		data, _ = json.Marshal(Response{
+			JSONRPC: "2.0",
+			ID:      resp.ID,
+			Error:   &Error{Code: codeInternalError, Message: err.Error()},
+		})
+	}
+	return data
+}
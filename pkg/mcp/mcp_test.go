@@ -0,0 +1,419 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"mdify/internal/filesystem"
+)
+
+func newTestService(t *testing.T) *Service {
+	t.Helper()
+
+	fs := filesystem.NewMemFileSystem()
+	fs.WriteFile("/base/index.md", []byte("# Home\n\nWelcome to the docs."))
+	fs.WriteFile("/base/docs/routing.md", []byte("# Routing\n\nThe router matches paths in order."))
+	fs.WriteFile("/base/docs/auth.md", []byte("---\ntitle: Authentication\n---\n\nUse a bearer token."))
+	fs.WriteFile("/base/docs/guides/index.md", []byte("# Guides\n\nStep by step guides."))
+	fs.WriteFile("/base/.private/notes.md", []byte("# Private"))
+	fs.WriteFile("/base/docs/.draft.md", []byte("# Draft"))
+	fs.WriteFile("/secret.md", []byte("# Secret"))
+
+	service := NewService(fs, NewMockLogger(), "1.2.3")
+	if err := service.Open("/base"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return service
+}
+
+// call sends a request and decodes the response into result
+func call(t *testing.T, service *Service, method string, params interface{}, result interface{}) *Error {
+	t.Helper()
+
+	raw, err := json.Marshal(params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	request, _ := json.Marshal(Request{JSONRPC: "2.0", ID: json.RawMessage("1"), Method: method, Params: raw})
+
+	var response struct {
+		ID     json.RawMessage `json:"id"`
+		Result json.RawMessage `json:"result"`
+		Error  *Error          `json:"error"`
+	}
+	if err := json.Unmarshal(service.Handle(request), &response); err != nil {
+		t.Fatalf("invalid response: %v", err)
+	}
+	if string(response.ID) != "1" {
+		t.Errorf("expected id 1, got %s", response.ID)
+	}
+	if response.Error != nil {
+		return response.Error
+	}
+	if result != nil {
+		if err := json.Unmarshal(response.Result, result); err != nil {
+			t.Fatalf("invalid result: %v", err)
+		}
+	}
+	return nil
+}
+
+type toolResponse struct {
+	Content []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	} `json:"content"`
+	IsError bool `json:"isError"`
+}
+
+func callTool(t *testing.T, service *Service, name string, arguments map[string]interface{}) toolResponse {
+	t.Helper()
+
+	var result toolResponse
+	if rpcErr := call(t, service, "tools/call", map[string]interface{}{"name": name, "arguments": arguments}, &result); rpcErr != nil {
+		t.Fatalf("unexpected error: %v", rpcErr)
+	}
+	if len(result.Content) != 1 || result.Content[0].Type != "text" {
+		t.Fatalf("expected a single text block, got %+v", result.Content)
+	}
+	return result
+}
+
+func TestService_Open(t *testing.T) {
+	service := NewService(filesystem.NewMemFileSystem(), NewMockLogger(), "1.2.3")
+	if err := service.Open("/missing"); err == nil || !strings.Contains(err.Error(), "directory does not exist") {
+		t.Errorf("expected missing directory error, got %v", err)
+	}
+}
+
+func TestService_Initialize(t *testing.T) {
+	tests := []struct {
+		name      string
+		requested string
+		expected  string
+	}{
+		{name: "supported version", requested: "2024-11-05", expected: "2024-11-05"},
+		{name: "unknown version", requested: "1999-01-01", expected: LatestProtocolVersion},
+	}
+
+	service := newTestService(t)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var result struct {
+				ProtocolVersion string                     `json:"protocolVersion"`
+				Capabilities    map[string]json.RawMessage `json:"capabilities"`
+				ServerInfo      map[string]string          `json:"serverInfo"`
+			}
+			if rpcErr := call(t, service, "initialize", map[string]string{"protocolVersion": tt.requested}, &result); rpcErr != nil {
+				t.Fatalf("unexpected error: %v", rpcErr)
+			}
+
+			if result.ProtocolVersion != tt.expected {
+				t.Errorf("expected protocol version %s, got %s", tt.expected, result.ProtocolVersion)
+			}
+			if _, ok := result.Capabilities["tools"]; !ok {
+				t.Error("expected tools capability")
+			}
+			if _, ok := result.Capabilities["resources"]; !ok {
+				t.Error("expected resources capability")
+			}
+			if result.ServerInfo["name"] != "mdify" || result.ServerInfo["version"] != "1.2.3" {
+				t.Errorf("unexpected server info: %v", result.ServerInfo)
+			}
+		})
+	}
+}
+
+func TestService_Handle(t *testing.T) {
+	service := newTestService(t)
+
+	tests := []struct {
+		name         string
+		message      string
+		expectedCode int
+		noResponse   bool
+	}{
+		{name: "ping", message: `{"jsonrpc":"2.0","id":1,"method":"ping"}`},
+		{name: "notification", message: `{"jsonrpc":"2.0","method":"notifications/initialized"}`, noResponse: true},
+		{name: "unknown method", message: `{"jsonrpc":"2.0","id":1,"method":"prompts/list"}`, expectedCode: codeMethodNotFound},
+		{name: "parse error", message: `{"jsonrpc":`, expectedCode: codeParseError},
+		{name: "wrong version", message: `{"jsonrpc":"1.0","id":1,"method":"ping"}`, expectedCode: codeInvalidRequest},
+		{name: "bad params", message: `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":[]}`, expectedCode: codeInvalidParams},
+		{name: "unknown tool", message: `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"delete_docs"}}`, expectedCode: codeInvalidParams},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := service.Handle([]byte(tt.message))
+			if tt.noResponse {
+				if response != nil {
+					t.Errorf("expected no response, got %s", response)
+				}
+				return
+			}
+
+			var decoded Response
+			if err := json.Unmarshal(response, &decoded); err != nil {
+				t.Fatalf("invalid response %s: %v", response, err)
+			}
+			if tt.expectedCode == 0 {
+				if decoded.Error != nil {
+					t.Errorf("unexpected error: %v", decoded.Error)
+				}
+				return
+			}
+			if decoded.Error == nil || decoded.Error.Code != tt.expectedCode {
+				t.Errorf("expected error code %d, got %s", tt.expectedCode, response)
+			}
+		})
+	}
+}
+
+func TestService_ToolsList(t *testing.T) {
+	var result struct {
+		Tools []Tool `json:"tools"`
+	}
+	if rpcErr := call(t, newTestService(t), "tools/list", nil, &result); rpcErr != nil {
+		t.Fatalf("unexpected error: %v", rpcErr)
+	}
+
+	var names []string
+	for _, tool := range result.Tools {
+		names = append(names, tool.Name)
+	}
+	if strings.Join(names, ",") != "search_docs,read_doc,list_docs" {
+		t.Errorf("unexpected tools: %v", names)
+	}
+}
+
+func TestService_SearchDocs(t *testing.T) {
+	service := newTestService(t)
+
+	result := callTool(t, service, "search_docs", map[string]interface{}{"query": "router"})
+	if result.IsError {
+		t.Fatalf("unexpected tool error: %s", result.Content[0].Text)
+	}
+	var hits []struct {
+		Path  string `json:"path"`
+		Title string `json:"title"`
+	}
+	if err := json.Unmarshal([]byte(result.Content[0].Text), &hits); err != nil {
+		t.Fatalf("expected JSON results, got %q", result.Content[0].Text)
+	}
+	if len(hits) != 1 || hits[0].Path != "/docs/routing" || hits[0].Title != "Routing" {
+		t.Errorf("unexpected results: %+v", hits)
+	}
+
+	if result := callTool(t, service, "search_docs", map[string]interface{}{"query": "nonexistent"}); !strings.Contains(result.Content[0].Text, "No documents match") {
+		t.Errorf("expected no matches message, got %q", result.Content[0].Text)
+	}
+	if result := callTool(t, service, "search_docs", map[string]interface{}{"query": " "}); !result.IsError {
+		t.Error("expected error for empty query")
+	}
+}
+
+func TestService_ReadDoc(t *testing.T) {
+	tests := []struct {
+		name     string
+		path     string
+		expected string
+		isError  bool
+	}{
+		{name: "url path", path: "/docs/routing", expected: "# Routing"},
+		{name: "file name", path: "docs/routing.md", expected: "# Routing"},
+		{name: "root index", path: "/", expected: "# Home"},
+		{name: "directory index", path: "/docs/guides/", expected: "# Guides"},
+		{name: "missing", path: "/docs/missing", expected: "document not found", isError: true},
+		{name: "traversal", path: "../secret", expected: "invalid path", isError: true},
+		{name: "hidden directory", path: "/.private/notes", expected: "document not found", isError: true},
+		{name: "dotfile", path: "docs/.draft.md", expected: "document not found", isError: true},
+		{name: "empty", path: "", expected: "path is required", isError: true},
+	}
+
+	service := newTestService(t)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := callTool(t, service, "read_doc", map[string]interface{}{"path": tt.path})
+			if result.IsError != tt.isError {
+				t.Errorf("expected isError %v, got %v", tt.isError, result.IsError)
+			}
+			if !strings.Contains(result.Content[0].Text, tt.expected) {
+				t.Errorf("expected %q in %q", tt.expected, result.Content[0].Text)
+			}
+		})
+	}
+}
+
+func TestService_ListDocs(t *testing.T) {
+	service := newTestService(t)
+
+	result := callTool(t, service, "list_docs", nil)
+	expected := "/docs/auth\tAuthentication\n/docs/guides/\tGuides\n/docs/routing\tRouting\n/\tHome\n"
+	if result.Content[0].Text != expected {
+		t.Errorf("expected %q, got %q", expected, result.Content[0].Text)
+	}
+
+	result = callTool(t, service, "list_docs", map[string]interface{}{"prefix": "/docs/guides"})
+	if result.Content[0].Text != "/docs/guides/\tGuides\n" {
+		t.Errorf("expected prefix filter to apply, got %q", result.Content[0].Text)
+	}
+}
+
+func TestService_Resources(t *testing.T) {
+	service := newTestService(t)
+
+	var list struct {
+		Resources []map[string]string `json:"resources"`
+	}
+	if rpcErr := call(t, service, "resources/list", nil, &list); rpcErr != nil {
+		t.Fatalf("unexpected error: %v", rpcErr)
+	}
+	if len(list.Resources) != 4 {
+		t.Fatalf("expected 4 resources, got %d", len(list.Resources))
+	}
+	first := list.Resources[0]
+	if first["uri"] != "mdify:///docs/auth.md" || first["title"] != "Authentication" || first["mimeType"] != "text/markdown" {
+		t.Errorf("unexpected resource: %v", first)
+	}
+
+	var read struct {
+		Contents []map[string]string `json:"contents"`
+	}
+	if rpcErr := call(t, service, "resources/read", map[string]string{"uri": "mdify:///docs/guides/index.md"}, &read); rpcErr != nil {
+		t.Fatalf("unexpected error: %v", rpcErr)
+	}
+	if len(read.Contents) != 1 || !strings.HasPrefix(read.Contents[0]["text"], "# Guides") {
+		t.Errorf("unexpected contents: %v", read.Contents)
+	}
+
+	for _, uri := range []string{"https://example.com/x.md", "mdify:///../secret.md", "mdify:///missing.md", "mdify:///.private/notes.md"} {
+		if rpcErr := call(t, service, "resources/read", map[string]string{"uri": uri}, nil); rpcErr == nil || rpcErr.Code != codeInvalidParams {
+			t.Errorf("expected invalid params for %s, got %v", uri, rpcErr)
+		}
+	}
+}
+
+func TestService_ServeStdio(t *testing.T) {
+	service := newTestService(t)
+
+	in := strings.NewReader(strings.Join([]string{
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-06-18"}}`,
+		`{"jsonrpc":"2.0","method":"notifications/initialized"}`,
+		``,
+		`{"jsonrpc":"2.0","id":2,"method":"ping"}`,
+	}, "\n"))
+	var out bytes.Buffer
+
+	if err := service.ServeStdio(in, &out); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 responses, got %d: %q", len(lines), out.String())
+	}
+	if !strings.Contains(lines[0], `"id":1`) || !strings.Contains(lines[1], `"id":2`) {
+		t.Errorf("unexpected responses: %q", lines)
+	}
+}
+
+func TestService_ServeHTTP(t *testing.T) {
+	tests := []struct {
+		name           string
+		method         string
+		body           string
+		origin         string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{name: "request", method: http.MethodPost, body: `{"jsonrpc":"2.0","id":7,"method":"ping"}`, expectedStatus: http.StatusOK, expectedBody: `"id":7`},
+		{name: "notification", method: http.MethodPost, body: `{"jsonrpc":"2.0","method":"notifications/initialized"}`, expectedStatus: http.StatusAccepted},
+		{name: "get", method: http.MethodGet, expectedStatus: http.StatusMethodNotAllowed},
+		{name: "same origin", method: http.MethodPost, body: `{"jsonrpc":"2.0","id":1,"method":"ping"}`, origin: "http://example.com", expectedStatus: http.StatusOK},
+		{name: "foreign origin", method: http.MethodPost, body: `{"jsonrpc":"2.0","id":1,"method":"ping"}`, origin: "http://evil.test", expectedStatus: http.StatusForbidden},
+	}
+
+	service := newTestService(t)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/mcp", strings.NewReader(tt.body))
+			if tt.origin != "" {
+				req.Header.Set("Origin", tt.origin)
+			}
+			w := httptest.NewRecorder()
+
+			service.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+			if !strings.Contains(w.Body.String(), tt.expectedBody) {
+				t.Errorf("expected body to contain %q, got %q", tt.expectedBody, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestService_ListenAndServe(t *testing.T) {
+	socketPath := filepath.Join(t.TempDir(), "mcp.sock")
+	service := newTestService(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- service.ListenAndServe(ctx, "unix:"+socketPath)
+	}()
+
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "unix", socketPath)
+		},
+	}}
+	var resp *http.Response
+	var err error
+	for i := 0; i < 50; i++ {
+		if resp, err = client.Post("http://mdify/mcp", "application/json", strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"ping"}`)); err == nil {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected status 200, got %d", resp.StatusCode)
+	}
+
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("expected a clean shutdown, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the server to stop when the context is cancelled")
+	}
+}
+
+func TestService_Handle_Panic(t *testing.T) {
+	logger := NewMockLogger()
+	// never opened, so there is no index to search
+	service := NewService(filesystem.NewMemFileSystem(), logger, "test")
+
+	response := service.Handle([]byte(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"search_docs","arguments":{"query":"router"}}}`))
+	if !strings.Contains(string(response), `"code":-32603`) {
+		t.Errorf("expected an internal error response, got %s", response)
+	}
+	if messages := logger.GetMessages(); len(messages) == 0 || !strings.HasPrefix(messages[len(messages)-1], "ERROR Request failed method=tools/call") {
+		t.Errorf("expected the panic to be logged, got %v", messages)
+	}
+}
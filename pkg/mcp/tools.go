@@ -0,0 +1,254 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+
+	"mdify/internal/docs"
+)
+
+// resourceScheme prefixes the URIs of documents exposed as resources
+const resourceScheme = "mdify:///"
+
+const defaultSearchLimit = 10
+
+// Tool describes a tool in the tools/list response
+type Tool struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description"`
+	InputSchema map[string]interface{} `json:"inputSchema"`
+}
+
+var toolDefinitions = []Tool{
+	{
+		Name:        "search_docs",
+		Description: "Full-text search over the documentation. Returns matching pages ranked by relevance with highlighted snippets.",
+		InputSchema: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"query": map[string]interface{}{"type": "string", "description": "Search terms"},
+				"limit": map[string]interface{}{"type": "integer", "description": "Maximum number of results (default 10)"},
+			},
+			"required": []string{"query"},
+		},
+	},
+	{
+		Name:        "read_doc",
+		Description: "Read the markdown content of a documentation page by its path, e.g. /docs/getting-started.",
+		InputSchema: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"path": map[string]interface{}{"type": "string", "description": "Page path as returned by search_docs or list_docs"},
+			},
+			"required": []string{"path"},
+		},
+	},
+	{
+		Name:        "list_docs",
+		Description: "List documentation pages with their titles, optionally limited to a path prefix such as /docs/api/.",
+		InputSchema: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"prefix": map[string]interface{}{"type": "string", "description": "Only list pages whose path starts with this prefix"},
+			},
+		},
+	},
+}
+
+// toolResult builds a tools/call result with a single text block
+func toolResult(text string, isError bool) map[string]interface{} {
+	return map[string]interface{}{
+		"content": []map[string]string{{"type": "text", "text": text}},
+		"isError": isError,
+	}
+}
+
+func (s *Service) callTool(params json.RawMessage) (interface{}, error) {
+	var p struct {
+		Name      string          `json:"name"`
+		Arguments json.RawMessage `json:"arguments"`
+	}
+	if err := decodeParams(params, &p); err != nil {
+		return nil, err
+	}
+
+	var (
+		text string
+		err  error
+	)
+	switch p.Name {
+	case "search_docs":
+		text, err = s.searchDocs(p.Arguments)
+	case "read_doc":
+		text, err = s.readDoc(p.Arguments)
+	case "list_docs":
+		text, err = s.listDocs(p.Arguments)
+	default:
+		return nil, &Error{Code: codeInvalidParams, Message: "unknown tool: " + p.Name}
+	}
+
+	// tool failures are reported in the result so the model can see them
+	if err != nil {
+		return toolResult(err.Error(), true), nil
+	}
+	return toolResult(text, false), nil
+}
+
+func (s *Service) searchDocs(arguments json.RawMessage) (string, error) {
+	var args struct {
+		Query string `json:"query"`
+		Limit int    `json:"limit"`
+	}
+	if err := decodeParams(arguments, &args); err != nil {
+		return "", err
+	}
+	if strings.TrimSpace(args.Query) == "" {
+		return "", fmt.Errorf("query is required")
+	}
+	if args.Limit <= 0 {
+		args.Limit = defaultSearchLimit
+	}
+
+	if err := s.index.Refresh(); err != nil {
+		s.logger.Error("Failed to refresh search index", "error", err)
+	}
+
+	results := s.index.Search(args.Query, args.Limit)
+	if len(results) == 0 {
+		return fmt.Sprintf("No documents match %q.", args.Query), nil
+	}
+
+	data, err := json.MarshalIndent(results, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+func (s *Service) readDoc(arguments json.RawMessage) (string, error) {
+	var args struct {
+		Path string `json:"path"`
+	}
+	if err := decodeParams(arguments, &args); err != nil {
+		return "", err
+	}
+	if args.Path == "" {
+		return "", fmt.Errorf("path is required")
+	}
+
+	content, err := s.readDocument(args.Path)
+	if err != nil {
+		return "", err
+	}
+	return string(content), nil
+}
+
+func (s *Service) listDocs(arguments json.RawMessage) (string, error) {
+	var args struct {
+		Prefix string `json:"prefix"`
+	}
+	if err := decodeParams(arguments, &args); err != nil {
+		return "", err
+	}
+
+	documents, err := docs.List(s.fs, s.baseDir)
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	count := 0
+	for _, doc := range documents {
+		if args.Prefix != "" && !strings.HasPrefix(doc.Path, args.Prefix) {
+			continue
+		}
+		title := doc.Title
+		if title == "" {
+			title = path.Base(doc.File)
+		}
+		fmt.Fprintf(&b, "%s\t%s\n", doc.Path, title)
+		count++
+	}
+
+	if count == 0 {
+		return "No documents found.", nil
+	}
+	return b.String(), nil
+}
+
+func (s *Service) listResources() (interface{}, error) {
+	documents, err := docs.List(s.fs, s.baseDir)
+	if err != nil {
+		return nil, err
+	}
+
+	resources := make([]map[string]string, 0, len(documents))
+	for _, doc := range documents {
+		resource := map[string]string{
+			"uri":      resourceScheme + doc.File,
+			"name":     doc.File,
+			"mimeType": "text/markdown",
+		}
+		if doc.Title != "" {
+			resource["title"] = doc.Title
+		}
+		resources = append(resources, resource)
+	}
+
+	return map[string]interface{}{"resources": resources}, nil
+}
+
+func (s *Service) readResource(params json.RawMessage) (interface{}, error) {
+	var p struct {
+		URI string `json:"uri"`
+	}
+	if err := decodeParams(params, &p); err != nil {
+		return nil, err
+	}
+
+	file, ok := strings.CutPrefix(p.URI, resourceScheme)
+	if !ok {
+		return nil, &Error{Code: codeInvalidParams, Message: "unknown resource: " + p.URI}
+	}
+
+	content, err := s.readDocument(file)
+	if err != nil {
+		return nil, &Error{Code: codeInvalidParams, Message: err.Error()}
+	}
+
+	return map[string]interface{}{
+		"contents": []map[string]string{{
+			"uri":      p.URI,
+			"mimeType": "text/markdown",
+			"text":     string(content),
+		}},
+	}, nil
+}
+
+// readDocument reads a document by URL path or file name, using the same
+// mapping and path checks as the HTTP server
+func (s *Service) readDocument(docPath string) ([]byte, error) {
+	requestPath := strings.TrimPrefix(docPath, "/")
+	if requestPath == "" || strings.HasSuffix(requestPath, "/") {
+		requestPath += "index"
+	}
+	if !strings.HasSuffix(requestPath, ".md") {
+		requestPath += ".md"
+	}
+
+	if docs.IsHidden(requestPath) {
+		return nil, fmt.Errorf("document not found: %s", docPath)
+	}
+	filePath, ok := docs.ResolvePath(s.baseDir, requestPath)
+	if !ok {
+		return nil, fmt.Errorf("invalid path: %s", docPath)
+	}
+
+	if info, err := s.fs.Stat(filePath); err != nil || !info.IsExist() || info.IsDir() {
+		return nil, fmt.Errorf("document not found: %s", docPath)
+	}
+
+	return s.fs.ReadFile(filePath)
+}
@@ -0,0 +1,92 @@
+package mcp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+
+	"mdify/pkg/server"
+)
+
+// maxMessageSize bounds a single JSON-RPC message read from stdio or HTTP
+const maxMessageSize = 10 * 1024 * 1024
+
+// ServeStdio answers newline-delimited JSON-RPC messages read from in,
+// writing responses to out until in is closed
+func (s *Service) ServeStdio(in io.Reader, out io.Writer) error {
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, 64*1024), maxMessageSize)
+
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		response := s.Handle(line)
+		if response == nil {
+			continue
+		}
+		if _, err := out.Write(append(response, '\n')); err != nil {
+			return fmt.Errorf("failed to write response: %w", err)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read request: %w", err)
+	}
+	return nil
+}
+
+// ServeHTTP implements the streamable HTTP transport. Every request gets a
+// single JSON response; the server never opens an event stream.
+func (s *Service) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// guard against DNS rebinding from browsers on other sites
+	if origin := r.Header.Get("Origin"); origin != "" {
+		if u, err := url.Parse(origin); err != nil || u.Host != r.Host {
+			http.Error(w, "Forbidden origin", http.StatusForbidden)
+			return
+		}
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxMessageSize))
+	if err != nil {
+		http.Error(w, "Failed to read request", http.StatusBadRequest)
+		return
+	}
+
+	response := s.Handle(body)
+	if response == nil {
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(response)
+}
+
+// ListenAndServe serves the MCP endpoint at /mcp on addr, a host:port or
+// unix:/path/to.sock, until ctx is cancelled. It uses the markdown server's
+// timeouts and shuts down as gracefully.
+func (s *Service) ListenAndServe(ctx context.Context, addr string) error {
+	mux := http.NewServeMux()
+	mux.Handle("/mcp", s)
+
+	srv, err := server.NewServer(mux, server.Config{Addr: addr}, s.logger)
+	if err != nil {
+		return err
+	}
+
+	s.logger.Info("MCP server running", "url", srv.URL()+"/mcp")
+	return srv.Serve(ctx)
+}
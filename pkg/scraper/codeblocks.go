@@ -0,0 +1,200 @@
+package scraper
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	md "github.com/JohannesKaufmann/html-to-markdown"
+	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/net/html"
+)
+
+// gutterSelectors match line-number gutters emitted by common highlighters
+const gutterSelectors = ".linenos, .lineno, .line-numbers-rows, .hljs-ln-numbers, .gutter, .line-number, .ln, [data-line-number]"
+
+// copyButtonSelectors match copy-to-clipboard widgets placed inside code
+// blocks; only buttons and copy-code toolbars, so captions and links that
+// mention copying are kept
+const copyButtonSelectors = "button[class*=copy], button[class*=clipboard], [class*=copy-code], [class*=code-copy], [class*=copy-button], [class*=btn-copy], clipboard-copy"
+
+// codeWrapperSelectors match the elements highlighters wrap around a <pre>
+const codeWrapperSelectors = "[class*=highlight], .codehilite, .sourceCode, [class*=code-block], [class*=codeblock], [class*=code-toolbar], [class*=language-], [class*=shiki], [class*=prism]"
+
+// languageIgnore lists class tokens that never name a language
+var languageIgnore = map[string]bool{
+	"hljs":         true,
+	"highlight":    true,
+	"highlighter":  true,
+	"shiki":        true,
+	"chroma":       true,
+	"prettyprint":  true,
+	"line-numbers": true,
+	"linenums":     true,
+	"notranslate":  true,
+	"code":         true,
+	"codehilite":   true,
+	"sourcecode":   true,
+	"default":      true,
+	"none":         true,
+	"text":         true,
+	"plaintext":    true,
+}
+
+// CodeBlocks is a converter plugin that turns highlighter markup (Prism,
+// highlight.js, Shiki, Pygments) into clean fenced code blocks with the
+// detected language as the info string
+func CodeBlocks() md.Plugin {
+	return func(c *md.Converter) []md.Rule {
+		c.Before(cleanCodeBlocks)
+
+		return []md.Rule{
+			{
+				Filter: []string{"pre"},
+				Replacement: func(content string, selec *goquery.Selection, opt *md.Options) *string {
+					code := strings.TrimRight(codeText(selec.Nodes[0]), "\n")
+					language := DetectLanguage(selec)
+
+					fenceChar, _ := utf8.DecodeRuneInString(opt.Fence)
+					fence := md.CalculateCodeFence(fenceChar, code)
+
+					text := "\n\n" + fence + language + "\n" + code + "\n" + fence + "\n\n"
+					return &text
+				},
+			},
+		}
+	}
+}
+
+// cleanCodeBlocks removes gutters and copy buttons and unwraps the table
+// layouts some highlighters use for line numbers
+func cleanCodeBlocks(selec *goquery.Selection) {
+	// Pygments and Hexo: <table><td class="linenos">…</td><td class="code"><pre>…</pre></td></table>
+	selec.Find("table").Has("td.linenos, td.gutter").Each(func(i int, table *goquery.Selection) {
+		pre := table.Find("td.code pre").First()
+		if pre.Length() == 0 {
+			return
+		}
+		table.ReplaceWithSelection(pre)
+	})
+
+	// highlight.js line numbers plugin: one table row per line inside <code>
+	selec.Find("table.hljs-ln").Each(func(i int, table *goquery.Selection) {
+		var lines []string
+		table.Find("td.hljs-ln-code").Each(func(j int, cell *goquery.Selection) {
+			lines = append(lines, cell.Text())
+		})
+		table.ReplaceWithHtml(html.EscapeString(strings.Join(lines, "\n")))
+	})
+
+	selec.Find("pre").Each(func(i int, pre *goquery.Selection) {
+		pre.Find(gutterSelectors).Remove()
+		pre.Find(copyButtonSelectors).Remove()
+
+		// copy buttons usually sit next to the <pre> inside the highlighter wrapper
+		wrapper := pre.Parent()
+		if wrapper.Is(codeWrapperSelectors) && wrapper.Find("pre").Length() == 1 {
+			wrapper.Find(copyButtonSelectors).Not("pre *").Remove()
+		}
+	})
+}
+
+// DetectLanguage returns the language of a <pre> block from class="language-x",
+// data-lang attributes, or the classes of highlighter wrappers around it
+func DetectLanguage(pre *goquery.Selection) string {
+	candidates := []*goquery.Selection{pre.Find("code").First(), pre}
+	pre.Parents().Slice(0, min(3, pre.Parents().Length())).Each(func(i int, s *goquery.Selection) {
+		candidates = append(candidates, s)
+	})
+
+	for _, s := range candidates {
+		if s.Length() == 0 {
+			continue
+		}
+		for _, attr := range []string{"data-lang", "data-language"} {
+			if lang := normalizeLanguage(s.AttrOr(attr, "")); lang != "" {
+				return lang
+			}
+		}
+		if lang := languageFromClass(s.AttrOr("class", "")); lang != "" {
+			return lang
+		}
+	}
+
+	return ""
+}
+
+func languageFromClass(class string) string {
+	tokens := strings.Fields(class)
+
+	for _, token := range tokens {
+		for _, prefix := range []string{"language-", "lang-", "highlight-source-", "highlight-", "sourceCode-"} {
+			if strings.HasPrefix(token, prefix) {
+				if lang := normalizeLanguage(strings.TrimPrefix(token, prefix)); lang != "" {
+					return lang
+				}
+			}
+		}
+	}
+
+	// highlight.js, Pandoc and Hexo put the bare language next to a marker class
+	for _, marker := range []string{"hljs", "sourceCode", "highlight"} {
+		if !containsToken(tokens, marker) {
+			continue
+		}
+		for _, token := range tokens {
+			if token == marker || strings.HasPrefix(token, "hljs-") {
+				continue
+			}
+			if lang := normalizeLanguage(token); lang != "" {
+				return lang
+			}
+		}
+	}
+
+	return ""
+}
+
+func normalizeLanguage(lang string) string {
+	lang = strings.ToLower(strings.TrimSpace(lang))
+	if lang == "" || languageIgnore[lang] || strings.ContainsAny(lang, " `") {
+		return ""
+	}
+	return lang
+}
+
+func containsToken(tokens []string, token string) bool {
+	for _, t := range tokens {
+		if t == token {
+			return true
+		}
+	}
+	return false
+}
+
+// codeText returns the text of a code block, turning <br> into newlines
+// and skipping anything that isn't part of the code itself
+func codeText(node *html.Node) string {
+	var b strings.Builder
+
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode {
+			switch n.Data {
+			case "style", "script", "textarea", "button":
+				return
+			case "br":
+				b.WriteString("\n")
+			}
+		}
+		if n.Type == html.TextNode {
+			b.WriteString(n.Data)
+			return
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(node)
+
+	return b.String()
+}
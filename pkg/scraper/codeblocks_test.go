@@ -0,0 +1,124 @@
+package scraper
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCodeBlocks_Conversion(t *testing.T) {
+	tests := []struct {
+		name     string
+		html     string
+		expected string
+		absent   []string
+		present  []string
+	}{
+		{
+			name:     "prism language class",
+			html:     `<div class="content"><pre class="language-js line-numbers"><code class="language-js">const a = 1;<span class="line-numbers-rows"><span></span></span></code></pre></div>`,
+			expected: "```js\nconst a = 1;\n```",
+		},
+		{
+			name:     "highlight.js bare language",
+			html:     `<div class="content"><pre><code class="hljs python">print("hi")</code></pre></div>`,
+			expected: "```python\nprint(\"hi\")\n```",
+		},
+		{
+			name:     "data-lang attribute",
+			html:     `<div class="content"><div class="highlight"><pre class="chroma"><code data-lang="go">fmt.Println()</code></pre></div></div>`,
+			expected: "```go\nfmt.Println()\n```",
+		},
+		{
+			name:     "shiki lines",
+			html:     `<div class="content"><pre class="shiki" data-language="ts"><code><span class="line">let x = 1</span>` + "\n" + `<span class="line">let y = 2</span></code></pre></div>`,
+			expected: "```ts\nlet x = 1\nlet y = 2\n```",
+		},
+		{
+			name: "pygments table with line numbers",
+			html: `<div class="content"><div class="highlight-python notranslate"><div class="highlight"><table class="highlighttable"><tr>` +
+				`<td class="linenos"><div class="linenodiv"><pre>1` + "\n" + `2</pre></div></td>` +
+				`<td class="code"><div><pre><span></span>a = 1` + "\n" + `b = 2` + "\n" + `</pre></div></td></tr></table></div></div></div>`,
+			expected: "```python\na = 1\nb = 2\n```",
+			absent:   []string{"1\n2"},
+		},
+		{
+			name:     "pygments inline line numbers",
+			html:     `<div class="content"><div class="highlight-bash"><pre><span class="linenos">1</span>ls -la</pre></div></div>`,
+			expected: "```bash\nls -la\n```",
+		},
+		{
+			name:     "copy button removed",
+			html:     `<div class="content"><div class="code-block"><button class="copy">Copy</button><pre><code class="language-sh">make</code></pre></div></div>`,
+			expected: "```sh\nmake\n```",
+			absent:   []string{"Copy"},
+		},
+		{
+			name:     "copy toolbar removed",
+			html:     `<div class="content"><div class="codehilite"><pre><code class="language-sh">make</code></pre><div class="copy-code-toolbar"><span>Copy code</span></div></div></div>`,
+			expected: "```sh\nmake\n```",
+			absent:   []string{"Copy code"},
+		},
+		{
+			name: "elements with code in their class kept",
+			html: `<div class="content"><div class="example-code"><pre><code class="language-sh">make</code></pre>` +
+				`<button class="toggle-output">Show output</button><p class="copy">Copy this to /etc/app.conf</p></div>` +
+				`<p class="inline-code">Run <code>make</code> first</p></div>`,
+			expected: "```sh\nmake\n```",
+			present:  []string{"Show output", "Copy this to /etc/app.conf", "Run `make` first"},
+		},
+		{
+			name:     "no language",
+			html:     `<div class="content"><pre>plain text</pre></div>`,
+			expected: "```\nplain text\n```",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scraper := NewService(nil, nil, nil, nil, Config{})
+
+			result, err := scraper.ExtractContent(tt.html, ".content")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !strings.Contains(result, tt.expected) {
+				t.Errorf("expected result to contain %q, got %q", tt.expected, result)
+			}
+			for _, present := range tt.present {
+				if !strings.Contains(result, present) {
+					t.Errorf("expected result to contain %q, got %q", present, result)
+				}
+			}
+			for _, absent := range tt.absent {
+				if strings.Contains(result, absent) {
+					t.Errorf("expected result not to contain %q, got %q", absent, result)
+				}
+			}
+		})
+	}
+}
+
+func TestLanguageFromClass(t *testing.T) {
+	tests := []struct {
+		class    string
+		expected string
+	}{
+		{"language-rust", "rust"},
+		{"lang-ruby", "ruby"},
+		{"highlight-python3 notranslate", "python3"},
+		{"hljs language-yaml", "yaml"},
+		{"sourceCode haskell", "haskell"},
+		{"highlight js", "js"},
+		{"highlight", ""},
+		{"highlight-default", ""},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.class, func(t *testing.T) {
+			if got := languageFromClass(tt.class); got != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,313 @@
+package scraper
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"hash/fnv"
+	"math/bits"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Dedup modes: what happens to a page that duplicates one already saved
+const (
+	DedupOff     = "off"     // save every page
+	DedupRecord  = "record"  // save duplicates too, but list them in the run report
+	DedupSkip    = "skip"    // don't save duplicates
+	DedupSymlink = "symlink" // save duplicates as symlinks to the original
+)
+
+// Reasons a page is a duplicate
+const (
+	DuplicateCanonical = "canonical" // its <link rel=canonical> names another page saved in the run
+	DuplicateExact     = "exact"     // its text is the same as another page's
+	DuplicateNear      = "near"      // its simhash is within NearDuplicateBits of another page's
+)
+
+// Actions taken for a duplicate page
+const (
+	DuplicateSaved   = "saved"
+	DuplicateSkipped = "skipped"
+	DuplicateLinked  = "linked"
+)
+
+// DefaultNearDuplicateBits is the simhash distance below which pages are
+// near duplicates, out of 64 bits
+const DefaultNearDuplicateBits = 3
+
+// shingleSize is the number of words in each shingle hashed into a simhash
+const shingleSize = 3
+
+// minNearDuplicateWords is the length below which pages are only compared
+// exactly; the simhashes of short pages are too close to tell apart
+const minNearDuplicateWords = 50
+
+// ValidDedupMode reports whether mode is one of the dedup modes
+func ValidDedupMode(mode string) bool {
+	switch mode {
+	case DedupOff, DedupRecord, DedupSkip, DedupSymlink:
+		return true
+	}
+	return false
+}
+
+// Duplicate is a page whose content was already saved from another URL
+type Duplicate struct {
+	URL      string `json:"url"`
+	Original string `json:"original"`           // URL of the page it duplicates
+	Path     string `json:"path"`               // file the original is saved to
+	File     string `json:"file,omitempty"`     // file written for the duplicate, if any
+	Reason   string `json:"reason"`             // canonical, exact or near
+	Distance int    `json:"distance,omitempty"` // differing simhash bits, for near duplicates
+	Action   string `json:"action"`             // saved, skipped or linked
+}
+
+// original is a page saved in the run, which later pages are compared with
+type original struct {
+	url         string
+	path        string
+	simhash     uint64
+	fingerprint bool // whether the page was long enough for a simhash
+}
+
+// dedupIndex remembers the pages saved in a run. Checking a page and
+// claiming it as an original happen under one lock, so two workers never
+// both save the same content.
+type dedupIndex struct {
+	mu     sync.Mutex
+	saved  map[string]string // normalized URLs of the pages written so far, to their files
+	hashes map[[sha256.Size]byte]*original
+	pages  []*original
+}
+
+func newDedupIndex() *dedupIndex {
+	return &dedupIndex{
+		saved:  make(map[string]string),
+		hashes: make(map[[sha256.Size]byte]*original),
+	}
+}
+
+// markSaved records that the page at rawURL was written to path
+func (d *dedupIndex) markSaved(rawURL, path string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.saved[normalizeURL(rawURL)] = path
+}
+
+// savedPath returns the file the page at a normalized URL was written to,
+// if it has been saved in the run
+func (d *dedupIndex) savedPath(normalized string) (string, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	path, ok := d.saved[normalized]
+	return path, ok
+}
+
+// claim returns the page that markdown duplicates, or records it as an
+// original saved at path. maxBits is the simhash distance for near
+// duplicates; negative disables them.
+func (d *dedupIndex) claim(rawURL, path, markdown string, maxBits int) *Duplicate {
+	words := strings.Fields(strings.ToLower(markdown))
+	if len(words) == 0 {
+		return nil
+	}
+	hash := sha256.Sum256([]byte(strings.Join(words, " ")))
+
+	page := &original{url: rawURL, path: path}
+	if maxBits >= 0 && len(words) >= minNearDuplicateWords {
+		page.simhash, page.fingerprint = simhash(words), true
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if match, ok := d.hashes[hash]; ok {
+		return &Duplicate{URL: rawURL, Original: match.url, Path: match.path, Reason: DuplicateExact}
+	}
+	if page.fingerprint {
+		for _, other := range d.pages {
+			if !other.fingerprint {
+				continue
+			}
+			if distance := bits.OnesCount64(page.simhash ^ other.simhash); distance <= maxBits {
+				return &Duplicate{URL: rawURL, Original: other.url, Path: other.path, Reason: DuplicateNear, Distance: distance}
+			}
+		}
+	}
+
+	d.hashes[hash] = page
+	d.pages = append(d.pages, page)
+	return nil
+}
+
+// release forgets a page claimed by rawURL, e.g. because it failed to save
+func (d *dedupIndex) release(rawURL string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for hash, page := range d.hashes {
+		if page.url == rawURL {
+			delete(d.hashes, hash)
+		}
+	}
+	for i, page := range d.pages {
+		if page.url == rawURL {
+			d.pages = append(d.pages[:i], d.pages[i+1:]...)
+			break
+		}
+	}
+}
+
+// simhash fingerprints text from its overlapping word shingles, so that
+// similar texts have fingerprints differing in few bits
+func simhash(words []string) uint64 {
+	var weights [64]int
+	for i := 0; i+shingleSize <= len(words); i++ {
+		h := fnv.New64a()
+		h.Write([]byte(strings.Join(words[i:i+shingleSize], " ")))
+		sum := h.Sum64()
+		for bit := 0; bit < 64; bit++ {
+			if sum&(1<<bit) != 0 {
+				weights[bit]++
+			} else {
+				weights[bit]--
+			}
+		}
+	}
+
+	var fingerprint uint64
+	for bit, weight := range weights {
+		if weight > 0 {
+			fingerprint |= 1 << bit
+		}
+	}
+	return fingerprint
+}
+
+// normalizeURL puts a URL in a form where equivalent URLs compare equal:
+// lowercase scheme and host, no default port and no fragment
+func normalizeURL(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	parsed.Scheme = strings.ToLower(parsed.Scheme)
+	parsed.Host = strings.ToLower(parsed.Host)
+	if port := parsed.Port(); (parsed.Scheme == "http" && port == "80") || (parsed.Scheme == "https" && port == "443") {
+		parsed.Host = parsed.Hostname()
+	}
+	if parsed.Path == "" {
+		parsed.Path = "/"
+	}
+	parsed.Fragment = ""
+	parsed.RawFragment = ""
+	return parsed.String()
+}
+
+// resolveCanonical resolves a canonical link against the page it was found
+// on, returning "" when there is none or it points back at the page
+func resolveCanonical(rawURL, href string) string {
+	href = strings.TrimSpace(href)
+	if href == "" {
+		return ""
+	}
+	base, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	target, err := base.Parse(href)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") {
+		return ""
+	}
+
+	canonical := normalizeURL(target.String())
+	if canonical == normalizeURL(rawURL) {
+		return ""
+	}
+	return canonical
+}
+
+// dedupe checks a scraped page against the pages already saved in the run.
+// It returns nil for a page that should be saved as usual, after claiming
+// it as an original. A canonical link only makes a page a duplicate once
+// the page it names has been written; until then the page is saved, so a
+// failed original or two pages naming each other never lose both.
+func (s *Service) dedupe(rawURL, canonical, output, markdown string) (*Duplicate, error) {
+	if s.dedup == nil {
+		return nil, nil
+	}
+
+	path, err := s.outputPath(rawURL, output)
+	if err != nil {
+		return nil, err
+	}
+
+	var duplicate *Duplicate
+	if original, ok := s.dedup.savedPath(canonical); ok {
+		duplicate = &Duplicate{URL: rawURL, Original: canonical, Path: original, Reason: DuplicateCanonical}
+	} else {
+		duplicate = s.dedup.claim(rawURL, path, markdown, s.config.NearDuplicateBits)
+	}
+	if duplicate == nil {
+		return nil, nil
+	}
+
+	switch {
+	case s.config.Dedup == DedupRecord:
+		duplicate.Action, duplicate.File = DuplicateSaved, path
+	case s.config.Dedup == DedupSymlink && path != duplicate.Path:
+		duplicate.Action, duplicate.File = DuplicateLinked, path
+	default:
+		duplicate.Action = DuplicateSkipped
+	}
+	return duplicate, nil
+}
+
+// linkDuplicate writes a symlink from a duplicate's file to its original
+func (s *Service) linkDuplicate(duplicate *Duplicate) error {
+	if duplicate.Action != DuplicateLinked {
+		return nil
+	}
+
+	dir := filepath.Dir(duplicate.File)
+	if err := s.fs.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create directory %s: %w", dir, err)
+	}
+	target, err := filepath.Rel(dir, duplicate.Path)
+	if err != nil {
+		return fmt.Errorf("failed to link %s to %s: %w", duplicate.File, duplicate.Path, err)
+	}
+	s.recordSource(duplicate.File, duplicate.URL)
+	if err := s.fs.Symlink(target, duplicate.File); err != nil {
+		return fmt.Errorf("failed to link %s to %s: %w", duplicate.File, duplicate.Path, err)
+	}
+	return nil
+}
+
+// markSaved records a page written to path, so later pages naming it as
+// canonical are duplicates
+func (s *Service) markSaved(rawURL, path string) {
+	if s.dedup != nil {
+		s.dedup.markSaved(rawURL, path)
+	}
+}
+
+// release forgets a page claimed as an original that was not saved
+func (s *Service) release(rawURL string) {
+	if s.dedup != nil {
+		s.dedup.release(rawURL)
+	}
+}
+
+func (s *Service) logDuplicate(duplicate *Duplicate) {
+	s.logger.Info("Duplicate",
+		"url", duplicate.URL,
+		"original", duplicate.Original,
+		"reason", duplicate.Reason,
+		"action", duplicate.Action,
+	)
+}
@@ -0,0 +1,390 @@
+package scraper
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// article returns a page of prose long enough to get a simhash
+func article(topic string, words int) string {
+	var b strings.Builder
+	for i := 0; i < words; i++ {
+		fmt.Fprintf(&b, "%s%d ", topic, i%37)
+		if i%12 == 11 {
+			b.WriteString("\n\n")
+		}
+	}
+	return b.String()
+}
+
+func TestNormalizeURL(t *testing.T) {
+	tests := []struct {
+		url      string
+		expected string
+	}{
+		{url: "https://Example.COM/Docs", expected: "https://example.com/Docs"},
+		{url: "https://example.com:443/docs#intro", expected: "https://example.com/docs"},
+		{url: "http://example.com:80", expected: "http://example.com/"},
+		{url: "http://example.com:8080/a?b=c", expected: "http://example.com:8080/a?b=c"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			if got := normalizeURL(tt.url); got != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestResolveCanonical(t *testing.T) {
+	tests := []struct {
+		name     string
+		url      string
+		href     string
+		expected string
+	}{
+		{name: "no link", url: "https://example.com/latest/api", href: "", expected: ""},
+		{name: "absolute", url: "https://example.com/latest/api", href: "https://example.com/v3/api", expected: "https://example.com/v3/api"},
+		{name: "relative", url: "https://example.com/latest/api", href: "../v3/api", expected: "https://example.com/v3/api"},
+		{name: "self", url: "https://example.com/v3/api", href: "https://EXAMPLE.com/v3/api#top", expected: ""},
+		{name: "not http", url: "https://example.com/api", href: "javascript:void(0)", expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := resolveCanonical(tt.url, tt.href); got != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestSimhash(t *testing.T) {
+	words := strings.Fields(article("alpha", 200))
+
+	edited := append([]string(nil), words...)
+	edited[100] = "changed"
+	unrelated := strings.Fields(article("omega", 200))
+
+	near := simhash(words) ^ simhash(edited)
+	far := simhash(words) ^ simhash(unrelated)
+	if bitsSet(near) > DefaultNearDuplicateBits {
+		t.Errorf("expected a one-word edit to stay within %d bits, got %d", DefaultNearDuplicateBits, bitsSet(near))
+	}
+	if bitsSet(far) <= DefaultNearDuplicateBits {
+		t.Errorf("expected unrelated text to differ in more than %d bits, got %d", DefaultNearDuplicateBits, bitsSet(far))
+	}
+}
+
+func bitsSet(x uint64) int {
+	n := 0
+	for ; x != 0; x &= x - 1 {
+		n++
+	}
+	return n
+}
+
+func TestDedupIndex_Claim(t *testing.T) {
+	long := article("alpha", 200)
+	edited := strings.Replace(long, "alpha5 ", "beta ", 1)
+
+	tests := []struct {
+		name           string
+		markdown       string
+		maxBits        int
+		expectedReason string
+	}{
+		{name: "new content", markdown: article("omega", 200), maxBits: 3},
+		{name: "exact copy", markdown: long, maxBits: 3, expectedReason: DuplicateExact},
+		{name: "whitespace and case differences", markdown: strings.ToUpper(strings.ReplaceAll(long, " ", "\n ")), maxBits: 3, expectedReason: DuplicateExact},
+		{name: "near copy", markdown: edited, maxBits: 3, expectedReason: DuplicateNear},
+		{name: "near copy with near detection off", markdown: edited, maxBits: -1},
+		{name: "empty page", markdown: "\n\n", maxBits: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			index := newDedupIndex()
+			if index.claim("https://example.com/original", "/out/original.md", long, 3) != nil {
+				t.Fatal("expected the first page to be an original")
+			}
+
+			duplicate := index.claim("https://example.com/copy", "/out/copy.md", tt.markdown, tt.maxBits)
+			if tt.expectedReason == "" {
+				if duplicate != nil {
+					t.Errorf("expected no duplicate, got %+v", duplicate)
+				}
+				return
+			}
+			if duplicate == nil {
+				t.Fatal("expected a duplicate")
+			}
+			if duplicate.Reason != tt.expectedReason || duplicate.Original != "https://example.com/original" || duplicate.Path != "/out/original.md" {
+				t.Errorf("unexpected duplicate %+v", duplicate)
+			}
+		})
+	}
+}
+
+func TestDedupIndex_Release(t *testing.T) {
+	index := newDedupIndex()
+	index.claim("https://example.com/a", "/out/a.md", "same text", -1)
+	index.release("https://example.com/a")
+
+	if duplicate := index.claim("https://example.com/b", "/out/b.md", "same text", -1); duplicate != nil {
+		t.Errorf("expected a released page not to be an original, got %+v", duplicate)
+	}
+}
+
+func TestScraperService_Dedup(t *testing.T) {
+	body := article("alpha", 120)
+	page := func(canonical, content string) string {
+		link := ""
+		if canonical != "" {
+			link = fmt.Sprintf(`<link rel="canonical" href="%s">`, canonical)
+		}
+		return fmt.Sprintf(`<html><head>%s</head><body><div class="content"><p>%s</p></div></body></html>`, link, content)
+	}
+
+	tests := []struct {
+		name             string
+		mode             string
+		expectedFiles    []string
+		expectedSymlinks map[string]string
+		expectedAction   string
+	}{
+		{
+			name:          "off saves everything",
+			mode:          DedupOff,
+			expectedFiles: []string{"/out/v3/api.md", "/out/latest/api.md", "/out/print/api.md", "/out/other.md"},
+		},
+		{
+			name:           "record saves and reports",
+			mode:           DedupRecord,
+			expectedFiles:  []string{"/out/v3/api.md", "/out/latest/api.md", "/out/print/api.md", "/out/other.md"},
+			expectedAction: DuplicateSaved,
+		},
+		{
+			name:           "skip",
+			mode:           DedupSkip,
+			expectedFiles:  []string{"/out/v3/api.md", "/out/other.md"},
+			expectedAction: DuplicateSkipped,
+		},
+		{
+			name:          "symlink",
+			mode:          DedupSymlink,
+			expectedFiles: []string{"/out/v3/api.md", "/out/other.md"},
+			expectedSymlinks: map[string]string{
+				"/out/latest/api.md": "../v3/api.md",
+				"/out/print/api.md":  "../v3/api.md",
+			},
+			expectedAction: DuplicateLinked,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewMockHTTPClient()
+			client.SetResponse("https://example.com/v3/api", 200, page("", body))
+			client.SetResponse("https://example.com/latest/api", 200, page("/v3/api", body))
+			client.SetResponse("https://example.com/print/api", 200, page("", body))
+			client.SetResponse("https://example.com/other", 200, page("", article("omega", 120)))
+
+			fs := NewMockFileSystem()
+			logger := NewMockLogger()
+			scraper := NewService(client, fs, NewMockSleeper(), logger, Config{Workers: 1, Dedup: tt.mode, NearDuplicateBits: DefaultNearDuplicateBits})
+
+			urls := []string{"https://example.com/v3/api", "https://example.com/latest/api", "https://example.com/print/api", "https://example.com/other"}
+			if err := scraper.ScrapeURLs(urls, ".content", "/out"); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if files := fs.GetCreatedFiles(); !reflect.DeepEqual(files, tt.expectedFiles) {
+				t.Errorf("expected files %v, got %v", tt.expectedFiles, files)
+			}
+			if tt.expectedSymlinks == nil {
+				tt.expectedSymlinks = map[string]string{}
+			}
+			if links := fs.GetSymlinks(); !reflect.DeepEqual(links, tt.expectedSymlinks) {
+				t.Errorf("expected symlinks %v, got %v", tt.expectedSymlinks, links)
+			}
+
+			report := scraper.Report()
+			if report.URLs != 4 || len(report.Failed) != 0 {
+				t.Errorf("unexpected report %+v", report)
+			}
+			if tt.expectedAction == "" {
+				if len(report.Duplicates) != 0 || report.Saved != 4 {
+					t.Errorf("expected no duplicates with dedup off, got %+v", report)
+				}
+				return
+			}
+
+			expected := []Duplicate{
+				{URL: "https://example.com/latest/api", Original: "https://example.com/v3/api", Path: "/out/v3/api.md", Reason: DuplicateCanonical, Action: tt.expectedAction},
+				{URL: "https://example.com/print/api", Original: "https://example.com/v3/api", Path: "/out/v3/api.md", Reason: DuplicateExact, Action: tt.expectedAction},
+			}
+			if tt.expectedAction != DuplicateSkipped {
+				expected[0].File = "/out/latest/api.md"
+				expected[1].File = "/out/print/api.md"
+			}
+			if !reflect.DeepEqual(report.Duplicates, expected) {
+				t.Errorf("expected duplicates %+v, got %+v", expected, report.Duplicates)
+			}
+
+			found := false
+			for _, message := range logger.GetMessages() {
+				if strings.Contains(message, "INFO Duplicate url=https://example.com/print/api original=https://example.com/v3/api reason=exact action="+tt.expectedAction) {
+					found = true
+				}
+				if strings.Contains(message, "INFO Completed") && !strings.Contains(message, "duplicates=2") {
+					t.Errorf("expected 2 duplicates in the summary, got %q", message)
+				}
+			}
+			if !found {
+				t.Errorf("expected a duplicate log, got %v", logger.GetMessages())
+			}
+		})
+	}
+}
+
+func TestScraperService_DedupCanonical(t *testing.T) {
+	page := func(canonical, content string) string {
+		return fmt.Sprintf(`<html><head><link rel="canonical" href="%s"></head><body><div class="content"><p>%s</p></div></body></html>`, canonical, content)
+	}
+
+	tests := []struct {
+		name               string
+		setup              func(client *MockHTTPClient)
+		expectedFiles      []string
+		expectedDuplicates []Duplicate
+	}{
+		{
+			name: "failed original",
+			setup: func(client *MockHTTPClient) {
+				client.SetResponse("https://example.com/v3/api", 500, "")
+				client.SetResponse("https://example.com/latest/api", 200, page("/v3/api", article("alpha", 120)))
+			},
+			expectedFiles:      []string{"/out/latest/api.md"},
+			expectedDuplicates: []Duplicate{},
+		},
+		{
+			name: "mutual canonicals",
+			setup: func(client *MockHTTPClient) {
+				client.SetResponse("https://example.com/v3/api", 200, page("/latest/api", article("alpha", 120)))
+				client.SetResponse("https://example.com/latest/api", 200, page("/v3/api", article("omega", 120)))
+			},
+			expectedFiles: []string{"/out/v3/api.md"},
+			expectedDuplicates: []Duplicate{
+				{URL: "https://example.com/latest/api", Original: "https://example.com/v3/api", Path: "/out/v3/api.md", Reason: DuplicateCanonical, Action: DuplicateSkipped},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewMockHTTPClient()
+			tt.setup(client)
+
+			fs := NewMockFileSystem()
+			scraper := NewService(client, fs, NewMockSleeper(), NewMockLogger(), Config{Workers: 1, Dedup: DedupSkip})
+			urls := []string{"https://example.com/v3/api", "https://example.com/latest/api"}
+			_ = scraper.ScrapeURLs(urls, ".content", "/out")
+
+			if files := fs.GetCreatedFiles(); !reflect.DeepEqual(files, tt.expectedFiles) {
+				t.Errorf("expected files %v, got %v", tt.expectedFiles, files)
+			}
+			if duplicates := scraper.Report().Duplicates; !reflect.DeepEqual(duplicates, tt.expectedDuplicates) {
+				t.Errorf("expected duplicates %+v, got %+v", tt.expectedDuplicates, duplicates)
+			}
+		})
+	}
+}
+
+func TestScraperService_DedupConcurrent(t *testing.T) {
+	client := NewMockHTTPClient()
+	var urls []string
+	for i := 0; i < 6; i++ {
+		url := fmt.Sprintf("https://example.com/copy%d", i)
+		client.SetResponse(url, 200, `<div class="content"><p>Same text on every page.</p></div>`)
+		urls = append(urls, url)
+	}
+
+	scraper := NewService(client, NewMockFileSystem(), NewMockSleeper(), NewMockLogger(), Config{Workers: 3, Dedup: DedupSkip})
+	if err := scraper.ScrapeURLs(urls, ".content", "/out"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	report := scraper.Report()
+	if report.Saved != 1 || len(report.Duplicates) != 5 {
+		t.Errorf("expected exactly one copy saved and 5 duplicates, got %d saved and %d duplicates", report.Saved, len(report.Duplicates))
+	}
+}
+
+func TestRunReport_Write(t *testing.T) {
+	report := RunReport{
+		URLs:       2,
+		Saved:      1,
+		Failed:     []Failure{{URL: "https://example.com/a?x=1&y=2", Error: "404 not found"}},
+		Duplicates: []Duplicate{},
+	}
+
+	var out bytes.Buffer
+	if err := report.Write(&out); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(out.String(), `"url": "https://example.com/a?x=1&y=2"`) {
+		t.Errorf("expected URLs to be written unescaped, got %s", out.String())
+	}
+
+	var decoded RunReport
+	if err := json.Unmarshal(out.Bytes(), &decoded); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if !reflect.DeepEqual(decoded, report) {
+		t.Errorf("expected %+v, got %+v", report, decoded)
+	}
+}
+
+// recordingFileSystem is a file system that keeps source URLs
+type recordingFileSystem struct {
+	*MockFileSystem
+	mu      sync.Mutex
+	sources map[string]string
+}
+
+func (r *recordingFileSystem) RecordSource(path, sourceURL string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sources[path] = sourceURL
+}
+
+func TestScraperService_RecordSource(t *testing.T) {
+	for _, workers := range []int{1, 2} {
+		t.Run(fmt.Sprintf("workers=%d", workers), func(t *testing.T) {
+			client := NewMockHTTPClient()
+			client.SetResponse("https://example.com/a", 200, `<div class="content"><p>Page A</p></div>`)
+			client.SetResponse("https://example.com/b/c", 200, `<div class="content"><p>Page C</p></div>`)
+
+			fs := &recordingFileSystem{MockFileSystem: NewMockFileSystem(), sources: map[string]string{}}
+			scraper := NewService(client, fs, NewMockSleeper(), NewMockLogger(), Config{Workers: workers})
+			if err := scraper.ScrapeURLs([]string{"https://example.com/a", "https://example.com/b/c"}, ".content", "/out"); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			expected := map[string]string{
+				"/out/a.md":   "https://example.com/a",
+				"/out/b/c.md": "https://example.com/b/c",
+			}
+			if !reflect.DeepEqual(fs.sources, expected) {
+				t.Errorf("expected sources %v, got %v", expected, fs.sources)
+			}
+		})
+	}
+}
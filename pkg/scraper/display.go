@@ -0,0 +1,124 @@
+package scraper
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// Refresh intervals for the progress display
+const (
+	lineRefreshInterval = 200 * time.Millisecond
+	logRefreshInterval  = 10 * time.Second
+)
+
+// clearLine moves the cursor to the start of the line and erases it
+const clearLine = "\r\033[K"
+
+// ProgressDisplay shows a run's progress. On a terminal it redraws a single
+// status line in place; otherwise it logs a summary every few seconds. It is
+// also a Logger, so log lines are printed above the status line rather than
+// through it.
+type ProgressDisplay struct {
+	progress    *Progress
+	out         io.Writer
+	logger      Logger
+	interactive bool
+
+	mu   sync.Mutex
+	line string // status line currently on screen
+}
+
+// NewProgressDisplay creates a display that writes the status line to out
+// when interactive, and logs through logger
+func NewProgressDisplay(progress *Progress, out io.Writer, logger Logger, interactive bool) *ProgressDisplay {
+	return &ProgressDisplay{
+		progress:    progress,
+		out:         out,
+		logger:      logger,
+		interactive: interactive,
+	}
+}
+
+// Debug, Info, Warn and Error log a message without garbling the status line
+func (d *ProgressDisplay) Debug(msg string, args ...interface{}) { d.log(d.logger.Debug, msg, args) }
+func (d *ProgressDisplay) Info(msg string, args ...interface{})  { d.log(d.logger.Info, msg, args) }
+func (d *ProgressDisplay) Warn(msg string, args ...interface{})  { d.log(d.logger.Warn, msg, args) }
+func (d *ProgressDisplay) Error(msg string, args ...interface{}) { d.log(d.logger.Error, msg, args) }
+
+func (d *ProgressDisplay) log(write func(string, ...interface{}), msg string, args []interface{}) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.line != "" {
+		fmt.Fprint(d.out, clearLine)
+	}
+	write(msg, args...)
+	if d.line != "" {
+		fmt.Fprint(d.out, d.line)
+	}
+}
+
+// Run refreshes the display until stop is closed, then removes the status line
+func (d *ProgressDisplay) Run(stop <-chan struct{}) {
+	interval := logRefreshInterval
+	if d.interactive {
+		interval = lineRefreshInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			d.refresh()
+		case <-stop:
+			d.mu.Lock()
+			if d.line != "" {
+				fmt.Fprint(d.out, clearLine)
+				d.line = ""
+			}
+			d.mu.Unlock()
+			return
+		}
+	}
+}
+
+func (d *ProgressDisplay) refresh() {
+	snapshot := d.progress.Snapshot()
+	if snapshot.Total == 0 {
+		return
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.interactive {
+		d.line = snapshot.String()
+		fmt.Fprint(d.out, clearLine+d.line)
+		return
+	}
+
+	d.logger.Info("Progress",
+		"finished", snapshot.Done+snapshot.Failed,
+		"total", snapshot.Total,
+		"active", snapshot.InFlight,
+		"failed", snapshot.Failed,
+		"pages_per_second", fmt.Sprintf("%.1f", snapshot.PagesPerSecond),
+		"bytes", snapshot.Bytes,
+		"eta", snapshot.ETA.Round(time.Second),
+	)
+	if len(snapshot.Hosts) > 1 {
+		for _, host := range snapshot.Hosts {
+			d.logger.Info("Host progress",
+				"host", host.Host,
+				"done", host.Done,
+				"failed", host.Failed,
+				"active", host.InFlight,
+				"bytes", host.Bytes,
+				"last_error", host.LastError,
+			)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package scraper
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestProgressDisplay_Interactive(t *testing.T) {
+	progress := NewProgress()
+	progress.start(2)
+	var out bytes.Buffer
+	logger := NewMockLogger()
+	display := NewProgressDisplay(progress, &out, logger, true)
+
+	display.refresh()
+	if out.String() != clearLine+"0/2 (0%) | 0 active | 0 failed | 0.0 pages/s | 0 B" {
+		t.Errorf("expected the status line to be drawn, got %q", out.String())
+	}
+
+	out.Reset()
+	display.Info("Scraping", "url", "https://example.com")
+	if logger.GetLastMessage() != "INFO Scraping url=https://example.com" {
+		t.Errorf("expected message to be logged, got %q", logger.GetLastMessage())
+	}
+	if !strings.HasPrefix(out.String(), clearLine) || !strings.HasSuffix(out.String(), display.line) {
+		t.Errorf("expected the status line to be cleared and redrawn, got %q", out.String())
+	}
+
+	out.Reset()
+	stop := make(chan struct{})
+	close(stop)
+	display.Run(stop)
+	if out.String() != clearLine || display.line != "" {
+		t.Errorf("expected the status line to be removed on stop, got %q", out.String())
+	}
+}
+
+func TestProgressDisplay_Log(t *testing.T) {
+	progress := NewProgress()
+	var out bytes.Buffer
+	logger := NewMockLogger()
+	display := NewProgressDisplay(progress, &out, logger, false)
+
+	// nothing to report before the run starts
+	display.refresh()
+	if len(logger.GetMessages()) != 0 {
+		t.Errorf("expected no progress before start, got %v", logger.GetMessages())
+	}
+
+	progress.start(2)
+	progress.begin("https://a.example.com/1")
+	progress.begin("https://b.example.com/1")
+	display.refresh()
+	display.Debug("Scraping", "url", "https://example.com")
+
+	messages := logger.GetMessages()
+	if len(messages) != 4 || !strings.HasPrefix(messages[0], "INFO Progress finished=0 total=2 active=2") || !strings.Contains(messages[1], "host=a.example.com done=0") {
+		t.Errorf("expected a summary and a line per host, got %v", messages)
+	}
+	if out.Len() != 0 {
+		t.Errorf("expected no terminal output, got %q", out.String())
+	}
+}
+
+func TestProgressDisplay_RunRefreshes(t *testing.T) {
+	progress := NewProgress()
+	progress.start(1)
+	var out bytes.Buffer
+	display := NewProgressDisplay(progress, &out, NewMockLogger(), true)
+
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		display.Run(stop)
+		close(done)
+	}()
+
+	time.Sleep(3 * lineRefreshInterval)
+	close(stop)
+	<-done
+
+	if !strings.Contains(out.String(), "0/1 (0%)") {
+		t.Errorf("expected the status line to be drawn while running, got %q", out.String())
+	}
+}
@@ -0,0 +1,60 @@
+package scraper
+
+import (
+	"reflect"
+	"testing"
+
+	"mdify/internal/filesystem"
+)
+
+func TestScraperService_MemFileSystem(t *testing.T) {
+	body := article("alpha", 120)
+
+	for _, workers := range []int{1, 3} {
+		client := NewMockHTTPClient()
+		client.SetResponse("https://example.com/", 200, `<div class="content"><h1>Home</h1><p>Welcome.</p></div>`)
+		client.SetResponse("https://example.com/v3/api", 200, `<div class="content"><p>`+body+`</p></div>`)
+		client.SetResponse("https://example.com/latest/api", 200, `<div class="content"><p>`+body+`</p></div>`)
+
+		fs := filesystem.NewMemFileSystem()
+		scraper := NewService(client, fs, NewMockSleeper(), NewMockLogger(), Config{Workers: workers, Dedup: DedupSymlink})
+
+		urls := []string{"https://example.com/", "https://example.com/v3/api", "https://example.com/latest/api"}
+		if err := scraper.ScrapeURLs(urls, ".content", "/out"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		home, err := fs.ReadFile("/out/index.md")
+		if err != nil {
+			t.Fatalf("expected index.md to be written: %v", err)
+		}
+		if string(home) != "# Home\n\nWelcome." {
+			t.Errorf("unexpected markdown %q", home)
+		}
+
+		// with several workers either copy may be the original
+		original, err := fs.ReadFile("/out/v3/api.md")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		duplicate, err := fs.ReadFile("/out/latest/api.md")
+		if err != nil {
+			t.Fatalf("expected the duplicate to resolve through its symlink: %v", err)
+		}
+		if !reflect.DeepEqual(original, duplicate) {
+			t.Errorf("expected the duplicate to link to the original")
+		}
+
+		entries, err := fs.ReadDir("/out")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		var names []string
+		for _, entry := range entries {
+			names = append(names, entry.Name())
+		}
+		if expected := []string{"index.md", "latest", "v3"}; !reflect.DeepEqual(names, expected) {
+			t.Errorf("expected %v, got %v", expected, names)
+		}
+	}
+}
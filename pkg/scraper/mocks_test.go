@@ -6,6 +6,7 @@ import (
 	"io"
 	"net/http"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -63,12 +64,14 @@ type MockFileSystem struct {
 	statError     error
 	createdFiles  []string
 	createdDirs   []string
+	symlinks      map[string]string
 }
 
 func NewMockFileSystem() *MockFileSystem {
 	return &MockFileSystem{
 		files:       make(map[string]string),
 		directories: make(map[string]bool),
+		symlinks:    make(map[string]string),
 	}
 }
 
@@ -91,6 +94,15 @@ func (m *MockFileSystem) MkdirAll(path string, perm int) error {
 	return nil
 }
 
+func (m *MockFileSystem) Symlink(oldname, newname string) error {
+	m.symlinks[newname] = oldname
+	return nil
+}
+
+func (m *MockFileSystem) GetSymlinks() map[string]string {
+	return m.symlinks
+}
+
 func (m *MockFileSystem) SetFile(filename, content string) {
 	m.files[filename] = content
 }
@@ -158,8 +170,17 @@ func NewMockLogger() *MockLogger {
 	return &MockLogger{}
 }
 
-func (m *MockLogger) Printf(format string, v ...interface{}) {
-	message := fmt.Sprintf(format, v...)
+func (m *MockLogger) Debug(msg string, args ...interface{}) { m.log("DEBUG", msg, args) }
+func (m *MockLogger) Info(msg string, args ...interface{})  { m.log("INFO", msg, args) }
+func (m *MockLogger) Warn(msg string, args ...interface{})  { m.log("WARN", msg, args) }
+func (m *MockLogger) Error(msg string, args ...interface{}) { m.log("ERROR", msg, args) }
+
+// log records "LEVEL msg key=value ..." so tests can match on attributes
+func (m *MockLogger) log(level, msg string, args []interface{}) {
+	message := level + " " + msg
+	for i := 0; i+1 < len(args); i += 2 {
+		message += fmt.Sprintf(" %v=%v", args[i], args[i+1])
+	}
 	m.messages = append(m.messages, message)
 }
 
@@ -176,4 +197,33 @@ func (m *MockLogger) GetLastMessage() string {
 
 func (m *MockLogger) Clear() {
 	m.messages = nil
-}
\ No newline at end of file
+}
+type MockChunkWriter struct {
+	mu        sync.Mutex
+	documents map[string]string // file path -> source URL
+	err       error
+}
+
+func NewMockChunkWriter() *MockChunkWriter {
+	return &MockChunkWriter{documents: make(map[string]string)}
+}
+
+func (m *MockChunkWriter) WriteDocument(sourceURL, filePath, markdown string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.err != nil {
+		return m.err
+	}
+	m.documents[filePath] = sourceURL
+	return nil
+}
+
+func (m *MockChunkWriter) SetError(err error) {
+	m.err = err
+}
+
+func (m *MockChunkWriter) GetDocuments() map[string]string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.documents
+}
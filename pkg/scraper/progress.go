@@ -0,0 +1,245 @@
+package scraper
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Progress tracks a scrape run: how many URLs are queued, in flight, done or
+// failed, how much was downloaded, and the same counts per host
+type Progress struct {
+	mu       sync.Mutex
+	now      func() time.Time
+	started  time.Time
+	total    int
+	inFlight int
+	done     int
+	failed   int
+	retries  int
+	bytes    int64
+	hosts    map[string]*HostStatus
+}
+
+// HostStatus is the progress of the URLs on a single host
+type HostStatus struct {
+	Host      string
+	InFlight  int
+	Done      int
+	Failed    int
+	Bytes     int64
+	LastError string
+}
+
+// ProgressSnapshot is a consistent view of a run at one point in time
+type ProgressSnapshot struct {
+	Total          int
+	Queued         int
+	InFlight       int
+	Done           int
+	Failed         int
+	Retries        int
+	Bytes          int64
+	Elapsed        time.Duration
+	PagesPerSecond float64
+	BytesPerSecond float64
+	ETA            time.Duration // zero until the first URL finished
+	Hosts          []HostStatus  // sorted by host
+}
+
+// NewProgress creates an empty progress tracker
+func NewProgress() *Progress {
+	return &Progress{
+		now:   time.Now,
+		hosts: make(map[string]*HostStatus),
+	}
+}
+
+func (p *Progress) start(total int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.started = p.now()
+	p.total = total
+}
+
+func (p *Progress) begin(rawURL string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.inFlight++
+	p.host(rawURL).InFlight++
+}
+
+func (p *Progress) finish(rawURL string, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	host := p.host(rawURL)
+	p.inFlight--
+	host.InFlight--
+	if err != nil {
+		p.failed++
+		host.Failed++
+		host.LastError = err.Error()
+		return
+	}
+	p.done++
+	host.Done++
+}
+
+func (p *Progress) retry() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.retries++
+}
+
+func (p *Progress) addBytes(rawURL string, n int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.bytes += int64(n)
+	p.host(rawURL).Bytes += int64(n)
+}
+
+// host returns the status for a URL's host; callers hold p.mu
+func (p *Progress) host(rawURL string) *HostStatus {
+	name := rawURL
+	if parsed, err := url.Parse(rawURL); err == nil && parsed.Host != "" {
+		name = parsed.Host
+	}
+
+	status, ok := p.hosts[name]
+	if !ok {
+		status = &HostStatus{Host: name}
+		p.hosts[name] = status
+	}
+	return status
+}
+
+// Snapshot returns the current counts along with throughput and ETA
+func (p *Progress) Snapshot() ProgressSnapshot {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	snapshot := ProgressSnapshot{
+		Total:    p.total,
+		Queued:   p.total - p.inFlight - p.done - p.failed,
+		InFlight: p.inFlight,
+		Done:     p.done,
+		Failed:   p.failed,
+		Retries:  p.retries,
+		Bytes:    p.bytes,
+	}
+	if !p.started.IsZero() {
+		snapshot.Elapsed = p.now().Sub(p.started)
+	}
+
+	finished := p.done + p.failed
+	if seconds := snapshot.Elapsed.Seconds(); seconds > 0 {
+		snapshot.PagesPerSecond = float64(finished) / seconds
+		snapshot.BytesPerSecond = float64(p.bytes) / seconds
+	}
+	if finished > 0 {
+		remaining := p.total - finished
+		snapshot.ETA = time.Duration(float64(snapshot.Elapsed) / float64(finished) * float64(remaining))
+	}
+
+	for _, host := range p.hosts {
+		snapshot.Hosts = append(snapshot.Hosts, *host)
+	}
+	sort.Slice(snapshot.Hosts, func(i, j int) bool {
+		return snapshot.Hosts[i].Host < snapshot.Hosts[j].Host
+	})
+
+	return snapshot
+}
+
+// String summarizes the snapshot on one line
+func (s ProgressSnapshot) String() string {
+	finished := s.Done + s.Failed
+	percent := 0
+	if s.Total > 0 {
+		percent = finished * 100 / s.Total
+	}
+
+	parts := []string{
+		fmt.Sprintf("%d/%d (%d%%)", finished, s.Total, percent),
+		fmt.Sprintf("%d active", s.InFlight),
+		fmt.Sprintf("%d failed", s.Failed),
+		fmt.Sprintf("%.1f pages/s", s.PagesPerSecond),
+		formatBytes(s.Bytes),
+	}
+	if s.ETA > 0 {
+		parts = append(parts, "ETA "+s.ETA.Round(time.Second).String())
+	}
+	return strings.Join(parts, " | ")
+}
+
+// String summarizes one host's progress
+func (h HostStatus) String() string {
+	line := fmt.Sprintf("%s: %d done, %d failed, %d active, %s", h.Host, h.Done, h.Failed, h.InFlight, formatBytes(h.Bytes))
+	if h.LastError != "" {
+		line += ", last error: " + h.LastError
+	}
+	return line
+}
+
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	value, suffix := float64(n)/unit, "KB"
+	for _, next := range []string{"MB", "GB"} {
+		if value < unit {
+			break
+		}
+		value, suffix = value/unit, next
+	}
+	return fmt.Sprintf("%.1f %s", value, suffix)
+}
+
+// ServeHTTP exposes the run's progress in the Prometheus text format
+func (p *Progress) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s := p.Snapshot()
+
+	var b strings.Builder
+	gauge := func(name, help string, value string) {
+		fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s gauge\n%s %s\n", name, help, name, name, value)
+	}
+	counter := func(name, help string, value string) {
+		fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s counter\n%s %s\n", name, help, name, name, value)
+	}
+
+	gauge("mdify_scrape_urls", "URLs in this run.", strconv.Itoa(s.Total))
+	gauge("mdify_scrape_urls_queued", "URLs waiting for a worker.", strconv.Itoa(s.Queued))
+	gauge("mdify_scrape_urls_in_flight", "URLs being fetched or saved.", strconv.Itoa(s.InFlight))
+	counter("mdify_scrape_urls_done_total", "URLs saved successfully.", strconv.Itoa(s.Done))
+	counter("mdify_scrape_urls_failed_total", "URLs that failed.", strconv.Itoa(s.Failed))
+	counter("mdify_scrape_retries_total", "Request retries.", strconv.Itoa(s.Retries))
+	counter("mdify_scrape_bytes_total", "Response bytes downloaded.", strconv.FormatInt(s.Bytes, 10))
+	gauge("mdify_scrape_eta_seconds", "Estimated seconds until the run finishes.", strconv.FormatFloat(s.ETA.Seconds(), 'f', 0, 64))
+
+	b.WriteString("# HELP mdify_scrape_host_urls_total URLs finished per host, by result.\n")
+	b.WriteString("# TYPE mdify_scrape_host_urls_total counter\n")
+	for _, host := range s.Hosts {
+		fmt.Fprintf(&b, "mdify_scrape_host_urls_total{host=%q,result=\"done\"} %d\n", host.Host, host.Done)
+		fmt.Fprintf(&b, "mdify_scrape_host_urls_total{host=%q,result=\"failed\"} %d\n", host.Host, host.Failed)
+	}
+	b.WriteString("# HELP mdify_scrape_host_in_flight URLs in flight per host.\n")
+	b.WriteString("# TYPE mdify_scrape_host_in_flight gauge\n")
+	for _, host := range s.Hosts {
+		fmt.Fprintf(&b, "mdify_scrape_host_in_flight{host=%q} %d\n", host.Host, host.InFlight)
+	}
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Write([]byte(b.String()))
+}
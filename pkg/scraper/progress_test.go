@@ -0,0 +1,151 @@
+package scraper
+
+import (
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestProgress_Snapshot(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	p := NewProgress()
+	p.now = func() time.Time { return now }
+
+	p.start(4)
+	p.begin("https://a.example.com/1")
+	p.begin("https://b.example.com/1")
+	p.begin("https://a.example.com/2")
+	p.addBytes("https://a.example.com/1", 2048)
+	p.finish("https://a.example.com/1", nil)
+	p.retry()
+	p.finish("https://b.example.com/1", errors.New("HTTP 500"))
+	now = now.Add(10 * time.Second)
+
+	s := p.Snapshot()
+
+	if s.Total != 4 || s.Queued != 1 || s.InFlight != 1 || s.Done != 1 || s.Failed != 1 || s.Retries != 1 {
+		t.Errorf("unexpected counts: %+v", s)
+	}
+	if s.Elapsed != 10*time.Second || s.PagesPerSecond != 0.2 || s.BytesPerSecond != 204.8 {
+		t.Errorf("unexpected throughput: elapsed %v, %v pages/s, %v bytes/s", s.Elapsed, s.PagesPerSecond, s.BytesPerSecond)
+	}
+	if s.ETA != 10*time.Second {
+		t.Errorf("expected ETA 10s, got %v", s.ETA)
+	}
+
+	if len(s.Hosts) != 2 || s.Hosts[0].Host != "a.example.com" || s.Hosts[1].Host != "b.example.com" {
+		t.Fatalf("expected hosts sorted by name, got %+v", s.Hosts)
+	}
+	if a := s.Hosts[0]; a.Done != 1 || a.InFlight != 1 || a.Bytes != 2048 {
+		t.Errorf("unexpected status for a.example.com: %+v", a)
+	}
+	if b := s.Hosts[1]; b.Failed != 1 || b.LastError != "HTTP 500" {
+		t.Errorf("unexpected status for b.example.com: %+v", b)
+	}
+}
+
+func TestProgressSnapshot_String(t *testing.T) {
+	tests := []struct {
+		name     string
+		snapshot ProgressSnapshot
+		expected string
+	}{
+		{
+			name:     "not started",
+			snapshot: ProgressSnapshot{Total: 10},
+			expected: "0/10 (0%) | 0 active | 0 failed | 0.0 pages/s | 0 B",
+		},
+		{
+			name:     "running",
+			snapshot: ProgressSnapshot{Total: 120, Done: 40, Failed: 2, InFlight: 4, PagesPerSecond: 1.25, Bytes: 3 << 20, ETA: 62500 * time.Millisecond},
+			expected: "42/120 (35%) | 4 active | 2 failed | 1.2 pages/s | 3.0 MB | ETA 1m3s",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if line := tt.snapshot.String(); line != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, line)
+			}
+		})
+	}
+}
+
+func TestFormatBytes(t *testing.T) {
+	tests := []struct {
+		bytes    int64
+		expected string
+	}{
+		{bytes: 512, expected: "512 B"},
+		{bytes: 1536, expected: "1.5 KB"},
+		{bytes: 5 << 20, expected: "5.0 MB"},
+		{bytes: 3 << 30, expected: "3.0 GB"},
+	}
+
+	for _, tt := range tests {
+		if result := formatBytes(tt.bytes); result != tt.expected {
+			t.Errorf("formatBytes(%d): expected %q, got %q", tt.bytes, tt.expected, result)
+		}
+	}
+}
+
+func TestScraperService_Progress(t *testing.T) {
+	for _, workers := range []int{1, 3} {
+		client := NewMockHTTPClient()
+		client.SetResponse("https://example.com/good", 200, `<div class="content"><h1>Good</h1></div>`)
+		client.SetResponse("https://example.com/good2", 200, `<div class="content"><h1>Good 2</h1></div>`)
+		client.SetResponse("https://example.com/missing", 404, "Not Found")
+
+		progress := NewProgress()
+		service := NewService(client, NewMockFileSystem(), NewMockSleeper(), NewMockLogger(), Config{Workers: workers, Progress: progress})
+		if service.Progress() != progress {
+			t.Fatal("expected the configured progress tracker to be used")
+		}
+
+		urls := []string{"https://example.com/good", "https://example.com/missing", "https://example.com/good2"}
+		if err := service.ScrapeURLs(urls, ".content", "/tmp/test"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		s := progress.Snapshot()
+		if s.Total != 3 || s.Done != 2 || s.Failed != 1 || s.InFlight != 0 || s.Queued != 0 {
+			t.Errorf("workers=%d: unexpected counts: %+v", workers, s)
+		}
+		if s.Bytes != 82 {
+			t.Errorf("workers=%d: expected 82 bytes downloaded, got %d", workers, s.Bytes)
+		}
+		if len(s.Hosts) != 1 || !strings.Contains(s.Hosts[0].LastError, "404") {
+			t.Errorf("workers=%d: unexpected hosts: %+v", workers, s.Hosts)
+		}
+	}
+}
+
+func TestProgress_ServeHTTP(t *testing.T) {
+	p := NewProgress()
+	p.start(3)
+	p.begin("https://example.com/a")
+	p.addBytes("https://example.com/a", 100)
+	p.finish("https://example.com/a", nil)
+	p.begin("https://example.com/b")
+
+	w := httptest.NewRecorder()
+	p.ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+
+	body := w.Body.String()
+	for _, line := range []string{
+		"# TYPE mdify_scrape_urls gauge",
+		"mdify_scrape_urls 3",
+		"mdify_scrape_urls_queued 1",
+		"mdify_scrape_urls_in_flight 1",
+		"mdify_scrape_urls_done_total 1",
+		"mdify_scrape_bytes_total 100",
+		`mdify_scrape_host_urls_total{host="example.com",result="done"} 1`,
+		`mdify_scrape_host_in_flight{host="example.com"} 1`,
+	} {
+		if !strings.Contains(body, line+"\n") {
+			t.Errorf("expected metrics to contain %q, got:\n%s", line, body)
+		}
+	}
+}
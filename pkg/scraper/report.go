@@ -0,0 +1,77 @@
+package scraper
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+)
+
+// RunReport summarizes a scrape run: what was saved, what failed and which
+// pages were duplicates
+type RunReport struct {
+	Started    time.Time   `json:"started"`
+	Finished   time.Time   `json:"finished"`
+	URLs       int         `json:"urls"`
+	Saved      int         `json:"saved"`
+	Failed     []Failure   `json:"failed"`
+	Duplicates []Duplicate `json:"duplicates"`
+}
+
+// Failure is a URL that could not be scraped or saved
+type Failure struct {
+	URL   string `json:"url"`
+	Error string `json:"error"`
+}
+
+// Write encodes the report as indented JSON
+func (r RunReport) Write(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+	return encoder.Encode(r)
+}
+
+// Report returns the report of the last scrape run
+func (s *Service) Report() RunReport {
+	s.reportMu.Lock()
+	defer s.reportMu.Unlock()
+
+	report := s.report
+	report.Failed = append([]Failure{}, s.report.Failed...)
+	report.Duplicates = append([]Duplicate{}, s.report.Duplicates...)
+	return report
+}
+
+// startReport resets the report for a run of total URLs
+func (s *Service) startReport(total int) {
+	s.reportMu.Lock()
+	defer s.reportMu.Unlock()
+
+	s.report = RunReport{Started: time.Now(), URLs: total}
+}
+
+// finishReport stamps the end of the run
+func (s *Service) finishReport() {
+	s.reportMu.Lock()
+	defer s.reportMu.Unlock()
+
+	s.report.Finished = time.Now()
+}
+
+// record adds the outcome of one URL to the report
+func (s *Service) record(rawURL string, duplicate *Duplicate, err error) {
+	s.reportMu.Lock()
+	defer s.reportMu.Unlock()
+
+	if err != nil {
+		s.report.Failed = append(s.report.Failed, Failure{URL: rawURL, Error: err.Error()})
+		return
+	}
+	if duplicate != nil {
+		s.report.Duplicates = append(s.report.Duplicates, *duplicate)
+		if duplicate.Action != DuplicateSaved {
+			return
+		}
+	}
+	s.report.Saved++
+}
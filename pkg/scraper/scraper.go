@@ -23,6 +23,7 @@ type HTTPClient interface {
 type FileSystem interface {
 	Create(name string) (io.WriteCloser, error)
 	MkdirAll(path string, perm int) error
+	Symlink(oldname, newname string) error
 }
 
 // Sleeper interface for time delays
@@ -30,16 +31,39 @@ type Sleeper interface {
 	Sleep(duration time.Duration)
 }
 
-// Logger interface for logging
+// Logger interface for leveled, structured logging; *slog.Logger satisfies it
 type Logger interface {
-	Printf(format string, v ...interface{})
+	Debug(msg string, args ...interface{})
+	Info(msg string, args ...interface{})
+	Warn(msg string, args ...interface{})
+	Error(msg string, args ...interface{})
+}
+
+// ChunkWriter receives every saved document, e.g. to export chunks for RAG
+type ChunkWriter interface {
+	WriteDocument(sourceURL, filePath, markdown string) error
+}
+
+// SourceRecorder is implemented by file systems that store each file's
+// source URL with it, such as a database
+type SourceRecorder interface {
+	RecordSource(path, sourceURL string)
 }
 
 // Config holds configuration for the scraper
 type Config struct {
-	Timeout    time.Duration
-	MaxRetries int
-	Workers    int
+	Timeout       time.Duration
+	MaxRetries    int
+	Workers       int
+	TableFallback string
+	Exclude       []string      // CSS selectors removed from the extracted content
+	RateLimit     time.Duration // minimum delay between requests across all workers
+	Progress      *Progress     // tracks the run; NewService creates one when nil
+	Chunks        ChunkWriter   // optional; receives each saved document
+	Dedup         string        // what to do with duplicate pages: off, record, skip or symlink
+	// NearDuplicateBits is how many simhash bits two pages may differ by and
+	// still be near duplicates; negative disables near-duplicate detection
+	NearDuplicateBits int
 }
 
 // Service provides web scraping functionality
@@ -50,6 +74,14 @@ type Service struct {
 	sleeper   Sleeper
 	logger    Logger
 	config    Config
+	progress  *Progress
+
+	rateMu      sync.Mutex
+	lastRequest time.Time
+
+	dedup    *dedupIndex // nil unless deduplicating
+	reportMu sync.Mutex
+	report   RunReport
 }
 
 // Job represents a scraping job
@@ -65,11 +97,18 @@ type Result struct {
 	Success     bool
 	Error       error
 	OutputPath  string
+	Duplicate   *Duplicate
 }
 
 // NewService creates a new scraper service
 func NewService(client HTTPClient, fs FileSystem, sleeper Sleeper, logger Logger, config Config) *Service {
 	converter := md.NewConverter("", true, nil)
+	converter.Use(CodeBlocks(), Tables(config.TableFallback))
+
+	progress := config.Progress
+	if progress == nil {
+		progress = NewProgress()
+	}
 	
 	return &Service{
 		client:    client,
@@ -78,9 +117,15 @@ func NewService(client HTTPClient, fs FileSystem, sleeper Sleeper, logger Logger
 		sleeper:   sleeper,
 		logger:    logger,
 		config:    config,
+		progress:  progress,
 	}
 }
 
+// Progress returns the tracker for the service's scrape runs
+func (s *Service) Progress() *Progress {
+	return s.progress
+}
+
 // FetchWithRetries fetches a URL with retry logic and exponential backoff
 func (s *Service) FetchWithRetries(url string) (*http.Response, error) {
 	var lastErr error
@@ -88,10 +133,12 @@ func (s *Service) FetchWithRetries(url string) (*http.Response, error) {
 	for attempt := 0; attempt <= s.config.MaxRetries; attempt++ {
 		if attempt > 0 {
 			backoffDuration := time.Duration(1<<uint(attempt-1)) * time.Second
-			s.logger.Printf("Retrying %s in %v (attempt %d/%d)", url, backoffDuration, attempt+1, s.config.MaxRetries+1)
+			s.logger.Warn("Retrying", "url", url, "delay", backoffDuration, "attempt", attempt+1, "max_attempts", s.config.MaxRetries+1)
+			s.progress.retry()
 			s.sleeper.Sleep(backoffDuration)
 		}
 
+		s.throttle()
 		resp, err := s.client.Get(url)
 		if err != nil {
 			lastErr = err
@@ -114,51 +161,108 @@ func (s *Service) FetchWithRetries(url string) (*http.Response, error) {
 	return nil, fmt.Errorf("failed after %d retries: %w", s.config.MaxRetries+1, lastErr)
 }
 
+// throttle waits until the configured rate limit allows another request
+func (s *Service) throttle() {
+	if s.config.RateLimit <= 0 {
+		return
+	}
+
+	s.rateMu.Lock()
+	defer s.rateMu.Unlock()
+
+	if !s.lastRequest.IsZero() {
+		if wait := s.config.RateLimit - time.Since(s.lastRequest); wait > 0 {
+			s.sleeper.Sleep(wait)
+		}
+	}
+	s.lastRequest = time.Now()
+}
+
 // ExtractContent extracts content from HTML using a CSS selector and converts to markdown
 func (s *Service) ExtractContent(htmlContent, selector string) (string, error) {
+	markdown, _, err := s.extract(htmlContent, selector)
+	return markdown, err
+}
+
+// extract converts the selected content to markdown and returns the page's
+// canonical link alongside it
+func (s *Service) extract(htmlContent, selector string) (string, string, error) {
 	doc, err := goquery.NewDocumentFromReader(strings.NewReader(htmlContent))
 	if err != nil {
-		return "", fmt.Errorf("failed to parse HTML: %w", err)
+		return "", "", fmt.Errorf("failed to parse HTML: %w", err)
 	}
 
+	canonical, _ := doc.Find(`link[rel~="canonical"]`).First().Attr("href")
+
 	selection := doc.Find(selector)
 	if selection.Length() == 0 {
-		return "", fmt.Errorf("selector '%s' matched no elements", selector)
+		return "", "", fmt.Errorf("selector '%s' matched no elements", selector)
+	}
+
+	for _, exclude := range s.config.Exclude {
+		selection.Find(exclude).Remove()
 	}
 
 	html, err := selection.Html()
 	if err != nil {
-		return "", fmt.Errorf("failed to extract HTML: %w", err)
+		return "", "", fmt.Errorf("failed to extract HTML: %w", err)
 	}
 
 	markdown, err := s.converter.ConvertString(html)
 	if err != nil {
-		return "", fmt.Errorf("failed to convert to markdown: %w", err)
+		return "", "", fmt.Errorf("failed to convert to markdown: %w", err)
 	}
 
-	return markdown, nil
+	return markdown, canonical, nil
 }
 
 // ScrapeURL scrapes a single URL and returns the markdown content
 func (s *Service) ScrapeURL(rawURL, selector string) (string, error) {
-	s.logger.Printf("Scraping: %s", rawURL)
+	markdown, _, err := s.scrape(rawURL, selector)
+	return markdown, err
+}
+
+// scrape fetches and converts a URL, returning the markdown and the
+// resolved canonical URL if it names a different page
+func (s *Service) scrape(rawURL, selector string) (string, string, error) {
+	s.logger.Debug("Scraping", "url", rawURL)
 
 	resp, err := s.FetchWithRetries(rawURL)
 	if err != nil {
-		return "", err
+		return "", "", err
 	}
 	defer resp.Body.Close()
 
 	htmlBytes, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return "", fmt.Errorf("failed to read response body: %w", err)
+		return "", "", fmt.Errorf("failed to read response body: %w", err)
 	}
+	s.progress.addBytes(rawURL, len(htmlBytes))
 
-	return s.ExtractContent(string(htmlBytes), selector)
+	markdown, href, err := s.extract(string(htmlBytes), selector)
+	if err != nil {
+		return "", "", err
+	}
+	return markdown, resolveCanonical(rawURL, href), nil
 }
 
 // GetOutputPath determines the output file path for a URL
 func (s *Service) GetOutputPath(rawURL, baseDir string) (string, error) {
+	outputPath, err := s.outputPath(rawURL, baseDir)
+	if err != nil {
+		return "", err
+	}
+	
+	dir := filepath.Dir(outputPath)
+	if err := s.fs.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create directory %s: %w", dir, err)
+	}
+
+	return outputPath, nil
+}
+
+// outputPath maps a URL to its file below baseDir
+func (s *Service) outputPath(rawURL, baseDir string) (string, error) {
 	parsedURL, err := url.Parse(rawURL)
 	if err != nil {
 		return "", fmt.Errorf("invalid URL %s: %w", rawURL, err)
@@ -173,14 +277,7 @@ func (s *Service) GetOutputPath(rawURL, baseDir string) (string, error) {
 		urlPath += ".md"
 	}
 
-	outputPath := filepath.Join(baseDir, urlPath)
-	
-	dir := filepath.Dir(outputPath)
-	if err := s.fs.MkdirAll(dir, 0755); err != nil {
-		return "", fmt.Errorf("failed to create directory %s: %w", dir, err)
-	}
-
-	return outputPath, nil
+	return filepath.Join(baseDir, urlPath), nil
 }
 
 // SaveMarkdown saves markdown content to a file
@@ -189,18 +286,31 @@ func (s *Service) SaveMarkdown(content, filePath string) error {
 	if err != nil {
 		return fmt.Errorf("failed to create file %s: %w", filePath, err)
 	}
-	defer file.Close()
 
 	_, err = io.WriteString(file, content)
 	if err != nil {
+		file.Close()
 		return fmt.Errorf("failed to write to file %s: %w", filePath, err)
 	}
 
+	// archives and databases only write the entry when it is closed
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", filePath, err)
+	}
 	return nil
 }
 
 // ScrapeURLs scrapes multiple URLs either sequentially or concurrently
 func (s *Service) ScrapeURLs(urls []string, selector, output string) error {
+	s.progress.start(len(urls))
+	s.startReport(len(urls))
+	defer s.finishReport()
+
+	s.dedup = nil
+	if s.config.Dedup != "" && s.config.Dedup != DedupOff {
+		s.dedup = newDedupIndex()
+	}
+
 	if s.config.Workers <= 1 {
 		return s.scrapeSequential(urls, selector, output)
 	}
@@ -210,33 +320,76 @@ func (s *Service) ScrapeURLs(urls []string, selector, output string) error {
 func (s *Service) scrapeSequential(urls []string, selector, output string) error {
 	successCount := 0
 	errorCount := 0
+	duplicateCount := 0
+
+	fail := func(rawURL string, err error) {
+		s.progress.finish(rawURL, err)
+		s.record(rawURL, nil, err)
+		errorCount++
+	}
 
 	for _, rawURL := range urls {
-		markdown, err := s.ScrapeURL(rawURL, selector)
+		s.progress.begin(rawURL)
+
+		markdown, canonical, err := s.scrape(rawURL, selector)
 		if err != nil {
-			s.logger.Printf("Error scraping %s: %v", rawURL, err)
-			errorCount++
+			s.logger.Error("Scrape failed", "url", rawURL, "error", err)
+			fail(rawURL, err)
 			continue
 		}
 
+		duplicate, err := s.dedupe(rawURL, canonical, output, markdown)
+		if err != nil {
+			s.logger.Error("Failed to determine output path", "url", rawURL, "error", err)
+			fail(rawURL, err)
+			continue
+		}
+		if duplicate != nil {
+			duplicateCount++
+			s.logDuplicate(duplicate)
+			if duplicate.Action != DuplicateSaved {
+				if err := s.linkDuplicate(duplicate); err != nil {
+					s.logger.Error("Failed to link duplicate", "url", rawURL, "error", err)
+					fail(rawURL, err)
+					continue
+				}
+				s.progress.finish(rawURL, nil)
+				s.record(rawURL, duplicate, nil)
+				successCount++
+				continue
+			}
+		}
+
 		outputPath, err := s.GetOutputPath(rawURL, output)
 		if err != nil {
-			s.logger.Printf("Error determining output path for %s: %v", rawURL, err)
-			errorCount++
+			s.logger.Error("Failed to determine output path", "url", rawURL, "error", err)
+			s.release(rawURL)
+			fail(rawURL, err)
 			continue
 		}
 
+		s.recordSource(outputPath, rawURL)
 		if err := s.SaveMarkdown(markdown, outputPath); err != nil {
-			s.logger.Printf("Error saving %s: %v", outputPath, err)
-			errorCount++
+			s.logger.Error("Failed to save", "url", rawURL, "path", outputPath, "error", err)
+			s.release(rawURL)
+			fail(rawURL, err)
 			continue
 		}
+		s.markSaved(rawURL, outputPath)
 
-		s.logger.Printf("✓ Saved: %s", outputPath)
+		if err := s.writeChunks(rawURL, output, outputPath, markdown); err != nil {
+			s.logger.Error("Failed to write chunks", "url", rawURL, "error", err)
+			fail(rawURL, err)
+			continue
+		}
+
+		s.logger.Info("Saved", "url", rawURL, "path", outputPath)
+		s.progress.finish(rawURL, nil)
+		s.record(rawURL, duplicate, nil)
 		successCount++
 	}
 
-	s.logger.Printf("Completed: %d successful, %d errors", successCount, errorCount)
+	s.logger.Info("Completed", "successful", successCount, "errors", errorCount, "duplicates", duplicateCount)
 	return nil
 }
 
@@ -246,7 +399,7 @@ func (s *Service) scrapeConcurrent(urls []string, selector, output string) error
 		numWorkers = len(urls)
 	}
 
-	s.logger.Printf("Starting %d workers to process %d URLs", numWorkers, len(urls))
+	s.logger.Info("Starting workers", "workers", numWorkers, "urls", len(urls))
 
 	// Create channels
 	jobs := make(chan Job, len(urls))
@@ -278,17 +431,29 @@ func (s *Service) scrapeConcurrent(urls []string, selector, output string) error
 	// Collect results
 	successCount := 0
 	errorCount := 0
+	duplicateCount := 0
 	for result := range results {
-		if result.Success {
-			s.logger.Printf("✓ Saved: %s", result.OutputPath)
-			successCount++
-		} else {
-			s.logger.Printf("Error scraping %s: %v", result.URL, result.Error)
+		if result.Success && result.Duplicate != nil {
+			s.logDuplicate(result.Duplicate)
+			duplicateCount++
+		}
+
+		switch {
+		case !result.Success:
+			s.logger.Error("Scrape failed", "url", result.URL, "error", result.Error)
+			s.record(result.URL, nil, result.Error)
 			errorCount++
+		case result.OutputPath != "":
+			s.logger.Info("Saved", "url", result.URL, "path", result.OutputPath)
+			s.record(result.URL, result.Duplicate, nil)
+			successCount++
+		default:
+			s.record(result.URL, result.Duplicate, nil)
+			successCount++
 		}
 	}
 
-	s.logger.Printf("Completed: %d successful, %d errors", successCount, errorCount)
+	s.logger.Info("Completed", "successful", successCount, "errors", errorCount, "duplicates", duplicateCount)
 	return nil
 }
 
@@ -296,35 +461,88 @@ func (s *Service) worker(id int, jobs <-chan Job, results chan<- Result, wg *syn
 	defer wg.Done()
 
 	for job := range jobs {
-		result := Result{
-			URL: job.URL,
-		}
+		s.progress.begin(job.URL)
+		result := s.process(job)
+		s.progress.finish(job.URL, result.Error)
+		results <- result
+	}
+}
 
-		markdown, err := s.ScrapeURL(job.URL, job.Selector)
-		if err != nil {
-			result.Success = false
-			result.Error = err
-			results <- result
-			continue
-		}
+// process scrapes a single job and saves the markdown
+func (s *Service) process(job Job) Result {
+	result := Result{
+		URL: job.URL,
+	}
 
-		outputPath, err := s.GetOutputPath(job.URL, job.Output)
-		if err != nil {
-			result.Success = false
-			result.Error = fmt.Errorf("error determining output path: %w", err)
-			results <- result
-			continue
-		}
+	markdown, canonical, err := s.scrape(job.URL, job.Selector)
+	if err != nil {
+		result.Success = false
+		result.Error = err
+		return result
+	}
 
-		if err := s.SaveMarkdown(markdown, outputPath); err != nil {
+	duplicate, err := s.dedupe(job.URL, canonical, job.Output, markdown)
+	if err != nil {
+		result.Success = false
+		result.Error = fmt.Errorf("error determining output path: %w", err)
+		return result
+	}
+	result.Duplicate = duplicate
+	if duplicate != nil && duplicate.Action != DuplicateSaved {
+		if err := s.linkDuplicate(duplicate); err != nil {
 			result.Success = false
-			result.Error = fmt.Errorf("error saving file: %w", err)
-			results <- result
-			continue
+			result.Error = fmt.Errorf("error linking duplicate: %w", err)
+			return result
 		}
-
 		result.Success = true
-		result.OutputPath = outputPath
-		results <- result
+		return result
+	}
+
+	outputPath, err := s.GetOutputPath(job.URL, job.Output)
+	if err != nil {
+		s.release(job.URL)
+		result.Success = false
+		result.Error = fmt.Errorf("error determining output path: %w", err)
+		return result
+	}
+
+	s.recordSource(outputPath, job.URL)
+	if err := s.SaveMarkdown(markdown, outputPath); err != nil {
+		s.release(job.URL)
+		result.Success = false
+		result.Error = fmt.Errorf("error saving file: %w", err)
+		return result
 	}
-}
\ No newline at end of file
+	s.markSaved(job.URL, outputPath)
+
+	if err := s.writeChunks(job.URL, job.Output, outputPath, markdown); err != nil {
+		result.Success = false
+		result.Error = fmt.Errorf("error writing chunks: %w", err)
+		return result
+	}
+
+	result.Success = true
+	result.OutputPath = outputPath
+	return result
+}
+
+// recordSource tells file systems that keep metadata where a file came from
+func (s *Service) recordSource(outputPath, rawURL string) {
+	if recorder, ok := s.fs.(SourceRecorder); ok {
+		recorder.RecordSource(outputPath, rawURL)
+	}
+}
+
+// writeChunks passes a saved document to the chunk writer, identified by its
+// path relative to the output directory
+func (s *Service) writeChunks(rawURL, output, outputPath, markdown string) error {
+	if s.config.Chunks == nil {
+		return nil
+	}
+
+	rel, err := filepath.Rel(output, outputPath)
+	if err != nil {
+		rel = outputPath
+	}
+	return s.config.Chunks.WriteDocument(rawURL, filepath.ToSlash(rel), markdown)
+}
@@ -1,10 +1,15 @@
 package scraper
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"reflect"
 	"strings"
 	"testing"
 	"time"
+
+	"mdify/internal/filesystem"
 )
 
 func TestScraperService_ExtractContent(t *testing.T) {
@@ -256,6 +261,26 @@ func TestScraperService_SaveMarkdown(t *testing.T) {
 	}
 }
 
+// nopCloser lets a buffer stand in for an archive's output file
+type nopCloser struct{ *bytes.Buffer }
+
+func (nopCloser) Close() error { return nil }
+
+func TestScraperService_SaveMarkdown_ArchiveClose(t *testing.T) {
+	archive := filesystem.NewZipArchive(nopCloser{&bytes.Buffer{}})
+	scraper := NewService(nil, archive, nil, nil, Config{})
+
+	if err := scraper.SaveMarkdown("# First", "docs/page.md"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// the entry is only written when the file is closed, where the
+	// duplicate name is rejected
+	err := scraper.SaveMarkdown("# Second", "docs/page.md")
+	if err == nil || !strings.Contains(err.Error(), "already in the archive") {
+		t.Errorf("expected the archive's close error, got %v", err)
+	}
+}
+
 func TestScraperService_ScrapeURL(t *testing.T) {
 	t.Run("successful scrape", func(t *testing.T) {
 		client := NewMockHTTPClient()
@@ -274,7 +299,7 @@ func TestScraperService_ScrapeURL(t *testing.T) {
 		}
 		
 		messages := logger.GetMessages()
-		if len(messages) == 0 || !strings.Contains(messages[0], "Scraping: https://example.com") {
+		if len(messages) == 0 || !strings.Contains(messages[0], "DEBUG Scraping url=https://example.com") {
 			t.Errorf("expected scraping log message")
 		}
 	})
@@ -396,9 +421,9 @@ func TestScraperService_ConcurrentScraping(t *testing.T) {
 			errorCount := 0
 
 			for _, msg := range messages {
-				if strings.Contains(msg, "✓ Saved:") {
+				if strings.Contains(msg, "INFO Saved url=") {
 					successCount++
-				} else if strings.Contains(msg, "Error scraping") {
+				} else if strings.Contains(msg, "ERROR Scrape failed url=") {
 					errorCount++
 				}
 			}
@@ -431,4 +456,108 @@ func TestScraperService_ConcurrentScraping(t *testing.T) {
 			}
 		})
 	}
-}
\ No newline at end of file
+}
+func TestScraperService_Exclude(t *testing.T) {
+	scraper := NewService(nil, nil, nil, nil, Config{Exclude: []string{".edit-link", "nav"}})
+
+	html := `<div class="content"><nav>Menu</nav><h1>Title</h1><a class="edit-link" href="/edit">Edit this page</a><p>Body</p></div>`
+	result, err := scraper.ExtractContent(html, ".content")
+
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if !strings.Contains(result, "# Title") || !strings.Contains(result, "Body") {
+		t.Errorf("expected content to be kept, got %q", result)
+	}
+	if strings.Contains(result, "Menu") || strings.Contains(result, "Edit this page") {
+		t.Errorf("expected excluded elements to be removed, got %q", result)
+	}
+}
+
+func TestScraperService_RateLimit(t *testing.T) {
+	client := NewMockHTTPClient()
+	client.SetResponse("https://example.com/1", 200, "one")
+	client.SetResponse("https://example.com/2", 200, "two")
+
+	sleeper := NewMockSleeper()
+	scraper := NewService(client, nil, sleeper, NewMockLogger(), Config{RateLimit: time.Minute})
+
+	for _, url := range []string{"https://example.com/1", "https://example.com/2"} {
+		if _, err := scraper.FetchWithRetries(url); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	sleeps := sleeper.GetSleepDurations()
+	if len(sleeps) != 1 {
+		t.Fatalf("expected 1 sleep between requests, got %d", len(sleeps))
+	}
+	if sleeps[0] <= 0 || sleeps[0] > time.Minute {
+		t.Errorf("expected sleep up to the rate limit, got %v", sleeps[0])
+	}
+}
+
+func TestScraperService_Chunks(t *testing.T) {
+	tests := []struct {
+		name          string
+		workers       int
+		chunkErr      error
+		expectedDocs  map[string]string
+		expectedError bool
+	}{
+		{
+			name:    "sequential",
+			workers: 1,
+			expectedDocs: map[string]string{
+				"docs/api.md": "https://example.com/docs/api",
+				"index.md":    "https://example.com/",
+			},
+		},
+		{
+			name:    "concurrent",
+			workers: 2,
+			expectedDocs: map[string]string{
+				"docs/api.md": "https://example.com/docs/api",
+				"index.md":    "https://example.com/",
+			},
+		},
+		{
+			name:          "writer error fails the page",
+			workers:       1,
+			chunkErr:      errors.New("disk full"),
+			expectedDocs:  map[string]string{},
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewMockHTTPClient()
+			client.SetResponse("https://example.com/docs/api", 200, `<div class="content"><h1>API</h1></div>`)
+			client.SetResponse("https://example.com/", 200, `<div class="content"><h1>Home</h1></div>`)
+
+			chunks := NewMockChunkWriter()
+			chunks.SetError(tt.chunkErr)
+			logger := NewMockLogger()
+			scraper := NewService(client, NewMockFileSystem(), NewMockSleeper(), logger, Config{Workers: tt.workers, Chunks: chunks})
+
+			if err := scraper.ScrapeURLs([]string{"https://example.com/docs/api", "https://example.com/"}, ".content", "/tmp/output"); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if docs := chunks.GetDocuments(); !reflect.DeepEqual(docs, tt.expectedDocs) {
+				t.Errorf("expected chunked documents %v, got %v", tt.expectedDocs, docs)
+			}
+
+			failed := false
+			for _, message := range logger.GetMessages() {
+				if strings.Contains(message, "ERROR Failed to write chunks") {
+					failed = true
+				}
+			}
+			if failed != tt.expectedError {
+				t.Errorf("expected chunk error logged: %v, got messages %v", tt.expectedError, logger.GetMessages())
+			}
+		})
+	}
+}
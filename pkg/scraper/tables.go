@@ -0,0 +1,305 @@
+package scraper
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	md "github.com/JohannesKaufmann/html-to-markdown"
+	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/net/html"
+)
+
+// Table fallback modes for tables that can't be expressed as GFM tables
+const (
+	TableFallbackHTML = "html"
+	TableFallbackList = "list"
+)
+
+// blockSelectors match cell content that a GFM table cell can't hold
+const blockSelectors = "table, ul, ol, pre, blockquote, h1, h2, h3, h4, h5, h6, hr, dl"
+
+// keptTableAttributes are preserved when a table falls back to HTML
+var keptTableAttributes = map[string]bool{
+	"colspan": true,
+	"rowspan": true,
+	"align":   true,
+	"scope":   true,
+}
+
+var whitespaceRegex = regexp.MustCompile(`\s+`)
+
+// Tables is a converter plugin that renders simple tables as GitHub-flavored
+// markdown tables. Tables with spanning cells or block content are rendered
+// using the fallback mode, either as cleaned HTML or as a nested list.
+func Tables(fallback string) md.Plugin {
+	return func(c *md.Converter) []md.Rule {
+		return []md.Rule{
+			{
+				Filter: []string{"table"},
+				Replacement: func(content string, selec *goquery.Selection, opt *md.Options) *string {
+					var text string
+					switch {
+					case selec.Find("tr").Length() == 0:
+						text = content
+					case !isComplexTable(selec):
+						text = gfmTable(c, selec)
+					case fallback == TableFallbackList:
+						text = listTable(c, selec)
+					default:
+						text = htmlTable(selec)
+					}
+
+					if caption := strings.TrimSpace(selec.ChildrenFiltered("caption").Text()); caption != "" {
+						text = "**" + whitespaceRegex.ReplaceAllString(caption, " ") + "**\n\n" + text
+					}
+
+					text = "\n\n" + text + "\n\n"
+					return &text
+				},
+			},
+		}
+	}
+}
+
+// tableRows returns the rows that belong to this table, not to nested tables
+func tableRows(table *goquery.Selection) []*goquery.Selection {
+	var rows []*goquery.Selection
+	table.Find("tr").Each(func(i int, row *goquery.Selection) {
+		if row.ParentsFiltered("table").First().IsSelection(table) {
+			rows = append(rows, row)
+		}
+	})
+	return rows
+}
+
+func isComplexTable(table *goquery.Selection) bool {
+	for _, row := range tableRows(table) {
+		complex := false
+		row.ChildrenFiltered("td, th").EachWithBreak(func(i int, cell *goquery.Selection) bool {
+			if span(cell, "colspan") > 1 || span(cell, "rowspan") > 1 {
+				complex = true
+			} else if cell.Find(blockSelectors).Length() > 0 || cell.ChildrenFiltered("p").Length() > 1 {
+				complex = true
+			}
+			return !complex
+		})
+		if complex {
+			return true
+		}
+	}
+	return false
+}
+
+func span(cell *goquery.Selection, attr string) int {
+	n, err := strconv.Atoi(strings.TrimSpace(cell.AttrOr(attr, "1")))
+	if err != nil || n < 1 {
+		return 1
+	}
+	return n
+}
+
+// headerRowCount returns how many leading rows are header rows. Rows inside
+// <thead> count, and so does a first row made only of <th> cells.
+func headerRowCount(rows []*goquery.Selection) int {
+	count := 0
+	for _, row := range rows {
+		if row.ParentsFiltered("thead").Length() == 0 {
+			break
+		}
+		count++
+	}
+	if count > 0 || len(rows) == 0 {
+		return count
+	}
+
+	cells := rows[0].ChildrenFiltered("td, th")
+	if cells.Length() > 0 && cells.Length() == cells.Filter("th").Length() {
+		return 1
+	}
+	return 0
+}
+
+// expandGrid lays the table out as a rectangular grid of cells, repeating
+// cells that span multiple rows or columns
+func expandGrid(rows []*goquery.Selection) [][]*goquery.Selection {
+	grid := make([][]*goquery.Selection, len(rows))
+
+	for r, row := range rows {
+		col := 0
+		row.ChildrenFiltered("td, th").Each(func(i int, cell *goquery.Selection) {
+			for col < len(grid[r]) && grid[r][col] != nil {
+				col++
+			}
+			for dr := 0; dr < span(cell, "rowspan") && r+dr < len(rows); dr++ {
+				for dc := 0; dc < span(cell, "colspan"); dc++ {
+					target := col + dc
+					for len(grid[r+dr]) <= target {
+						grid[r+dr] = append(grid[r+dr], nil)
+					}
+					grid[r+dr][target] = cell
+				}
+			}
+			col += span(cell, "colspan")
+		})
+	}
+
+	return grid
+}
+
+func gfmTable(c *md.Converter, table *goquery.Selection) string {
+	rows := tableRows(table)
+	headerRows := headerRowCount(rows)
+	grid := expandGrid(rows)
+
+	width := 0
+	for _, row := range grid {
+		width = max(width, len(row))
+	}
+
+	renderRow := func(cells []string) string {
+		for len(cells) < width {
+			cells = append(cells, "")
+		}
+		return "| " + strings.Join(cells, " | ") + " |"
+	}
+
+	var header []string
+	var alignRow []*goquery.Selection
+	if headerRows > 0 {
+		for _, cell := range grid[0] {
+			header = append(header, cellMarkdown(c, cell))
+		}
+		alignRow = grid[0]
+	} else if len(grid) > 0 {
+		alignRow = grid[0]
+	}
+
+	dividers := make([]string, width)
+	for i := range dividers {
+		dividers[i] = "---"
+		if i < len(alignRow) && alignRow[i] != nil {
+			dividers[i] = alignmentDivider(alignRow[i])
+		}
+	}
+
+	lines := []string{renderRow(header), renderRow(dividers)}
+	// further header rows have nowhere to go in GFM, so they become body rows
+	for _, row := range grid[min(headerRows, 1):] {
+		var cells []string
+		for _, cell := range row {
+			cells = append(cells, cellMarkdown(c, cell))
+		}
+		lines = append(lines, renderRow(cells))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func alignmentDivider(cell *goquery.Selection) string {
+	align := strings.ToLower(cell.AttrOr("align", ""))
+	if align == "" {
+		style := strings.ToLower(strings.ReplaceAll(cell.AttrOr("style", ""), " ", ""))
+		for _, a := range []string{"left", "right", "center"} {
+			if strings.Contains(style, "text-align:"+a) {
+				align = a
+			}
+		}
+	}
+
+	switch align {
+	case "left":
+		return ":---"
+	case "right":
+		return "---:"
+	case "center":
+		return ":---:"
+	}
+	return "---"
+}
+
+// cellMarkdown converts a cell to single-line markdown safe to use inside a
+// GFM table row
+func cellMarkdown(c *md.Converter, cell *goquery.Selection) string {
+	if cell == nil {
+		return ""
+	}
+	text := strings.TrimSpace(c.Convert(cell))
+	// the converter may already have escaped some pipes
+	text = strings.ReplaceAll(strings.ReplaceAll(text, `\|`, "|"), "|", `\|`)
+	text = strings.ReplaceAll(text, "\n\n", "<br>")
+	return whitespaceRegex.ReplaceAllString(text, " ")
+}
+
+// listTable renders each body row as a list item, labelling every value
+// with its column header
+func listTable(c *md.Converter, table *goquery.Selection) string {
+	rows := tableRows(table)
+	headerRows := headerRowCount(rows)
+	grid := expandGrid(rows)
+
+	var headers []string
+	if headerRows > 0 {
+		for _, cell := range grid[headerRows-1] {
+			headers = append(headers, cellMarkdown(c, cell))
+		}
+	}
+
+	var items []string
+	for _, row := range grid[headerRows:] {
+		var lines []string
+		var previous *goquery.Selection
+		for i, cell := range row {
+			// a colspan repeats the same cell across columns; show it once
+			if cell == nil || cell == previous {
+				continue
+			}
+			previous = cell
+
+			value := indent(strings.TrimSpace(c.Convert(cell)), "    ")
+			if value == "" {
+				continue
+			}
+			label := ""
+			if i < len(headers) && headers[i] != "" {
+				label = "**" + headers[i] + ":** "
+			}
+			lines = append(lines, label+value)
+		}
+		if len(lines) == 0 {
+			continue
+		}
+
+		item := "- " + lines[0]
+		for _, line := range lines[1:] {
+			item += "\n  - " + line
+		}
+		items = append(items, item)
+	}
+
+	return strings.Join(items, "\n")
+}
+
+func indent(text, prefix string) string {
+	return strings.ReplaceAll(text, "\n", "\n"+prefix)
+}
+
+// htmlTable returns the table as HTML with presentational attributes removed
+func htmlTable(table *goquery.Selection) string {
+	clone := table.Clone()
+	clone.Find("caption").Remove()
+	clone.Find("*").AddSelection(clone).Each(func(i int, s *goquery.Selection) {
+		attrs := append([]html.Attribute(nil), s.Nodes[0].Attr...)
+		for _, attr := range attrs {
+			if !keptTableAttributes[attr.Key] {
+				s.RemoveAttr(attr.Key)
+			}
+		}
+	})
+
+	out, err := goquery.OuterHtml(clone)
+	if err != nil {
+		return clone.Text()
+	}
+	return out
+}
@@ -0,0 +1,95 @@
+package scraper
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTables_Conversion(t *testing.T) {
+	tests := []struct {
+		name     string
+		html     string
+		fallback string
+		expected []string
+		absent   []string
+	}{
+		{
+			name: "simple table with thead",
+			html: `<table><thead><tr><th>Name</th><th align="right">Type</th></tr></thead>` +
+				`<tbody><tr><td><code>id</code></td><td>int</td></tr><tr><td>name</td><td>string | null</td></tr></tbody></table>`,
+			expected: []string{
+				"| Name | Type |\n| --- | ---: |\n| `id` | int |\n| name | string \\| null |",
+			},
+		},
+		{
+			name: "header row without thead",
+			html: `<table><tr><th>Option</th><th>Default</th></tr><tr><td>port</td><td>8080</td></tr></table>`,
+			expected: []string{
+				"| Option | Default |\n| --- | --- |\n| port | 8080 |",
+			},
+		},
+		{
+			name: "table without header",
+			html: `<table><tr><td>a</td><td>b</td></tr></table>`,
+			expected: []string{
+				"|  |  |\n| --- | --- |\n| a | b |",
+			},
+		},
+		{
+			name: "caption",
+			html: `<table><caption>Limits</caption><tr><th>Plan</th></tr><tr><td>Free</td></tr></table>`,
+			expected: []string{
+				"**Limits**\n\n| Plan |",
+			},
+		},
+		{
+			name: "colspan falls back to html",
+			html: `<table class="api" style="width:100%"><tr><th colspan="2">Params</th></tr><tr><td>a</td><td>b</td></tr></table>`,
+			expected: []string{
+				`<table><tbody><tr><th colspan="2">Params</th></tr>`,
+			},
+			absent: []string{"class=", "style="},
+		},
+		{
+			name:     "block content falls back to list",
+			fallback: TableFallbackList,
+			html: `<table><thead><tr><th>Field</th><th>Description</th></tr></thead>` +
+				`<tbody><tr><td>items</td><td><p>A list.</p><ul><li>one</li><li>two</li></ul></td></tr></tbody></table>`,
+			expected: []string{
+				"- **Field:** items\n  - **Description:** A list.",
+				"    - one",
+			},
+		},
+		{
+			name:     "rowspan falls back to list",
+			fallback: TableFallbackList,
+			html: `<table><tr><th>Group</th><th>Item</th></tr>` +
+				`<tr><td rowspan="2">A</td><td>one</td></tr><tr><td>two</td></tr></table>`,
+			expected: []string{
+				"- **Group:** A\n  - **Item:** one",
+				"- **Group:** A\n  - **Item:** two",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scraper := NewService(nil, nil, nil, nil, Config{TableFallback: tt.fallback})
+
+			result, err := scraper.ExtractContent(`<div class="content">`+tt.html+`</div>`, ".content")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			for _, expected := range tt.expected {
+				if !strings.Contains(result, expected) {
+					t.Errorf("expected result to contain %q, got %q", expected, result)
+				}
+			}
+			for _, absent := range tt.absent {
+				if strings.Contains(result, absent) {
+					t.Errorf("expected result not to contain %q, got %q", absent, result)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package server
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// accessLogEntry is one line of the JSON access log
+type accessLogEntry struct {
+	Time       string  `json:"time"`
+	Method     string  `json:"method"`
+	Path       string  `json:"path"`
+	Query      string  `json:"query,omitempty"`
+	Status     int     `json:"status"`
+	Bytes      int64   `json:"bytes"`
+	DurationMS float64 `json:"duration_ms"`
+	RemoteAddr string  `json:"remote_addr,omitempty"`
+	UserAgent  string  `json:"user_agent,omitempty"`
+}
+
+// accessLog writes one JSON object per request and feeds the metrics
+type accessLog struct {
+	mu      sync.Mutex
+	out     *json.Encoder // nil when only metrics are collected
+	metrics *Metrics
+}
+
+func newAccessLog(out io.Writer, metrics *Metrics) *accessLog {
+	a := &accessLog{metrics: metrics}
+	if out != nil {
+		a.out = json.NewEncoder(out)
+	}
+	return a
+}
+
+// wrap records every request passing through next
+func (a *accessLog) wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w}
+
+		next.ServeHTTP(rec, r)
+
+		latency := time.Since(start)
+		status := rec.status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		a.metrics.Observe(r.URL.Path, r.Method, status, rec.bytes, latency)
+
+		if a.out == nil {
+			return
+		}
+		entry := accessLogEntry{
+			Time:       start.UTC().Format(time.RFC3339Nano),
+			Method:     r.Method,
+			Path:       r.URL.Path,
+			Query:      r.URL.RawQuery,
+			Status:     status,
+			Bytes:      rec.bytes,
+			DurationMS: float64(latency.Microseconds()) / 1000,
+			RemoteAddr: r.RemoteAddr,
+			UserAgent:  r.UserAgent(),
+		}
+		a.mu.Lock()
+		a.out.Encode(entry)
+		a.mu.Unlock()
+	})
+}
+
+// statusRecorder captures the status code and body size of a response
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+	bytes  int64
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	// informational responses are followed by the real one
+	if r.status == 0 && status >= 200 {
+		r.status = status
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Write(p []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	n, err := r.ResponseWriter.Write(p)
+	r.bytes += int64(n)
+	return n, err
+}
+
+// Flush keeps live reload streams and proxied responses streaming
+func (r *statusRecorder) Flush() {
+	http.NewResponseController(r.ResponseWriter).Flush()
+}
+
+// Unwrap gives http.ResponseController access to the underlying writer
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
@@ -0,0 +1,106 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestAccessLog(t *testing.T) {
+	tests := []struct {
+		name           string
+		handler        http.HandlerFunc
+		expectedStatus int
+		expectedBytes  int64
+	}{
+		{
+			name:           "implicit 200",
+			handler:        func(w http.ResponseWriter, r *http.Request) { w.Write([]byte("# Home")) },
+			expectedStatus: http.StatusOK,
+			expectedBytes:  6,
+		},
+		{
+			name:           "not found",
+			handler:        http.NotFound,
+			expectedStatus: http.StatusNotFound,
+			expectedBytes:  19,
+		},
+		{
+			name:           "no body",
+			handler:        func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusNotModified) },
+			expectedStatus: http.StatusNotModified,
+		},
+		{
+			name:           "nothing written",
+			handler:        func(w http.ResponseWriter, r *http.Request) {},
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			metrics := NewMetrics()
+			handler := newAccessLog(&out, metrics).wrap(tt.handler)
+
+			req := httptest.NewRequest("GET", "/guide/setup?format=html", nil)
+			req.Header.Set("User-Agent", "curl/8.0")
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			var entry accessLogEntry
+			if err := json.Unmarshal(out.Bytes(), &entry); err != nil {
+				t.Fatalf("expected a JSON log line, got %q: %v", out.String(), err)
+			}
+			if entry.Method != "GET" || entry.Path != "/guide/setup" || entry.Query != "format=html" {
+				t.Errorf("unexpected request fields: %+v", entry)
+			}
+			if entry.Status != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d", tt.expectedStatus, entry.Status)
+			}
+			if entry.Bytes != tt.expectedBytes {
+				t.Errorf("expected %d bytes, got %d", tt.expectedBytes, entry.Bytes)
+			}
+			if entry.UserAgent != "curl/8.0" || entry.Time == "" || entry.DurationMS < 0 {
+				t.Errorf("unexpected entry: %+v", entry)
+			}
+			if strings.Count(out.String(), "\n") != 1 {
+				t.Errorf("expected a single line, got %q", out.String())
+			}
+
+			key := requestKey{prefix: "/guide/", method: "GET", code: tt.expectedStatus}
+			if metrics.requests[key] != 1 {
+				t.Errorf("expected request to be counted under %+v", key)
+			}
+		})
+	}
+}
+
+func TestAccessLog_Disabled(t *testing.T) {
+	metrics := NewMetrics()
+	handler := newAccessLog(nil, metrics).wrap(http.HandlerFunc(http.NotFound))
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/missing", nil))
+
+	if metrics.notFound["/"] != 1 {
+		t.Error("expected metrics to be collected without an access log")
+	}
+}
+
+func TestStatusRecorder_Flush(t *testing.T) {
+	w := httptest.NewRecorder()
+	rec := &statusRecorder{ResponseWriter: w}
+
+	var writer http.ResponseWriter = rec
+	flusher, ok := writer.(http.Flusher)
+	if !ok {
+		t.Fatal("expected recorder to support flushing for live reload streams")
+	}
+	flusher.Flush()
+
+	if !w.Flushed {
+		t.Error("expected flush to reach the underlying writer")
+	}
+}
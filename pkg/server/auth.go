@@ -0,0 +1,177 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// authRealm is sent in WWW-Authenticate challenges
+const authRealm = "mdify"
+
+// openPaths are served without authentication so orchestrators can probe them
+var openPaths = map[string]bool{
+	healthPath: true,
+	readyPath:  true,
+}
+
+// authenticator checks requests against static bearer tokens and users from
+// an htpasswd file
+type authenticator struct {
+	tokens []string
+	users  map[string]string // user -> password hash
+	logger Logger
+
+	mu       sync.Mutex
+	verified map[string][32]byte // user -> hash of the last password that matched
+}
+
+// newAuthenticator loads the credentials to accept. It returns nil when no
+// credentials are configured and the server is open.
+func newAuthenticator(fs FileSystem, tokens []string, htpasswdFile string, logger Logger) (*authenticator, error) {
+	if len(tokens) == 0 && htpasswdFile == "" {
+		return nil, nil
+	}
+
+	a := &authenticator{
+		tokens:   tokens,
+		logger:   logger,
+		verified: make(map[string][32]byte),
+	}
+
+	if htpasswdFile != "" {
+		content, err := fs.ReadFile(htpasswdFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read htpasswd file %s: %w", htpasswdFile, err)
+		}
+		users, err := parseHtpasswd(content)
+		if err != nil {
+			return nil, fmt.Errorf("invalid htpasswd file %s: %w", htpasswdFile, err)
+		}
+		a.users = users
+	}
+
+	return a, nil
+}
+
+// parseHtpasswd reads user:hash lines. Only bcrypt and {SHA} hashes are
+// accepted; the MD5 and crypt formats are too weak to rely on.
+func parseHtpasswd(content []byte) (map[string]string, error) {
+	users := make(map[string]string)
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		user, hash, ok := strings.Cut(line, ":")
+		if !ok || user == "" || hash == "" {
+			return nil, fmt.Errorf("line %d: expected user:hash", lineNumber)
+		}
+		if !isBcrypt(hash) && !strings.HasPrefix(hash, "{SHA}") {
+			return nil, fmt.Errorf("line %d: unsupported hash for user %s, use bcrypt (htpasswd -B)", lineNumber, user)
+		}
+		users[user] = hash
+	}
+
+	return users, scanner.Err()
+}
+
+func isBcrypt(hash string) bool {
+	return strings.HasPrefix(hash, "$2a$") || strings.HasPrefix(hash, "$2b$") || strings.HasPrefix(hash, "$2y$")
+}
+
+// wrap rejects requests without valid credentials, except for open paths.
+// The credentials are mdify's own, so they are removed before the request
+// goes on, e.g. to a proxied upstream.
+func (a *authenticator) wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if openPaths[r.URL.Path] || a.authorized(r) {
+			if r.Header.Get("Authorization") != "" {
+				r = r.Clone(r.Context())
+				r.Header.Del("Authorization")
+			}
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if len(a.users) > 0 {
+			w.Header().Add("WWW-Authenticate", fmt.Sprintf(`Basic realm="%s", charset="UTF-8"`, authRealm))
+		}
+		if len(a.tokens) > 0 {
+			w.Header().Add("WWW-Authenticate", fmt.Sprintf(`Bearer realm="%s"`, authRealm))
+		}
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+	})
+}
+
+func (a *authenticator) authorized(r *http.Request) bool {
+	if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+		return a.validToken(strings.TrimSpace(token))
+	}
+
+	user, password, ok := r.BasicAuth()
+	if !ok {
+		return false
+	}
+	if a.checkPassword(user, password) {
+		return true
+	}
+	a.logger.Warn("Failed login", "user", user, "remote_addr", r.RemoteAddr)
+	return false
+}
+
+func (a *authenticator) validToken(token string) bool {
+	valid := false
+	for _, expected := range a.tokens {
+		// compare every token so timing doesn't reveal which one matched
+		if subtle.ConstantTimeCompare([]byte(token), []byte(expected)) == 1 {
+			valid = true
+		}
+	}
+	return valid
+}
+
+// checkPassword verifies a basic auth password. bcrypt is deliberately slow,
+// so the last password that matched for each user is remembered.
+func (a *authenticator) checkPassword(user, password string) bool {
+	hash, ok := a.users[user]
+	if !ok {
+		return false
+	}
+
+	sum := sha256.Sum256([]byte(password))
+	a.mu.Lock()
+	cached, seen := a.verified[user]
+	a.mu.Unlock()
+	if seen && subtle.ConstantTimeCompare(cached[:], sum[:]) == 1 {
+		return true
+	}
+
+	var valid bool
+	if isBcrypt(hash) {
+		valid = bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+	} else {
+		digest := sha1.Sum([]byte(password))
+		expected := "{SHA}" + base64.StdEncoding.EncodeToString(digest[:])
+		valid = subtle.ConstantTimeCompare([]byte(hash), []byte(expected)) == 1
+	}
+
+	if valid {
+		a.mu.Lock()
+		a.verified[user] = sum
+		a.mu.Unlock()
+	}
+	return valid
+}
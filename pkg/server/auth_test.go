@@ -0,0 +1,144 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestParseHtpasswd(t *testing.T) {
+	tests := []struct {
+		name          string
+		content       string
+		expectedUsers int
+		expectedError string
+	}{
+		{
+			name:          "bcrypt and sha entries",
+			content:       "# team\nalice:$2y$05$abcdefghijklmnopqrstuv\n\nbob:{SHA}W6ph5Mm5Pz8GgiULbPgzG37mj9g=\n",
+			expectedUsers: 2,
+		},
+		{
+			name:          "md5 rejected",
+			content:       "alice:$apr1$salt$hash",
+			expectedError: "line 1: unsupported hash for user alice",
+		},
+		{
+			name:          "missing hash",
+			content:       "alice:$2y$05$abc\nbob",
+			expectedError: "line 2: expected user:hash",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users, err := parseHtpasswd([]byte(tt.content))
+			if tt.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectedError) {
+					t.Errorf("expected error containing %q, got %v", tt.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(users) != tt.expectedUsers {
+				t.Errorf("expected %d users, got %d", tt.expectedUsers, len(users))
+			}
+		})
+	}
+}
+
+func TestAuthenticator(t *testing.T) {
+	hash, err := bcrypt.GenerateFromPassword([]byte("s3cret"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fs := NewMockFileSystem()
+	// bob's password is "password"
+	fs.SetFile("/etc/mdify.htpasswd", "alice:"+string(hash)+"\nbob:{SHA}W6ph5Mm5Pz8GgiULbPgzG37mj9g=\n")
+
+	auth, err := newAuthenticator(fs, []string{"team-token", "ci-token"}, "/etc/mdify.htpasswd", NewMockLogger())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	handler := auth.wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("docs"))
+	}))
+
+	tests := []struct {
+		name           string
+		path           string
+		setup          func(r *http.Request)
+		expectedStatus int
+	}{
+		{name: "no credentials", path: "/", expectedStatus: http.StatusUnauthorized},
+		{name: "bearer token", path: "/", setup: func(r *http.Request) { r.Header.Set("Authorization", "Bearer ci-token") }, expectedStatus: http.StatusOK},
+		{name: "wrong bearer token", path: "/", setup: func(r *http.Request) { r.Header.Set("Authorization", "Bearer nope") }, expectedStatus: http.StatusUnauthorized},
+		{name: "bcrypt user", path: "/", setup: func(r *http.Request) { r.SetBasicAuth("alice", "s3cret") }, expectedStatus: http.StatusOK},
+		{name: "sha user", path: "/", setup: func(r *http.Request) { r.SetBasicAuth("bob", "password") }, expectedStatus: http.StatusOK},
+		{name: "wrong password", path: "/", setup: func(r *http.Request) { r.SetBasicAuth("alice", "guess") }, expectedStatus: http.StatusUnauthorized},
+		{name: "unknown user", path: "/", setup: func(r *http.Request) { r.SetBasicAuth("mallory", "s3cret") }, expectedStatus: http.StatusUnauthorized},
+		{name: "health check is open", path: "/healthz", expectedStatus: http.StatusOK},
+		{name: "readiness check is open", path: "/readyz", expectedStatus: http.StatusOK},
+		{name: "metrics are protected", path: "/metrics", expectedStatus: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", tt.path, nil)
+			if tt.setup != nil {
+				tt.setup(req)
+			}
+			w := httptest.NewRecorder()
+
+			handler.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+			if tt.expectedStatus == http.StatusUnauthorized {
+				challenges := strings.Join(w.Header().Values("WWW-Authenticate"), ", ")
+				if !strings.Contains(challenges, `Basic realm="mdify"`) || !strings.Contains(challenges, `Bearer realm="mdify"`) {
+					t.Errorf("expected basic and bearer challenges, got %q", challenges)
+				}
+			}
+		})
+	}
+
+	// a repeated login is served from the cache of verified passwords
+	req := httptest.NewRequest("GET", "/", nil)
+	req.SetBasicAuth("alice", "s3cret")
+	if !auth.authorized(req) {
+		t.Error("expected cached password to be accepted")
+	}
+	if _, ok := auth.verified["alice"]; !ok {
+		t.Error("expected verified password to be cached")
+	}
+}
+
+func TestNewAuthenticator(t *testing.T) {
+	if auth, err := newAuthenticator(NewMockFileSystem(), nil, "", NewMockLogger()); auth != nil || err != nil {
+		t.Errorf("expected no authenticator without credentials, got %v, %v", auth, err)
+	}
+	if _, err := newAuthenticator(NewMockFileSystem(), nil, "/missing", NewMockLogger()); err == nil || !strings.Contains(err.Error(), "failed to read htpasswd file") {
+		t.Errorf("expected read error, got %v", err)
+	}
+}
+
+func TestMarkdownHandler_PrivateCaching(t *testing.T) {
+	fs := NewMockFileSystem()
+	fs.SetFile("/base/test.md", "# Test")
+	handler := &MarkdownHandler{baseDir: "/base", fs: fs, logger: NewMockLogger(), private: true}
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/test", nil))
+
+	if cacheControl := w.Header().Get("Cache-Control"); cacheControl != "private, max-age=3600" {
+		t.Errorf("expected private caching behind authentication, got %q", cacheControl)
+	}
+}
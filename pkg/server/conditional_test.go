@@ -0,0 +1,143 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestMarkdownHandler_ConditionalRequests(t *testing.T) {
+	modTime := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	content := "# Test\n\nHello world"
+
+	newHandler := func() *MarkdownHandler {
+		fs := NewMockFileSystem()
+		fs.SetDir("/base")
+		fs.SetFile("/base/test.md", content)
+		fs.SetModTime("/base/test.md", modTime)
+		return &MarkdownHandler{baseDir: "/base", fs: fs, logger: NewMockLogger()}
+	}
+
+	// fetch the ETag the handler assigns to the markdown representation
+	w := httptest.NewRecorder()
+	newHandler().ServeHTTP(w, httptest.NewRequest("GET", "/test", nil))
+	markdownETag := w.Header().Get("ETag")
+	if markdownETag == "" || markdownETag != etag([]byte(content)) {
+		t.Fatalf("expected content hash ETag, got %q", markdownETag)
+	}
+	if lastModified := w.Header().Get("Last-Modified"); lastModified != modTime.Format(http.TimeFormat) {
+		t.Errorf("expected Last-Modified %q, got %q", modTime.Format(http.TimeFormat), lastModified)
+	}
+
+	// the rendered page has no Last-Modified, only its own ETag
+	w = httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/test", nil)
+	req.Header.Set("Accept", "text/html")
+	newHandler().ServeHTTP(w, req)
+	if lastModified := w.Header().Get("Last-Modified"); lastModified != "" {
+		t.Errorf("expected no Last-Modified for html, got %q", lastModified)
+	}
+	if htmlETag := w.Header().Get("ETag"); htmlETag != etag(w.Body.Bytes()) {
+		t.Errorf("expected the hash of the page as ETag, got %q", htmlETag)
+	}
+
+	tests := []struct {
+		name           string
+		method         string
+		headers        map[string]string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "matching etag",
+			method:         "GET",
+			headers:        map[string]string{"If-None-Match": markdownETag},
+			expectedStatus: http.StatusNotModified,
+		},
+		{
+			name:           "stale etag",
+			method:         "GET",
+			headers:        map[string]string{"If-None-Match": `"stale"`},
+			expectedStatus: http.StatusOK,
+			expectedBody:   content,
+		},
+		{
+			name:           "markdown etag does not match html",
+			method:         "GET",
+			headers:        map[string]string{"If-None-Match": markdownETag, "Accept": "text/html"},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "not modified since",
+			method:         "GET",
+			headers:        map[string]string{"If-Modified-Since": modTime.Add(time.Hour).Format(http.TimeFormat)},
+			expectedStatus: http.StatusNotModified,
+		},
+		{
+			name:           "html ignores the markdown file's modification time",
+			method:         "GET",
+			headers:        map[string]string{"If-Modified-Since": modTime.Add(time.Hour).Format(http.TimeFormat), "Accept": "text/html"},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "modified since",
+			method:         "GET",
+			headers:        map[string]string{"If-Modified-Since": modTime.Add(-time.Hour).Format(http.TimeFormat)},
+			expectedStatus: http.StatusOK,
+			expectedBody:   content,
+		},
+		{
+			name:           "head",
+			method:         "HEAD",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "range",
+			method:         "GET",
+			headers:        map[string]string{"Range": "bytes=0-5"},
+			expectedStatus: http.StatusPartialContent,
+			expectedBody:   "# Test",
+		},
+		{
+			name:           "unsatisfiable range",
+			method:         "GET",
+			headers:        map[string]string{"Range": "bytes=1000-"},
+			expectedStatus: http.StatusRequestedRangeNotSatisfiable,
+		},
+		{
+			name:           "range ignored for changed content",
+			method:         "GET",
+			headers:        map[string]string{"Range": "bytes=0-5", "If-Range": `"stale"`},
+			expectedStatus: http.StatusOK,
+			expectedBody:   content,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/test", nil)
+			for name, value := range tt.headers {
+				req.Header.Set(name, value)
+			}
+			w := httptest.NewRecorder()
+
+			newHandler().ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+			if tt.expectedBody != "" && w.Body.String() != tt.expectedBody {
+				t.Errorf("expected body %q, got %q", tt.expectedBody, w.Body.String())
+			}
+			if tt.method == "HEAD" {
+				if w.Body.Len() != 0 {
+					t.Errorf("expected empty body for HEAD, got %q", w.Body.String())
+				}
+				if w.Header().Get("Content-Length") != "19" {
+					t.Errorf("expected Content-Length 19, got %q", w.Header().Get("Content-Length"))
+				}
+			}
+		})
+	}
+}
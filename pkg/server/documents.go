@@ -0,0 +1,18 @@
+package server
+
+import (
+	"fmt"
+	"hash/fnv"
+
+	"mdify/internal/docs"
+)
+
+// filesSignature hashes file names, sizes and modification times so changes
+// can be detected without reading file contents
+func filesSignature(files []docs.File) uint64 {
+	h := fnv.New64a()
+	for _, file := range files {
+		fmt.Fprintf(h, "%s\x00%d\x00%d\n", file.Rel, file.Size, file.ModTime)
+	}
+	return h.Sum64()
+}
@@ -0,0 +1,151 @@
+package server
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"mdify/internal/docs"
+)
+
+// defaultLLMsTitle heads llms.txt when the root index.md has no title
+const defaultLLMsTitle = "Documentation"
+
+type sitemapURLSet struct {
+	XMLName xml.Name     `xml:"urlset"`
+	Xmlns   string       `xml:"xmlns,attr"`
+	URLs    []sitemapURL `xml:"url"`
+}
+
+type sitemapURL struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+// baseURL returns the scheme and host the request was made to, honoring
+// X-Forwarded-Proto from a fronting proxy
+func baseURL(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	if proto := r.Header.Get("X-Forwarded-Proto"); proto == "http" || proto == "https" {
+		scheme = proto
+	}
+	return scheme + "://" + r.Host
+}
+
+// serveSitemap generates a sitemap.xml listing every served document
+func (h *MarkdownHandler) serveSitemap(w http.ResponseWriter, r *http.Request) {
+	files, err := docs.Walk(h.fs, h.baseDir)
+	if err != nil {
+		h.logger.Error("Failed to generate sitemap", "error", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	base := baseURL(r)
+	urlSet := sitemapURLSet{Xmlns: "http://www.sitemaps.org/schemas/sitemap/0.9"}
+	for _, file := range files {
+		entry := sitemapURL{Loc: base + docs.URL(file.Rel)}
+		if file.ModTime != 0 {
+			entry.LastMod = time.Unix(0, file.ModTime).UTC().Format(time.RFC3339)
+		}
+		urlSet.URLs = append(urlSet.URLs, entry)
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	encoder := xml.NewEncoder(&buf)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(urlSet); err != nil {
+		h.logger.Error("Failed to generate sitemap", "error", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	buf.WriteString("\n")
+
+	h.serveGenerated(w, r, "application/xml; charset=utf-8", buf.Bytes())
+}
+
+// serveLLMsTxt generates an llms.txt index linking to the raw markdown of
+// every served document, grouped by top-level directory
+func (h *MarkdownHandler) serveLLMsTxt(w http.ResponseWriter, r *http.Request) {
+	documents, err := docs.List(h.fs, h.baseDir)
+	if err != nil {
+		h.logger.Error("Failed to generate llms.txt", "error", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	title := defaultLLMsTitle
+	sectionTitles := make(map[string]string)
+	var sections []string
+	entries := make(map[string][]docs.Document)
+
+	for _, doc := range documents {
+		section, _, nested := strings.Cut(doc.File, "/")
+		if !nested {
+			section = ""
+		}
+
+		if doc.File == "index.md" && doc.Title != "" {
+			title = doc.Title
+		}
+		if doc.File == section+"/index.md" && doc.Title != "" {
+			sectionTitles[section] = doc.Title
+		}
+
+		if _, seen := entries[section]; !seen {
+			sections = append(sections, section)
+		}
+		entries[section] = append(entries[section], doc)
+	}
+
+	// top-level documents come first
+	sort.SliceStable(sections, func(i, j int) bool {
+		return sections[i] == "" && sections[j] != ""
+	})
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n", title)
+
+	base := baseURL(r)
+	for _, section := range sections {
+		heading := sectionTitles[section]
+		switch {
+		case heading != "":
+		case section == "":
+			heading = "Docs"
+		default:
+			heading = section
+		}
+		fmt.Fprintf(&b, "\n## %s\n\n", heading)
+
+		for _, doc := range entries[section] {
+			label := doc.Title
+			if label == "" {
+				label = strings.TrimSuffix(path.Base(doc.File), ".md")
+			}
+			fmt.Fprintf(&b, "- [%s](%s/%s)\n", label, base, doc.File)
+		}
+	}
+
+	h.serveGenerated(w, r, "text/plain; charset=utf-8", []byte(b.String()))
+}
+
+// serveGenerated writes a generated document, supporting the same
+// conditional and range requests as files
+func (h *MarkdownHandler) serveGenerated(w http.ResponseWriter, r *http.Request, contentType string, content []byte) {
+	h.logger.Debug("Generating", "path", r.URL.Path)
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Cache-Control", h.cacheControl())
+	w.Header().Set("ETag", etag(content))
+	http.ServeContent(w, r, r.URL.Path, time.Time{}, bytes.NewReader(content))
+}
@@ -0,0 +1,216 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func newGeneratedFS() *MockFileSystem {
+	fs := NewMockFileSystem()
+	fs.SetDir("/base")
+	fs.SetFile("/base/index.md", "# Example Docs\n\nWelcome.")
+	fs.SetFile("/base/about.md", "no title")
+	fs.SetFile("/base/guides/index.md", "# User Guides")
+	fs.SetFile("/base/guides/setup.md", "# Setup")
+	fs.SetFile("/base/api/auth.md", "---\ntitle: Authentication\n---\n")
+	fs.SetFile("/base/images/logo.png", "\x89PNG\r\n\x1a\n")
+	fs.SetFile("/base/assets/site.css", "body {}")
+	fs.SetFile("/base/.git/config", "[core]")
+	fs.SetFile("/base/.hidden/index.md", "# Hidden")
+	fs.SetFile("/base/.hidden/notes.md", "# Notes")
+	return fs
+}
+
+func TestMarkdownHandler_StaticFiles(t *testing.T) {
+	tests := []struct {
+		name           string
+		requestPath    string
+		accept         string
+		expectedStatus int
+		expectedType   string
+		expectedBody   string
+	}{
+		{
+			name:           "image",
+			requestPath:    "/images/logo.png",
+			expectedStatus: http.StatusOK,
+			expectedType:   "image/png",
+		},
+		{
+			name:           "stylesheet",
+			requestPath:    "/assets/site.css",
+			expectedStatus: http.StatusOK,
+			expectedType:   "text/css; charset=utf-8",
+			expectedBody:   "body {}",
+		},
+		{
+			name:           "raw markdown for explicit .md",
+			requestPath:    "/guides/setup.md",
+			accept:         "text/html",
+			expectedStatus: http.StatusOK,
+			expectedType:   "text/markdown; charset=utf-8",
+			expectedBody:   "# Setup",
+		},
+		{
+			name:           "raw index markdown",
+			requestPath:    "/guides/index.md",
+			expectedStatus: http.StatusOK,
+			expectedBody:   "# User Guides",
+		},
+		{
+			name:           "extensionless url",
+			requestPath:    "/guides/setup",
+			expectedStatus: http.StatusOK,
+			expectedBody:   "# Setup",
+		},
+		{
+			name:           "trailing slash serves index",
+			requestPath:    "/guides/",
+			expectedStatus: http.StatusOK,
+			expectedBody:   "# User Guides",
+		},
+		{
+			name:           "directory redirects",
+			requestPath:    "/guides",
+			expectedStatus: http.StatusMovedPermanently,
+		},
+		{
+			name:           "missing .md file",
+			requestPath:    "/guides/missing.md",
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "hidden files",
+			requestPath:    "/.git/config",
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "hidden directory listing",
+			requestPath:    "/.git/",
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "hidden directory index",
+			requestPath:    "/.hidden/",
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "hidden directory below another",
+			requestPath:    "/guides/.hidden/",
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "hidden extensionless page",
+			requestPath:    "/.hidden/notes",
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := &MarkdownHandler{baseDir: "/base", fs: newGeneratedFS(), logger: NewMockLogger()}
+
+			req := httptest.NewRequest("GET", tt.requestPath, nil)
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			w := httptest.NewRecorder()
+
+			handler.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+			if tt.expectedType != "" && w.Header().Get("Content-Type") != tt.expectedType {
+				t.Errorf("expected Content-Type %q, got %q", tt.expectedType, w.Header().Get("Content-Type"))
+			}
+			if tt.expectedBody != "" && w.Body.String() != tt.expectedBody {
+				t.Errorf("expected body %q, got %q", tt.expectedBody, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestMarkdownHandler_Sitemap(t *testing.T) {
+	handler := &MarkdownHandler{baseDir: "/base", fs: newGeneratedFS(), logger: NewMockLogger()}
+
+	req := httptest.NewRequest("GET", "http://docs.example.com/sitemap.xml", nil)
+	req.Header.Set("X-Forwarded-Proto", "https")
+	w := httptest.NewRecorder()
+
+	handler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", w.Code)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "application/xml; charset=utf-8" {
+		t.Errorf("expected XML content type, got %q", ct)
+	}
+
+	body := w.Body.String()
+	for _, expected := range []string{
+		`<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">`,
+		"<loc>https://docs.example.com/</loc>",
+		"<loc>https://docs.example.com/about</loc>",
+		"<loc>https://docs.example.com/guides/</loc>",
+		"<loc>https://docs.example.com/guides/setup</loc>",
+		"<loc>https://docs.example.com/api/auth</loc>",
+	} {
+		if !strings.Contains(body, expected) {
+			t.Errorf("expected sitemap to contain %q, got:\n%s", expected, body)
+		}
+	}
+	if strings.Contains(body, "logo.png") || strings.Contains(body, ".git") {
+		t.Errorf("expected only documents in sitemap, got:\n%s", body)
+	}
+}
+
+func TestMarkdownHandler_LLMsTxt(t *testing.T) {
+	t.Run("generated", func(t *testing.T) {
+		handler := &MarkdownHandler{baseDir: "/base", fs: newGeneratedFS(), logger: NewMockLogger()}
+
+		req := httptest.NewRequest("GET", "http://docs.example.com/llms.txt", nil)
+		w := httptest.NewRecorder()
+
+		handler.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d", w.Code)
+		}
+
+		expected := `# Example Docs
+
+## Docs
+
+- [about](http://docs.example.com/about.md)
+- [Example Docs](http://docs.example.com/index.md)
+
+## api
+
+- [Authentication](http://docs.example.com/api/auth.md)
+
+## User Guides
+
+- [User Guides](http://docs.example.com/guides/index.md)
+- [Setup](http://docs.example.com/guides/setup.md)
+`
+		if w.Body.String() != expected {
+			t.Errorf("expected:\n%s\ngot:\n%s", expected, w.Body.String())
+		}
+	})
+
+	t.Run("existing file wins", func(t *testing.T) {
+		fs := newGeneratedFS()
+		fs.SetFile("/base/llms.txt", "# Curated")
+		handler := &MarkdownHandler{baseDir: "/base", fs: fs, logger: NewMockLogger()}
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest("GET", "/llms.txt", nil))
+
+		if w.Body.String() != "# Curated" {
+			t.Errorf("expected the file on disk, got %q", w.Body.String())
+		}
+	})
+}
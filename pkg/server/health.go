@@ -0,0 +1,45 @@
+package server
+
+import (
+	"net/http"
+	"sync/atomic"
+)
+
+// Probe endpoints, answered even when authentication is required
+const (
+	healthPath = "/healthz"
+	readyPath  = "/readyz"
+)
+
+// serveHealth reports that the process is up
+func serveHealth(w http.ResponseWriter, r *http.Request) {
+	writeProbe(w, http.StatusOK, "ok")
+}
+
+// readiness reports whether the server should receive traffic: it is ready
+// once serving starts and stops being ready when shutdown begins, or when the
+// served directory disappears
+type readiness struct {
+	fs    FileSystem
+	dir   string
+	ready atomic.Bool
+}
+
+func (rd *readiness) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !rd.ready.Load() {
+		writeProbe(w, http.StatusServiceUnavailable, "not ready")
+		return
+	}
+	if info, err := rd.fs.Stat(rd.dir); err != nil || !info.IsExist() || !info.IsDir() {
+		writeProbe(w, http.StatusServiceUnavailable, "directory unavailable")
+		return
+	}
+	writeProbe(w, http.StatusOK, "ok")
+}
+
+func writeProbe(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	w.Write([]byte(message + "\n"))
+}
@@ -0,0 +1,96 @@
+package server
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReadiness(t *testing.T) {
+	tests := []struct {
+		name           string
+		ready          bool
+		dirExists      bool
+		expectedStatus int
+	}{
+		{name: "ready", ready: true, dirExists: true, expectedStatus: http.StatusOK},
+		{name: "not serving yet", ready: false, dirExists: true, expectedStatus: http.StatusServiceUnavailable},
+		{name: "directory removed", ready: true, dirExists: false, expectedStatus: http.StatusServiceUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := NewMockFileSystem()
+			if tt.dirExists {
+				fs.SetDir("/base")
+			}
+			rd := &readiness{fs: fs, dir: "/base"}
+			rd.ready.Store(tt.ready)
+
+			w := httptest.NewRecorder()
+			rd.ServeHTTP(w, httptest.NewRequest("GET", readyPath, nil))
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+			if w.Header().Get("Cache-Control") != "no-store" {
+				t.Error("expected probes not to be cached")
+			}
+		})
+	}
+}
+
+func TestServer_ProbesAndMetrics(t *testing.T) {
+	srv, err := NewService(newListenFS(), NewMockLogger(), Config{Addr: "127.0.0.1:0"}).Listen("/base")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	stop := startServer(t, srv)
+	defer stop()
+
+	base := "http://" + srv.Addr().String()
+
+	// readiness flips once Serve is running
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		resp, err := http.Get(base + readyPath)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode == http.StatusOK {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("server never became ready, last status %d", resp.StatusCode)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	for _, path := range []string{"/", "/missing/page"} {
+		resp, err := http.Get(base + path)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		resp.Body.Close()
+	}
+
+	resp, err := http.Get(base + metricsPath)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+
+	for _, line := range []string{
+		`mdify_http_requests_total{prefix="/",method="GET",code="200"} 1`,
+		`mdify_http_not_found_total{prefix="/missing/"} 1`,
+	} {
+		if !strings.Contains(string(body), line) {
+			t.Errorf("expected metrics to contain %q, got:\n%s", line, body)
+		}
+	}
+}
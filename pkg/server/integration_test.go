@@ -0,0 +1,130 @@
+package server
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"mdify/internal/filesystem"
+)
+
+// get fetches path from srv and returns the status and body
+func get(t *testing.T, srv *Server, path string) (int, string) {
+	t.Helper()
+
+	req, _ := http.NewRequest("GET", "http://"+srv.Addr().String()+path, nil)
+	req.Header.Set("Accept", "text/markdown")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	return resp.StatusCode, string(body)
+}
+
+func TestService_ServeMemFileSystem(t *testing.T) {
+	fs := filesystem.NewMemFileSystem()
+	fs.WriteFile("/docs/index.md", []byte("# Home\n\nWelcome."))
+	fs.WriteFile("/docs/guide/setup.md", []byte("# Setup\n\nRun the installer."))
+	fs.Symlink("setup.md", "/docs/guide/install.md")
+
+	srv, err := NewService(fs, NewMockLogger(), Config{Addr: "127.0.0.1:0"}).Listen("/docs")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	stop := startServer(t, srv)
+	defer stop()
+
+	tests := []struct {
+		path           string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{path: "/", expectedStatus: 200, expectedBody: "# Home\n\nWelcome."},
+		{path: "/guide/setup", expectedStatus: 200, expectedBody: "# Setup\n\nRun the installer."},
+		{path: "/guide/install", expectedStatus: 200, expectedBody: "# Setup\n\nRun the installer."},
+		{path: "/guide/missing", expectedStatus: 404},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			status, body := get(t, srv, tt.path)
+			if status != tt.expectedStatus {
+				t.Fatalf("expected status %d, got %d", tt.expectedStatus, status)
+			}
+			if tt.expectedBody != "" && body != tt.expectedBody {
+				t.Errorf("expected %q, got %q", tt.expectedBody, body)
+			}
+		})
+	}
+
+	_, body := get(t, srv, "/_search?q=installer")
+	var response SearchResponse
+	if err := json.Unmarshal([]byte(body), &response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if response.Total != 2 {
+		t.Errorf("expected the page and its link to match, got %+v", response)
+	}
+}
+
+func TestService_ServeEmbeddedWithOverlay(t *testing.T) {
+	root := filepath.Join(t.TempDir(), "docs")
+	embedded, err := filesystem.NewIOFileSystem(fstest.MapFS{
+		"index.md":       {Data: []byte("# Home")},
+		"guide/setup.md": {Data: []byte("# Setup")},
+	}, root)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	local := filesystem.NewMemFileSystem()
+	local.WriteFile(filepath.Join(root, "guide", "setup.md"), []byte("# Setup\n\nPatched locally."))
+
+	srv, err := NewService(filesystem.NewOverlay(local, embedded), NewMockLogger(), Config{Addr: "127.0.0.1:0"}).Listen(root)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	stop := startServer(t, srv)
+	defer stop()
+
+	if _, body := get(t, srv, "/"); body != "# Home" {
+		t.Errorf("expected the embedded index, got %q", body)
+	}
+	if _, body := get(t, srv, "/guide/setup"); body != "# Setup\n\nPatched locally." {
+		t.Errorf("expected the local copy to hide the embedded one, got %q", body)
+	}
+}
+
+func TestWatcher_MemFileSystem(t *testing.T) {
+	fs := filesystem.NewMemFileSystem()
+	fs.WriteFile("/docs/index.md", []byte("# Home"))
+
+	watcher := NewWatcher(fs, "/docs", time.Second, NewMockLogger())
+	if changed, err := watcher.Check(); err != nil || changed {
+		t.Fatalf("expected the first check to only prime the watcher, got %v, %v", changed, err)
+	}
+
+	fs.Chtimes("/docs/index.md", time.Now().Add(time.Minute))
+	if changed, _ := watcher.Check(); !changed {
+		t.Error("expected a new modification time to be a change")
+	}
+
+	fs.WriteFile("/docs/new.md", []byte("# New"))
+	if changed, _ := watcher.Check(); !changed {
+		t.Error("expected a new file to be a change")
+	}
+
+	fs.Remove("/docs/new.md")
+	if changed, _ := watcher.Check(); !changed {
+		t.Error("expected a removed file to be a change")
+	}
+	if changed, _ := watcher.Check(); changed {
+		t.Error("expected no change without edits")
+	}
+}
@@ -0,0 +1,149 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// unixPrefix marks listen addresses that are Unix socket paths
+const unixPrefix = "unix:"
+
+// Server is a bound markdown server, ready to serve
+type Server struct {
+	http            *http.Server
+	listener        net.Listener
+	watcher         *Watcher
+	readiness       *readiness
+	logger          Logger
+	shutdownTimeout time.Duration
+}
+
+// NewServer binds config.Addr and returns a Server for handler with the
+// timeouts and graceful shutdown of the markdown server. Only the address and
+// timeouts of config are used.
+func NewServer(handler http.Handler, config Config, logger Logger) (*Server, error) {
+	config = config.withDefaults()
+	listener, err := listen(config.Addr)
+	if err != nil {
+		return nil, err
+	}
+	return newServer(handler, nil, listener, config, logger), nil
+}
+
+func newServer(handler http.Handler, tlsConfig *tls.Config, listener net.Listener, config Config, logger Logger) *Server {
+	return &Server{
+		http: &http.Server{
+			Handler:           handler,
+			TLSConfig:         tlsConfig,
+			ReadHeaderTimeout: readHeaderTimeout,
+			ReadTimeout:       config.ReadTimeout,
+			WriteTimeout:      config.WriteTimeout,
+			IdleTimeout:       config.IdleTimeout,
+		},
+		listener:        listener,
+		logger:          logger,
+		shutdownTimeout: config.ShutdownTimeout,
+	}
+}
+
+// Addr returns the address the server is listening on
+func (s *Server) Addr() net.Addr {
+	return s.listener.Addr()
+}
+
+// URL describes the address the server is listening on, for log messages
+func (s *Server) URL() string {
+	return addrURL(s.listener.Addr(), s.http.TLSConfig != nil)
+}
+
+// Serve handles requests until ctx is cancelled, then stops accepting
+// connections and waits for in-flight requests to finish
+func (s *Server) Serve(ctx context.Context) error {
+	// stopping the watcher also ends live reload streams, which would
+	// otherwise hold the shutdown open
+	if s.watcher != nil {
+		go s.watcher.Run(ctx.Done())
+	}
+	if s.readiness != nil {
+		s.readiness.ready.Store(true)
+	}
+
+	errs := make(chan error, 1)
+	go func() {
+		if s.http.TLSConfig != nil {
+			errs <- s.http.ServeTLS(s.listener, "", "")
+			return
+		}
+		errs <- s.http.Serve(s.listener)
+	}()
+
+	select {
+	case err := <-errs:
+		return fmt.Errorf("server failed: %w", err)
+	case <-ctx.Done():
+	}
+
+	// fail readiness first so load balancers stop sending new requests
+	if s.readiness != nil {
+		s.readiness.ready.Store(false)
+	}
+	s.logger.Info("Shutting down, waiting for open requests", "timeout", s.shutdownTimeout)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), s.shutdownTimeout)
+	defer cancel()
+
+	if err := s.http.Shutdown(shutdownCtx); err != nil {
+		s.http.Close()
+		return fmt.Errorf("failed to shut down gracefully: %w", err)
+	}
+	if err := <-errs; err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("server failed: %w", err)
+	}
+
+	s.logger.Info("Server stopped")
+	return nil
+}
+
+// listen binds a TCP address or, with the unix: prefix, a Unix socket
+func listen(addr string) (net.Listener, error) {
+	socketPath, isUnix := strings.CutPrefix(addr, unixPrefix)
+	if !isUnix {
+		listener, err := net.Listen("tcp", addr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to listen on %s: %w", addr, err)
+		}
+		return listener, nil
+	}
+
+	// a socket left behind by a crashed server would block the new one
+	if info, err := os.Stat(socketPath); err == nil && info.Mode()&os.ModeSocket != 0 {
+		if conn, err := net.Dial("unix", socketPath); err == nil {
+			conn.Close()
+			return nil, fmt.Errorf("socket %s is already in use", socketPath)
+		}
+		os.Remove(socketPath)
+	}
+
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+	return listener, nil
+}
+
+// addrURL describes a listener address for log messages
+func addrURL(addr net.Addr, secure bool) string {
+	if addr.Network() == "unix" {
+		return unixPrefix + addr.String()
+	}
+	if secure {
+		return "https://" + addr.String()
+	}
+	return "http://" + addr.String()
+}
@@ -0,0 +1,186 @@
+package server
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newListenFS() *MockFileSystem {
+	fs := NewMockFileSystem()
+	fs.SetDir("/base")
+	fs.SetFile("/base/index.md", "# Home")
+	return fs
+}
+
+// startServer runs srv in the background and returns a function that stops
+// it and returns the result of Serve
+func startServer(t *testing.T, srv *Server) func() error {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	result := make(chan error, 1)
+	go func() {
+		result <- srv.Serve(ctx)
+	}()
+
+	return func() error {
+		cancel()
+		select {
+		case err := <-result:
+			return err
+		case <-time.After(5 * time.Second):
+			t.Fatal("server did not stop")
+			return nil
+		}
+	}
+}
+
+func TestService_Listen(t *testing.T) {
+	service := NewService(newListenFS(), NewMockLogger(), Config{Addr: "127.0.0.1:0"})
+	srv, err := service.Listen("/base")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	stop := startServer(t, srv)
+
+	addr := srv.Addr().String()
+	if strings.HasSuffix(addr, ":0") {
+		t.Fatalf("expected a bound port, got %s", addr)
+	}
+
+	resp, err := http.Get("http://" + addr + "/")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != "# Home" {
+		t.Errorf("expected index content, got %q", body)
+	}
+
+	if err := stop(); err != nil {
+		t.Errorf("expected clean shutdown, got %v", err)
+	}
+	if _, err := http.Get("http://" + addr + "/"); err == nil {
+		t.Error("expected server to stop accepting connections")
+	}
+}
+
+func TestNewServer(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "ok")
+	})
+	srv, err := NewServer(handler, Config{Addr: "127.0.0.1:0", WriteTimeout: time.Second}, NewMockLogger())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if srv.http.WriteTimeout != time.Second || srv.http.ReadTimeout != defaultReadTimeout || srv.http.ReadHeaderTimeout != readHeaderTimeout {
+		t.Errorf("expected the configured and default timeouts, got %+v", srv.http)
+	}
+	if !strings.HasPrefix(srv.URL(), "http://127.0.0.1:") {
+		t.Errorf("unexpected URL %q", srv.URL())
+	}
+	stop := startServer(t, srv)
+
+	resp, err := http.Get(srv.URL() + "/")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != "ok" {
+		t.Errorf("expected the handler's response, got %q", body)
+	}
+	if err := stop(); err != nil {
+		t.Errorf("expected clean shutdown, got %v", err)
+	}
+}
+
+func TestService_ListenUnixSocket(t *testing.T) {
+	socketPath := filepath.Join(t.TempDir(), "mdify.sock")
+
+	service := NewService(newListenFS(), NewMockLogger(), Config{Addr: "unix:" + socketPath})
+	srv, err := service.Listen("/base")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	stop := startServer(t, srv)
+	defer stop()
+
+	if srv.Addr().Network() != "unix" {
+		t.Errorf("expected unix listener, got %s", srv.Addr().Network())
+	}
+
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "unix", socketPath)
+		},
+	}}
+	resp, err := client.Get("http://mdify/")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected status 200, got %d", resp.StatusCode)
+	}
+
+	// a second server must not take over a live socket
+	if _, err := NewService(newListenFS(), NewMockLogger(), Config{Addr: "unix:" + socketPath}).Listen("/base"); err == nil || !strings.Contains(err.Error(), "already in use") {
+		t.Errorf("expected socket in use error, got %v", err)
+	}
+}
+
+func TestService_ListenErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		config    Config
+		statError error
+		expected  string
+	}{
+		{name: "missing directory", config: Config{Addr: "127.0.0.1:0"}, statError: io.ErrUnexpectedEOF, expected: "directory does not exist"},
+		{name: "bad address", config: Config{Addr: "127.0.0.1:notaport"}, expected: "failed to listen"},
+		{name: "bad upstream", config: Config{Addr: "127.0.0.1:0", Upstream: "docs.example.com"}, expected: "invalid upstream"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := newListenFS()
+			fs.SetStatError(tt.statError)
+
+			_, err := NewService(fs, NewMockLogger(), tt.config).Listen("/base")
+			if err == nil || !strings.Contains(err.Error(), tt.expected) {
+				t.Errorf("expected error containing %q, got %v", tt.expected, err)
+			}
+		})
+	}
+}
+
+func TestServer_ShutdownEndsLiveReloadStreams(t *testing.T) {
+	service := NewService(newListenFS(), NewMockLogger(), Config{Addr: "127.0.0.1:0", Watch: true, ShutdownTimeout: 5 * time.Second})
+	srv, err := service.Listen("/base")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	stop := startServer(t, srv)
+
+	resp, err := http.Get("http://" + srv.Addr().String() + "/_events")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer resp.Body.Close()
+
+	start := time.Now()
+	if err := stop(); err != nil {
+		t.Errorf("expected clean shutdown, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("expected the event stream not to hold up shutdown, took %s", elapsed)
+	}
+}
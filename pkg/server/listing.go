@@ -0,0 +1,156 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"net/http"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"mdify/internal/docs"
+)
+
+// Response formats negotiated from the format parameter or Accept header
+const (
+	FormatMarkdown = "markdown"
+	FormatHTML     = "html"
+	FormatJSON     = "json"
+)
+
+// Listing describes the contents of a served directory
+type Listing struct {
+	Path       string         `json:"path"`
+	Entries    []ListingEntry `json:"entries"`
+	LiveReload template.HTML  `json:"-"` // reload script for HTML listings, when watching
+}
+
+// ListingEntry is a file or subdirectory in a listing
+type ListingEntry struct {
+	Name  string `json:"name"`
+	Path  string `json:"path"`
+	Title string `json:"title,omitempty"`
+	IsDir bool   `json:"is_dir"`
+}
+
+var listingTemplate = template.Must(template.New("listing").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Index of {{.Path}}</title>
+</head>
+<body>
+<h1>Index of {{.Path}}</h1>
+<ul>
+{{if ne .Path "/"}}<li><a href="../">../</a></li>
+{{end}}{{range .Entries}}<li><a href="{{.Path}}">{{if .Title}}{{.Title}}{{else}}{{.Name}}{{end}}</a>{{if .IsDir}}/{{end}}</li>
+{{end}}</ul>
+{{with .LiveReload}}{{.}}
+{{end}}</body>
+</html>
+`))
+
+// negotiateFormat picks the response format from the format query parameter,
+// falling back to the request's Accept header
+func negotiateFormat(r *http.Request) string {
+	switch r.URL.Query().Get("format") {
+	case "html":
+		return FormatHTML
+	case "json":
+		return FormatJSON
+	case "markdown", "md", "raw":
+		return FormatMarkdown
+	}
+
+	accept := r.Header.Get("Accept")
+	switch {
+	case strings.Contains(accept, "text/html"):
+		return FormatHTML
+	case strings.Contains(accept, "application/json"):
+		return FormatJSON
+	}
+	return FormatMarkdown
+}
+
+// listDirectory builds the listing for dirPath, which is served at urlPath
+func (h *MarkdownHandler) listDirectory(dirPath, urlPath string) (*Listing, error) {
+	entries, err := h.fs.ReadDir(dirPath)
+	if err != nil {
+		return nil, err
+	}
+
+	listing := &Listing{Path: urlPath, Entries: []ListingEntry{}}
+	for _, entry := range entries {
+		name := entry.Name()
+		if strings.HasPrefix(name, ".") {
+			continue
+		}
+
+		if entry.IsDir() {
+			listing.Entries = append(listing.Entries, ListingEntry{
+				Name:  name,
+				Path:  path.Join(urlPath, name) + "/",
+				IsDir: true,
+			})
+			continue
+		}
+
+		if !strings.HasSuffix(name, ".md") {
+			continue
+		}
+
+		item := ListingEntry{
+			Name: name,
+			Path: path.Join(urlPath, strings.TrimSuffix(name, ".md")),
+		}
+		if content, err := h.fs.ReadFile(filepath.Join(dirPath, name)); err == nil {
+			item.Title = docs.Title(content)
+		}
+		listing.Entries = append(listing.Entries, item)
+	}
+
+	// directories first, then files, each alphabetically
+	sort.SliceStable(listing.Entries, func(i, j int) bool {
+		a, b := listing.Entries[i], listing.Entries[j]
+		if a.IsDir != b.IsDir {
+			return a.IsDir
+		}
+		return a.Name < b.Name
+	})
+
+	return listing, nil
+}
+
+// writeListing renders the listing in the requested format
+func writeListing(w http.ResponseWriter, listing *Listing, format string) error {
+	switch format {
+	case FormatHTML:
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		return listingTemplate.Execute(w, listing)
+	case FormatJSON:
+		w.Header().Set("Content-Type", "application/json")
+		return json.NewEncoder(w).Encode(listing)
+	}
+
+	w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+	_, err := w.Write([]byte(listing.Markdown()))
+	return err
+}
+
+// Markdown renders the listing as a markdown list of links
+func (l *Listing) Markdown() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Index of %s\n\n", l.Path)
+	for _, entry := range l.Entries {
+		label := entry.Name
+		if entry.IsDir {
+			label += "/"
+		} else if entry.Title != "" {
+			label = entry.Title
+		}
+		fmt.Fprintf(&b, "- [%s](%s)\n", label, entry.Path)
+	}
+	return b.String()
+}
@@ -0,0 +1,131 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func newListingFS() *MockFileSystem {
+	fs := NewMockFileSystem()
+	fs.SetDir("/base")
+	fs.SetFile("/base/docs/api.md", "---\ntitle: \"API Reference\"\n---\n\n# Ignored")
+	fs.SetFile("/base/docs/intro.md", "```bash\n# not a title\n```\n\n# Introduction\n\nHello")
+	fs.SetFile("/base/docs/guides/setup.md", "no title here")
+	fs.SetFile("/base/docs/.hidden.md", "# Hidden")
+	fs.SetFile("/base/docs/logo.png", "png")
+	return fs
+}
+
+func TestMarkdownHandler_DirectoryListing(t *testing.T) {
+	tests := []struct {
+		name              string
+		requestPath       string
+		accept            string
+		expectedStatus    int
+		expectedType      string
+		expectedContent   []string
+		unexpectedContent []string
+	}{
+		{
+			name:           "markdown listing by default",
+			requestPath:    "/docs/",
+			expectedStatus: 200,
+			expectedType:   "text/markdown; charset=utf-8",
+			expectedContent: []string{
+				"# Index of /docs/",
+				"- [guides/](/docs/guides/)",
+				"- [API Reference](/docs/api)",
+				"- [Introduction](/docs/intro)",
+			},
+			unexpectedContent: []string{"Hidden", "logo.png", "not a title"},
+		},
+		{
+			name:           "html listing for browsers",
+			requestPath:    "/docs/",
+			accept:         "text/html,application/xhtml+xml",
+			expectedStatus: 200,
+			expectedType:   "text/html; charset=utf-8",
+			expectedContent: []string{
+				"<title>Index of /docs/</title>",
+				`<a href="/docs/api">API Reference</a>`,
+				`<a href="../">../</a>`,
+			},
+		},
+		{
+			name:            "root listing without index",
+			requestPath:     "/",
+			expectedStatus:  200,
+			expectedContent: []string{"- [docs/](/docs/)"},
+		},
+		{
+			name:           "directory without trailing slash redirects",
+			requestPath:    "/docs",
+			expectedStatus: 301,
+		},
+		{
+			name:           "missing directory",
+			requestPath:    "/missing/",
+			expectedStatus: 404,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := &MarkdownHandler{baseDir: "/base", fs: newListingFS(), logger: NewMockLogger()}
+
+			req := httptest.NewRequest("GET", tt.requestPath, nil)
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			w := httptest.NewRecorder()
+
+			handler.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+			if tt.expectedType != "" && w.Header().Get("Content-Type") != tt.expectedType {
+				t.Errorf("expected content type %q, got %q", tt.expectedType, w.Header().Get("Content-Type"))
+			}
+
+			body := w.Body.String()
+			for _, expected := range tt.expectedContent {
+				if !strings.Contains(body, expected) {
+					t.Errorf("expected body to contain %q, got %q", expected, body)
+				}
+			}
+			for _, unexpected := range tt.unexpectedContent {
+				if strings.Contains(body, unexpected) {
+					t.Errorf("expected body not to contain %q, got %q", unexpected, body)
+				}
+			}
+		})
+	}
+}
+
+func TestMarkdownHandler_DirectoryListingJSON(t *testing.T) {
+	handler := &MarkdownHandler{baseDir: "/base", fs: newListingFS(), logger: NewMockLogger()}
+
+	req := httptest.NewRequest("GET", "/docs/", nil)
+	req.Header.Set("Accept", "application/json")
+	w := httptest.NewRecorder()
+
+	handler.ServeHTTP(w, req)
+
+	var listing Listing
+	if err := json.Unmarshal(w.Body.Bytes(), &listing); err != nil {
+		t.Fatalf("failed to decode listing: %v", err)
+	}
+
+	if listing.Path != "/docs/" || len(listing.Entries) != 3 {
+		t.Fatalf("unexpected listing: %+v", listing)
+	}
+	if !listing.Entries[0].IsDir || listing.Entries[0].Name != "guides" {
+		t.Errorf("expected directories first, got %+v", listing.Entries[0])
+	}
+	if listing.Entries[1].Title != "API Reference" {
+		t.Errorf("expected title from front matter, got %+v", listing.Entries[1])
+	}
+}
@@ -0,0 +1,199 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// metricsPath exposes request metrics in the Prometheus text format
+const metricsPath = "/metrics"
+
+// maxPrefixes caps the number of distinct prefix labels, so scanners probing
+// random URLs can't grow the metrics without bound
+const maxPrefixes = 100
+
+// latencyBuckets are the upper bounds, in seconds, of the latency histogram
+var latencyBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// endpointPaths get their own prefix label instead of being grouped under "/"
+var endpointPaths = map[string]bool{
+	"/_search":     true,
+	"/_events":     true,
+	metricsPath:    true,
+	healthPath:     true,
+	readyPath:      true,
+	"/sitemap.xml": true,
+	"/llms.txt":    true,
+}
+
+// knownMethods are kept as method labels; anything else is counted as "other"
+var knownMethods = map[string]bool{
+	http.MethodGet:     true,
+	http.MethodHead:    true,
+	http.MethodPost:    true,
+	http.MethodPut:     true,
+	http.MethodPatch:   true,
+	http.MethodDelete:  true,
+	http.MethodOptions: true,
+}
+
+// labelEscaper escapes label values for the text exposition format
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// Metrics counts requests, response sizes and latencies per path prefix
+type Metrics struct {
+	mu        sync.Mutex
+	started   time.Time
+	requests  map[requestKey]uint64
+	notFound  map[string]uint64
+	bytes     map[string]uint64
+	latencies map[string]*histogram
+}
+
+type requestKey struct {
+	prefix string
+	method string
+	code   int
+}
+
+type histogram struct {
+	counts []uint64 // per bucket, not cumulative
+	sum    float64
+	count  uint64
+}
+
+// NewMetrics creates an empty metrics registry
+func NewMetrics() *Metrics {
+	return &Metrics{
+		started:   time.Now(),
+		requests:  make(map[requestKey]uint64),
+		notFound:  make(map[string]uint64),
+		bytes:     make(map[string]uint64),
+		latencies: make(map[string]*histogram),
+	}
+}
+
+// Observe records a finished request
+func (m *Metrics) Observe(path, method string, status int, size int64, latency time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	prefix := m.prefix(path)
+	if !knownMethods[method] {
+		method = "other"
+	}
+	m.requests[requestKey{prefix: prefix, method: method, code: status}]++
+	if status == http.StatusNotFound {
+		m.notFound[prefix]++
+	}
+	m.bytes[prefix] += uint64(size)
+
+	h, ok := m.latencies[prefix]
+	if !ok {
+		h = &histogram{counts: make([]uint64, len(latencyBuckets))}
+		m.latencies[prefix] = h
+	}
+	seconds := latency.Seconds()
+	for i, bound := range latencyBuckets {
+		if seconds <= bound {
+			h.counts[i]++
+			break
+		}
+	}
+	h.sum += seconds
+	h.count++
+}
+
+// prefix groups a request path by its top-level directory: /guide/setup
+// becomes /guide/, while top-level pages share the / prefix
+func (m *Metrics) prefix(path string) string {
+	if endpointPaths[path] {
+		return path
+	}
+
+	prefix := "/"
+	if segment, _, nested := strings.Cut(strings.TrimPrefix(path, "/"), "/"); nested {
+		prefix = labelEscaper.Replace("/" + segment + "/")
+	}
+
+	if _, known := m.latencies[prefix]; !known && len(m.latencies) >= maxPrefixes {
+		return "other"
+	}
+	return prefix
+}
+
+// ServeHTTP writes the metrics in the Prometheus text exposition format
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Write([]byte(m.render()))
+}
+
+func (m *Metrics) render() string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var b strings.Builder
+
+	b.WriteString("# HELP mdify_http_requests_total Requests served, by path prefix, method and status code.\n")
+	b.WriteString("# TYPE mdify_http_requests_total counter\n")
+	keys := make([]requestKey, 0, len(m.requests))
+	for key := range m.requests {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].prefix != keys[j].prefix {
+			return keys[i].prefix < keys[j].prefix
+		}
+		if keys[i].method != keys[j].method {
+			return keys[i].method < keys[j].method
+		}
+		return keys[i].code < keys[j].code
+	})
+	for _, key := range keys {
+		fmt.Fprintf(&b, "mdify_http_requests_total{prefix=\"%s\",method=\"%s\",code=\"%d\"} %d\n", key.prefix, key.method, key.code, m.requests[key])
+	}
+
+	prefixes := make([]string, 0, len(m.latencies))
+	for prefix := range m.latencies {
+		prefixes = append(prefixes, prefix)
+	}
+	sort.Strings(prefixes)
+
+	b.WriteString("# HELP mdify_http_not_found_total Requests answered with 404 Not Found, by path prefix.\n")
+	b.WriteString("# TYPE mdify_http_not_found_total counter\n")
+	for _, prefix := range prefixes {
+		fmt.Fprintf(&b, "mdify_http_not_found_total{prefix=\"%s\"} %d\n", prefix, m.notFound[prefix])
+	}
+
+	b.WriteString("# HELP mdify_http_response_bytes_total Response body bytes written, by path prefix.\n")
+	b.WriteString("# TYPE mdify_http_response_bytes_total counter\n")
+	for _, prefix := range prefixes {
+		fmt.Fprintf(&b, "mdify_http_response_bytes_total{prefix=\"%s\"} %d\n", prefix, m.bytes[prefix])
+	}
+
+	b.WriteString("# HELP mdify_http_request_duration_seconds Request latency, by path prefix.\n")
+	b.WriteString("# TYPE mdify_http_request_duration_seconds histogram\n")
+	for _, prefix := range prefixes {
+		h := m.latencies[prefix]
+		var cumulative uint64
+		for i, bound := range latencyBuckets {
+			cumulative += h.counts[i]
+			fmt.Fprintf(&b, "mdify_http_request_duration_seconds_bucket{prefix=\"%s\",le=\"%s\"} %d\n", prefix, strconv.FormatFloat(bound, 'g', -1, 64), cumulative)
+		}
+		fmt.Fprintf(&b, "mdify_http_request_duration_seconds_bucket{prefix=\"%s\",le=\"+Inf\"} %d\n", prefix, h.count)
+		fmt.Fprintf(&b, "mdify_http_request_duration_seconds_sum{prefix=\"%s\"} %s\n", prefix, strconv.FormatFloat(h.sum, 'g', -1, 64))
+		fmt.Fprintf(&b, "mdify_http_request_duration_seconds_count{prefix=\"%s\"} %d\n", prefix, h.count)
+	}
+
+	b.WriteString("# HELP mdify_uptime_seconds Seconds since the server started.\n")
+	b.WriteString("# TYPE mdify_uptime_seconds gauge\n")
+	fmt.Fprintf(&b, "mdify_uptime_seconds %s\n", strconv.FormatFloat(time.Since(m.started).Seconds(), 'f', 3, 64))
+
+	return b.String()
+}
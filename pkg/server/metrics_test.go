@@ -0,0 +1,89 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMetrics_Prefix(t *testing.T) {
+	tests := []struct {
+		path     string
+		expected string
+	}{
+		{path: "/", expected: "/"},
+		{path: "/guide", expected: "/"},
+		{path: "/guide.md", expected: "/"},
+		{path: "/guide/", expected: "/guide/"},
+		{path: "/guide/setup/install", expected: "/guide/"},
+		{path: "/_search", expected: "/_search"},
+		{path: "/healthz", expected: "/healthz"},
+		{path: "/llms.txt", expected: "/llms.txt"},
+		{path: `/a"b/c`, expected: `/a\"b/`},
+	}
+
+	m := NewMetrics()
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if prefix := m.prefix(tt.path); prefix != tt.expected {
+				t.Errorf("expected prefix %q, got %q", tt.expected, prefix)
+			}
+		})
+	}
+}
+
+func TestMetrics_PrefixLimit(t *testing.T) {
+	m := NewMetrics()
+	for i := 0; i < maxPrefixes+10; i++ {
+		m.Observe(fmt.Sprintf("/probe%d/x", i), http.MethodGet, http.StatusNotFound, 0, time.Millisecond)
+	}
+
+	if len(m.latencies) != maxPrefixes+1 {
+		t.Errorf("expected %d prefixes including other, got %d", maxPrefixes+1, len(m.latencies))
+	}
+	if m.notFound["other"] != 10 {
+		t.Errorf("expected 10 requests grouped as other, got %d", m.notFound["other"])
+	}
+}
+
+func TestMetrics_ServeHTTP(t *testing.T) {
+	m := NewMetrics()
+	m.Observe("/guide/setup", http.MethodGet, http.StatusOK, 120, 20*time.Millisecond)
+	m.Observe("/guide/missing", http.MethodGet, http.StatusNotFound, 10, 2*time.Millisecond)
+	m.Observe("/guide/setup", "BREW", http.StatusMethodNotAllowed, 0, time.Millisecond)
+	m.Observe("/index", http.MethodHead, http.StatusOK, 0, 3*time.Second)
+
+	w := httptest.NewRecorder()
+	m.ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+
+	if contentType := w.Header().Get("Content-Type"); !strings.HasPrefix(contentType, "text/plain; version=0.0.4") {
+		t.Errorf("expected Prometheus content type, got %q", contentType)
+	}
+
+	body := w.Body.String()
+	expected := []string{
+		"# TYPE mdify_http_requests_total counter",
+		`mdify_http_requests_total{prefix="/guide/",method="GET",code="200"} 1`,
+		`mdify_http_requests_total{prefix="/guide/",method="GET",code="404"} 1`,
+		`mdify_http_requests_total{prefix="/guide/",method="other",code="405"} 1`,
+		`mdify_http_requests_total{prefix="/",method="HEAD",code="200"} 1`,
+		`mdify_http_not_found_total{prefix="/guide/"} 1`,
+		`mdify_http_not_found_total{prefix="/"} 0`,
+		`mdify_http_response_bytes_total{prefix="/guide/"} 130`,
+		`mdify_http_request_duration_seconds_bucket{prefix="/guide/",le="0.005"} 2`,
+		`mdify_http_request_duration_seconds_bucket{prefix="/guide/",le="0.025"} 3`,
+		`mdify_http_request_duration_seconds_bucket{prefix="/",le="2.5"} 0`,
+		`mdify_http_request_duration_seconds_bucket{prefix="/",le="5"} 1`,
+		`mdify_http_request_duration_seconds_bucket{prefix="/",le="+Inf"} 1`,
+		`mdify_http_request_duration_seconds_count{prefix="/guide/"} 3`,
+		"# TYPE mdify_uptime_seconds gauge",
+	}
+	for _, line := range expected {
+		if !strings.Contains(body, line+"\n") {
+			t.Errorf("expected metrics to contain %q, got:\n%s", line, body)
+		}
+	}
+}
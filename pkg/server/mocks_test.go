@@ -4,6 +4,12 @@ import (
 	"bytes"
 	"fmt"
 	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
 
 	"mdify/internal/filesystem"
 )
@@ -11,6 +17,7 @@ import (
 type MockFileSystem struct {
 	files         map[string]string
 	directories   map[string]bool
+	modTimes      map[string]time.Time
 	createError   error
 	mkdirError    error
 	readError     error
@@ -23,6 +30,7 @@ func NewMockFileSystem() *MockFileSystem {
 	return &MockFileSystem{
 		files:       make(map[string]string),
 		directories: make(map[string]bool),
+		modTimes:    make(map[string]time.Time),
 	}
 }
 
@@ -62,21 +70,72 @@ func (m *MockFileSystem) Stat(name string) (filesystem.FileInfo, error) {
 		return nil, m.statError
 	}
 	
-	if _, exists := m.files[name]; exists {
-		return &MockFileInfo{exists: true}, nil
+	if content, exists := m.files[name]; exists {
+		return &MockFileInfo{exists: true, size: int64(len(content)), modTime: m.modTimes[name]}, nil
 	}
 	
-	if _, exists := m.directories[name]; exists {
-		return &MockFileInfo{exists: true}, nil
+	if m.isDir(name) {
+		return &MockFileInfo{exists: true, isDir: true}, nil
 	}
 	
 	return &MockFileInfo{exists: false}, nil
 }
 
+// isDir reports whether name was created as a directory or contains files
+func (m *MockFileSystem) isDir(name string) bool {
+	if _, exists := m.directories[name]; exists {
+		return true
+	}
+	for filename := range m.files {
+		if strings.HasPrefix(filename, name+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *MockFileSystem) ReadDir(name string) ([]os.DirEntry, error) {
+	if m.readError != nil {
+		return nil, m.readError
+	}
+	if !m.isDir(name) {
+		return nil, fmt.Errorf("directory not found: %s", name)
+	}
+
+	children := make(map[string]bool)
+	for filename := range m.files {
+		if rest, ok := strings.CutPrefix(filename, name+"/"); ok {
+			child, _, nested := strings.Cut(rest, "/")
+			children[child] = children[child] || nested
+		}
+	}
+	for dir := range m.directories {
+		if rest, ok := strings.CutPrefix(dir, name+"/"); ok {
+			child, _, _ := strings.Cut(rest, "/")
+			children[child] = true
+		}
+	}
+
+	var entries []os.DirEntry
+	for child, isDir := range children {
+		entries = append(entries, &MockDirEntry{name: child, isDir: isDir})
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+	return entries, nil
+}
+
+func (m *MockFileSystem) SetDir(path string) {
+	m.directories[filepath.Clean(path)] = true
+}
+
 func (m *MockFileSystem) SetFile(filename, content string) {
 	m.files[filename] = content
 }
 
+func (m *MockFileSystem) SetModTime(filename string, modTime time.Time) {
+	m.modTimes[filename] = modTime
+}
+
 func (m *MockFileSystem) SetCreateError(err error) {
 	m.createError = err
 }
@@ -125,13 +184,45 @@ func (w *MockFileWriter) Close() error {
 }
 
 type MockFileInfo struct {
-	exists bool
+	exists  bool
+	isDir   bool
+	size    int64
+	modTime time.Time
 }
 
 func (fi *MockFileInfo) IsExist() bool {
 	return fi.exists
 }
 
+func (fi *MockFileInfo) IsDir() bool {
+	return fi.isDir
+}
+
+func (fi *MockFileInfo) Size() int64 {
+	return fi.size
+}
+
+func (fi *MockFileInfo) ModTime() time.Time {
+	return fi.modTime
+}
+
+type MockDirEntry struct {
+	name  string
+	isDir bool
+}
+
+func (e *MockDirEntry) Name() string               { return e.name }
+func (e *MockDirEntry) IsDir() bool                { return e.isDir }
+func (e *MockDirEntry) Type() fs.FileMode          { return e.Mode() }
+func (e *MockDirEntry) Info() (fs.FileInfo, error) { return nil, fmt.Errorf("not supported") }
+
+func (e *MockDirEntry) Mode() fs.FileMode {
+	if e.isDir {
+		return fs.ModeDir
+	}
+	return 0
+}
+
 type MockLogger struct {
 	messages []string
 }
@@ -140,8 +231,17 @@ func NewMockLogger() *MockLogger {
 	return &MockLogger{}
 }
 
-func (m *MockLogger) Printf(format string, v ...interface{}) {
-	message := fmt.Sprintf(format, v...)
+func (m *MockLogger) Debug(msg string, args ...interface{}) { m.log("DEBUG", msg, args) }
+func (m *MockLogger) Info(msg string, args ...interface{})  { m.log("INFO", msg, args) }
+func (m *MockLogger) Warn(msg string, args ...interface{})  { m.log("WARN", msg, args) }
+func (m *MockLogger) Error(msg string, args ...interface{}) { m.log("ERROR", msg, args) }
+
+// log records "LEVEL msg key=value ..." so tests can match on attributes
+func (m *MockLogger) log(level, msg string, args []interface{}) {
+	message := level + " " + msg
+	for i := 0; i+1 < len(args); i += 2 {
+		message += fmt.Sprintf(" %v=%v", args[i], args[i+1])
+	}
 	m.messages = append(m.messages, message)
 }
 
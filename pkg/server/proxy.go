@@ -0,0 +1,127 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"path/filepath"
+	"strings"
+
+	"mdify/internal/docs"
+	"mdify/internal/filesystem"
+)
+
+// alternateKey carries the markdown alternate of a proxied request to
+// the response hook
+type alternateKey struct{}
+
+// ProxyHandler sits in front of the original HTML site: markdown requests are
+// answered from the converted files and everything else is forwarded upstream
+type ProxyHandler struct {
+	markdown *MarkdownHandler
+	proxy    *httputil.ReverseProxy
+	logger   Logger
+}
+
+// NewProxyHandler creates a reverse proxy to upstream that serves the
+// markdown files under baseDir as alternates of the upstream pages
+func NewProxyHandler(upstream string, baseDir string, fs FileSystem, logger Logger) (*ProxyHandler, error) {
+	target, err := url.Parse(upstream)
+	if err != nil {
+		return nil, fmt.Errorf("invalid upstream URL %s: %w", upstream, err)
+	}
+	if target.Scheme != "http" && target.Scheme != "https" || target.Host == "" {
+		return nil, fmt.Errorf("invalid upstream URL %s: must be an absolute http or https URL", upstream)
+	}
+
+	h := &ProxyHandler{
+		markdown: &MarkdownHandler{baseDir: baseDir, fs: fs, logger: logger},
+		logger:   logger,
+	}
+	h.proxy = &httputil.ReverseProxy{
+		Rewrite: func(r *httputil.ProxyRequest) {
+			r.SetURL(target)
+			r.SetXForwarded()
+		},
+		ModifyResponse: h.addAlternate,
+		ErrorHandler: func(w http.ResponseWriter, r *http.Request, err error) {
+			logger.Error("Proxy request failed", "path", r.URL.Path, "error", err)
+			http.Error(w, "Bad gateway", http.StatusBadGateway)
+		},
+	}
+
+	return h, nil
+}
+
+// ServeHTTP answers markdown requests locally and proxies the rest
+func (h *ProxyHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	filePath, info, found := h.markdown.localMarkdown(r.URL.Path)
+
+	wantsMarkdown := strings.HasSuffix(r.URL.Path, ".md") || acceptsMarkdown(r)
+	if found && wantsMarkdown && (r.Method == http.MethodGet || r.Method == http.MethodHead) {
+		h.markdown.serveFile(w, r, filePath, info, FormatMarkdown)
+		return
+	}
+
+	if found {
+		if rel, err := filepath.Rel(h.markdown.baseDir, filePath); err == nil {
+			alternate := "/" + filepath.ToSlash(rel)
+			r = r.WithContext(context.WithValue(r.Context(), alternateKey{}, alternate))
+		}
+	}
+
+	h.proxy.ServeHTTP(w, r)
+}
+
+// addAlternate advertises the markdown version of upstream HTML pages
+func (h *ProxyHandler) addAlternate(resp *http.Response) error {
+	resp.Header.Add("Vary", "Accept")
+
+	alternate, ok := resp.Request.Context().Value(alternateKey{}).(string)
+	if !ok || !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/html") {
+		return nil
+	}
+
+	resp.Header.Add("Link", fmt.Sprintf(`<%s>; rel="alternate"; type="text/markdown"`, alternate))
+	return nil
+}
+
+// acceptsMarkdown reports whether the client explicitly asked for markdown
+func acceptsMarkdown(r *http.Request) bool {
+	accept := r.Header.Get("Accept")
+	return strings.Contains(accept, "text/markdown") || strings.Contains(accept, "text/x-markdown")
+}
+
+// localMarkdown finds the converted file for an upstream URL path. Directory
+// URLs match either dir/index.md or dir.md, depending on how they were saved.
+func (h *MarkdownHandler) localMarkdown(urlPath string) (string, filesystem.FileInfo, bool) {
+	requestPath := strings.TrimPrefix(urlPath, "/")
+	if docs.IsHidden(requestPath) {
+		return "", nil, false
+	}
+
+	var candidates []string
+	switch {
+	case requestPath == "":
+		candidates = []string{"index.md"}
+	case strings.HasSuffix(requestPath, ".md"):
+		candidates = []string{requestPath}
+	case strings.HasSuffix(requestPath, "/"):
+		candidates = []string{requestPath + "index.md", strings.TrimSuffix(requestPath, "/") + ".md"}
+	default:
+		candidates = []string{requestPath + ".md", requestPath + "/index.md"}
+	}
+
+	for _, candidate := range candidates {
+		filePath, ok := h.resolve(candidate)
+		if !ok {
+			return "", nil, false
+		}
+		if info, err := h.fs.Stat(filePath); err == nil && info.IsExist() && !info.IsDir() {
+			return filePath, info, true
+		}
+	}
+	return "", nil, false
+}
@@ -0,0 +1,220 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func newTestUpstream() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/logo.png":
+			w.Header().Set("Content-Type", "image/png")
+			w.Write([]byte("png"))
+		default:
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			fmt.Fprintf(w, "<h1>upstream %s %s</h1>", r.URL.Path, r.Host)
+		}
+	}))
+}
+
+func TestProxyHandler(t *testing.T) {
+	upstream := newTestUpstream()
+	defer upstream.Close()
+
+	fs := NewMockFileSystem()
+	fs.SetDir("/base")
+	fs.SetFile("/base/index.md", "# Home")
+	fs.SetFile("/base/docs/intro.md", "# Introduction")
+	fs.SetFile("/base/docs/guides/index.md", "# Guides")
+
+	handler, err := NewProxyHandler(upstream.URL, "/base", fs, NewMockLogger())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		path         string
+		accept       string
+		expectedBody string
+		expectedType string
+		expectedLink string
+		expectedVary bool
+	}{
+		{
+			name:         "html page with alternate",
+			path:         "/docs/intro",
+			accept:       "text/html",
+			expectedBody: "<h1>upstream /docs/intro",
+			expectedLink: `</docs/intro.md>; rel="alternate"; type="text/markdown"`,
+			expectedVary: true,
+		},
+		{
+			name:         "directory page with alternate",
+			path:         "/docs/guides/",
+			expectedBody: "<h1>upstream /docs/guides/",
+			expectedLink: `</docs/guides/index.md>; rel="alternate"; type="text/markdown"`,
+			expectedVary: true,
+		},
+		{
+			name:         "html page without markdown",
+			path:         "/pricing",
+			expectedBody: "<h1>upstream /pricing",
+			expectedVary: true,
+		},
+		{
+			name:         "non-html response",
+			path:         "/logo.png",
+			expectedBody: "png",
+			expectedType: "image/png",
+		},
+		{
+			name:         "md suffix",
+			path:         "/docs/intro.md",
+			accept:       "text/html",
+			expectedBody: "# Introduction",
+			expectedType: "text/markdown; charset=utf-8",
+			expectedVary: true,
+		},
+		{
+			name:         "accept markdown",
+			path:         "/docs/intro",
+			accept:       "text/markdown, text/html;q=0.5",
+			expectedBody: "# Introduction",
+			expectedType: "text/markdown; charset=utf-8",
+			expectedVary: true,
+		},
+		{
+			name:         "accept markdown at root",
+			path:         "/",
+			accept:       "text/markdown",
+			expectedBody: "# Home",
+		},
+		{
+			name:         "accept markdown without local file",
+			path:         "/pricing",
+			accept:       "text/markdown",
+			expectedBody: "<h1>upstream /pricing",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", tt.path, nil)
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			w := httptest.NewRecorder()
+
+			handler.ServeHTTP(w, req)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("expected status 200, got %d", w.Code)
+			}
+			if !strings.HasPrefix(w.Body.String(), tt.expectedBody) {
+				t.Errorf("expected body to start with %q, got %q", tt.expectedBody, w.Body.String())
+			}
+			if tt.expectedType != "" && w.Header().Get("Content-Type") != tt.expectedType {
+				t.Errorf("expected Content-Type %q, got %q", tt.expectedType, w.Header().Get("Content-Type"))
+			}
+			if link := w.Header().Get("Link"); link != tt.expectedLink {
+				t.Errorf("expected Link %q, got %q", tt.expectedLink, link)
+			}
+			if tt.expectedVary && w.Header().Get("Vary") != "Accept" {
+				t.Errorf("expected Vary: Accept, got %q", w.Header().Get("Vary"))
+			}
+		})
+	}
+}
+
+func TestProxyHandler_UpstreamHost(t *testing.T) {
+	upstream := newTestUpstream()
+	defer upstream.Close()
+
+	handler, err := NewProxyHandler(upstream.URL, "/base", NewMockFileSystem(), NewMockLogger())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	req := httptest.NewRequest("GET", "http://mirror.example.com/page", nil)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	expected := "<h1>upstream /page " + strings.TrimPrefix(upstream.URL, "http://")
+	if !strings.HasPrefix(w.Body.String(), expected) {
+		t.Errorf("expected upstream to see its own host, got %q", w.Body.String())
+	}
+}
+
+func TestProxyHandler_StripsCredentials(t *testing.T) {
+	var seen []string
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = append(seen, r.Header.Get("Authorization"))
+	}))
+	defer upstream.Close()
+
+	fs := NewMockFileSystem()
+	fs.SetFile("/etc/mdify.htpasswd", "bob:{SHA}W6ph5Mm5Pz8GgiULbPgzG37mj9g=\n")
+	auth, err := newAuthenticator(fs, []string{"team-token"}, "/etc/mdify.htpasswd", NewMockLogger())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	proxy, err := NewProxyHandler(upstream.URL, "/base", fs, NewMockLogger())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	handler := auth.wrap(proxy)
+
+	for _, setup := range []func(r *http.Request){
+		func(r *http.Request) { r.Header.Set("Authorization", "Bearer team-token") },
+		func(r *http.Request) { r.SetBasicAuth("bob", "password") },
+	} {
+		req := httptest.NewRequest("GET", "/page", nil)
+		setup(req)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d", w.Code)
+		}
+		if req.Header.Get("Authorization") == "" {
+			t.Error("expected the caller's request to be left alone")
+		}
+	}
+
+	if len(seen) != 2 || seen[0] != "" || seen[1] != "" {
+		t.Errorf("expected the upstream never to see mdify's credentials, got %q", seen)
+	}
+}
+
+func TestNewProxyHandler_InvalidUpstream(t *testing.T) {
+	for _, upstream := range []string{"", "docs.example.com", "ftp://docs.example.com", "://bad"} {
+		if _, err := NewProxyHandler(upstream, "/base", NewMockFileSystem(), NewMockLogger()); err == nil {
+			t.Errorf("expected error for upstream %q", upstream)
+		}
+	}
+}
+
+func TestProxyHandler_UpstreamDown(t *testing.T) {
+	upstream := newTestUpstream()
+	upstream.Close()
+
+	logger := NewMockLogger()
+	handler, err := NewProxyHandler(upstream.URL, "/base", NewMockFileSystem(), logger)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/page", nil))
+
+	if w.Code != http.StatusBadGateway {
+		t.Errorf("expected status 502, got %d", w.Code)
+	}
+	if !strings.Contains(logger.GetLastMessage(), "ERROR Proxy request failed path=/page") {
+		t.Errorf("expected proxy error to be logged, got %q", logger.GetLastMessage())
+	}
+}
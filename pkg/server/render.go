@@ -0,0 +1,148 @@
+package server
+
+import (
+	"bytes"
+	"html/template"
+	"net/http"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer/html"
+
+	"mdify/internal/docs"
+)
+
+// markdownRenderer converts documents to HTML, omitting any raw HTML so
+// scraped pages can't run scripts on the server's origin
+var markdownRenderer = goldmark.New(
+	goldmark.WithExtensions(extension.GFM),
+	goldmark.WithParserOptions(parser.WithAutoHeadingID()),
+)
+
+// unsafeMarkdownRenderer passes raw HTML through, so tables the scraper kept
+// as HTML render; only for docs whose sources are trusted
+var unsafeMarkdownRenderer = goldmark.New(
+	goldmark.WithExtensions(extension.GFM),
+	goldmark.WithParserOptions(parser.WithAutoHeadingID()),
+	goldmark.WithRendererOptions(html.WithUnsafe()),
+)
+
+// Page is the data passed to the HTML page template
+type Page struct {
+	Title      string
+	Path       string
+	Content    template.HTML
+	Sidebar    *Listing
+	LiveReload template.HTML // script that reloads the page on changes, when watching
+}
+
+var pageTemplate = template.Must(template.New("page").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}}</title>
+<link rel="alternate" type="text/markdown" href="{{.Path}}?format=markdown">
+<style>
+body { margin: 0; font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", Helvetica, Arial, sans-serif; line-height: 1.6; color: #1f2328; }
+.layout { display: flex; min-height: 100vh; }
+nav { flex: 0 0 16rem; padding: 1.5rem; background: #f6f8fa; border-right: 1px solid #d0d7de; font-size: 0.9rem; }
+nav ul { list-style: none; padding: 0; }
+nav a { color: #0969da; text-decoration: none; }
+main { flex: 1; max-width: 52rem; padding: 1.5rem 2rem; }
+pre { background: #f6f8fa; padding: 1rem; overflow-x: auto; border-radius: 6px; }
+code { font-family: ui-monospace, SFMono-Regular, Menlo, monospace; font-size: 0.875em; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #d0d7de; padding: 0.4rem 0.8rem; }
+</style>
+</head>
+<body>
+<div class="layout">
+{{with .Sidebar}}<nav>
+<a href="/">Home</a>
+<ul>
+{{if ne .Path "/"}}<li><a href="../">../</a></li>
+{{end}}{{range .Entries}}<li><a href="{{.Path}}">{{if .Title}}{{.Title}}{{else}}{{.Name}}{{end}}</a>{{if .IsDir}}/{{end}}</li>
+{{end}}</ul>
+</nav>
+{{end}}<main>
+{{.Content}}
+</main>
+</div>
+{{with .LiveReload}}{{.}}
+{{end}}</body>
+</html>
+`))
+
+// renderMarkdown converts a markdown document to HTML, dropping any front
+// matter. Raw HTML is omitted unless unsafe is set.
+func renderMarkdown(content []byte, unsafe bool) (template.HTML, error) {
+	renderer := markdownRenderer
+	if unsafe {
+		renderer = unsafeMarkdownRenderer
+	}
+
+	var buf bytes.Buffer
+	if err := renderer.Convert(stripFrontMatter(content), &buf); err != nil {
+		return "", err
+	}
+	return template.HTML(buf.String()), nil
+}
+
+// stripFrontMatter removes a leading YAML front matter block
+func stripFrontMatter(content []byte) []byte {
+	lines := strings.SplitAfter(string(content), "\n")
+	if strings.TrimSpace(lines[0]) != "---" {
+		return content
+	}
+
+	for i := 1; i < len(lines); i++ {
+		if strings.TrimSpace(lines[i]) == "---" {
+			return []byte(strings.Join(lines[i+1:], ""))
+		}
+	}
+
+	return content
+}
+
+// renderPage renders a markdown file as an HTML page with a sidebar listing
+// the other documents in its directory
+func (h *MarkdownHandler) renderPage(r *http.Request, filePath string, content []byte) ([]byte, error) {
+	body, err := renderMarkdown(content, h.unsafeHTML)
+	if err != nil {
+		return nil, err
+	}
+
+	page := Page{
+		Title:   docs.Title(content),
+		Path:    r.URL.Path,
+		Content: body,
+	}
+	if page.Title == "" {
+		page.Title = strings.TrimSuffix(filepath.Base(filePath), ".md")
+	}
+	if h.liveReload {
+		page.LiveReload = liveReloadScript
+	}
+
+	dirPath := filepath.Dir(filePath)
+	if rel, err := filepath.Rel(h.baseDir, dirPath); err == nil {
+		urlDir := "/"
+		if rel != "." {
+			urlDir = path.Join("/", filepath.ToSlash(rel)) + "/"
+		}
+		if listing, err := h.listDirectory(dirPath, urlDir); err == nil {
+			page.Sidebar = listing
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := pageTemplate.Execute(&buf, page); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,174 @@
+package server
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMarkdownHandler_ContentNegotiation(t *testing.T) {
+	tests := []struct {
+		name            string
+		requestPath     string
+		accept          string
+		expectedType    string
+		expectedContent []string
+	}{
+		{
+			name:            "raw markdown for API clients",
+			requestPath:     "/docs/intro",
+			accept:          "*/*",
+			expectedType:    "text/markdown; charset=utf-8",
+			expectedContent: []string{"# Introduction\n\nHello"},
+		},
+		{
+			name:         "html for browsers",
+			requestPath:  "/docs/intro",
+			accept:       "text/html,application/xhtml+xml,*/*;q=0.8",
+			expectedType: "text/html; charset=utf-8",
+			expectedContent: []string{
+				"<title>Introduction</title>",
+				`<h1 id="introduction">Introduction</h1>`,
+				`<pre><code class="language-bash"># not a title`,
+				`<a href="/docs/api">API Reference</a>`,
+			},
+		},
+		{
+			name:            "format parameter forces html",
+			requestPath:     "/docs/api?format=html",
+			expectedType:    "text/html; charset=utf-8",
+			expectedContent: []string{"<title>API Reference</title>", `<h1 id="ignored">Ignored</h1>`},
+		},
+		{
+			name:            "format parameter forces markdown",
+			requestPath:     "/docs/api?format=markdown",
+			accept:          "text/html",
+			expectedType:    "text/markdown; charset=utf-8",
+			expectedContent: []string{"title: \"API Reference\""},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := &MarkdownHandler{baseDir: "/base", fs: newListingFS(), logger: NewMockLogger()}
+
+			req := httptest.NewRequest("GET", tt.requestPath, nil)
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			w := httptest.NewRecorder()
+
+			handler.ServeHTTP(w, req)
+
+			if w.Code != 200 {
+				t.Fatalf("expected status 200, got %d", w.Code)
+			}
+			if contentType := w.Header().Get("Content-Type"); contentType != tt.expectedType {
+				t.Errorf("expected content type %q, got %q", tt.expectedType, contentType)
+			}
+			if vary := w.Header().Get("Vary"); vary != "Accept" {
+				t.Errorf("expected Vary: Accept, got %q", vary)
+			}
+
+			body := w.Body.String()
+			for _, expected := range tt.expectedContent {
+				if !strings.Contains(body, expected) {
+					t.Errorf("expected body to contain %q, got %q", expected, body)
+				}
+			}
+		})
+	}
+}
+
+func TestMarkdownHandler_UnsafeHTML(t *testing.T) {
+	fs := newListingFS()
+	fs.SetFile("/base/raw.md", "# Raw\n\n<script>alert(1)</script>\n")
+
+	for _, unsafe := range []bool{false, true} {
+		handler := &MarkdownHandler{baseDir: "/base", fs: fs, logger: NewMockLogger(), unsafeHTML: unsafe}
+		req := httptest.NewRequest("GET", "/raw?format=html", nil)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		if hasScript := strings.Contains(w.Body.String(), "<script>alert(1)</script>"); hasScript != unsafe {
+			t.Errorf("with unsafeHTML %v, expected the script in the page: %v, got %q", unsafe, unsafe, w.Body.String())
+		}
+	}
+}
+
+func TestRenderMarkdown(t *testing.T) {
+	content := "---\ntitle: Test\n---\n\n| a | b |\n| --- | --- |\n| 1 | 2 |\n"
+
+	html, err := renderMarkdown([]byte(content), false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if strings.Contains(string(html), "title: Test") {
+		t.Errorf("expected front matter to be stripped, got %q", html)
+	}
+	if !strings.Contains(string(html), "<table>") {
+		t.Errorf("expected GFM table to render, got %q", html)
+	}
+}
+
+func TestRenderMarkdown_RawHTML(t *testing.T) {
+	content := "# Page\n\n<script>alert(1)</script>\n\n<table><tr><td rowspan=\"2\">a</td></tr></table>\n\nText <img src=x onerror=alert(1)>\n"
+
+	tests := []struct {
+		name     string
+		unsafe   bool
+		expected []string
+		absent   []string
+	}{
+		{
+			name:     "omitted by default",
+			expected: []string{"<h1 id=\"page\">Page</h1>", "raw HTML omitted"},
+			absent:   []string{"<script>", "<table>", "onerror"},
+		},
+		{
+			name:     "passed through when unsafe",
+			unsafe:   true,
+			expected: []string{"<script>alert(1)</script>", "<td rowspan=\"2\">"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			html, err := renderMarkdown([]byte(content), tt.unsafe)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			for _, expected := range tt.expected {
+				if !strings.Contains(string(html), expected) {
+					t.Errorf("expected %q in %q", expected, html)
+				}
+			}
+			for _, absent := range tt.absent {
+				if strings.Contains(string(html), absent) {
+					t.Errorf("expected %q to be omitted from %q", absent, html)
+				}
+			}
+		})
+	}
+}
+
+func TestStripFrontMatter(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected string
+	}{
+		{"front matter", "---\ntitle: x\n---\n# Body", "# Body"},
+		{"no front matter", "# Body\n---\n", "# Body\n---\n"},
+		{"unterminated", "---\ntitle: x\n", "---\ntitle: x\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := string(stripFrontMatter([]byte(tt.content))); got != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
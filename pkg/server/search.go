@@ -0,0 +1,423 @@
+package server
+
+import (
+	"encoding/json"
+	"html"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	"mdify/internal/docs"
+)
+
+// BM25 tuning parameters
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+const (
+	titleWeight       = 3 // title terms count this many times toward term frequency
+	snippetRadius     = 80
+	maxSnippets       = 2
+	defaultSearchSize = 10
+	maxSearchSize     = 100
+)
+
+var stopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true, "be": true,
+	"by": true, "for": true, "from": true, "in": true, "is": true, "it": true, "of": true,
+	"on": true, "or": true, "that": true, "the": true, "this": true, "to": true, "with": true,
+}
+
+// SearchResult is a single document matching a search query
+type SearchResult struct {
+	Path     string   `json:"path"`
+	Title    string   `json:"title"`
+	Score    float64  `json:"score"`
+	Snippets []string `json:"snippets"`
+}
+
+// SearchResponse is the JSON body returned by the search endpoint
+type SearchResponse struct {
+	Query   string         `json:"query"`
+	Total   int            `json:"total"`
+	Results []SearchResult `json:"results"`
+}
+
+type indexedDocument struct {
+	path    string
+	title   string
+	content string
+	length  int
+}
+
+// SearchIndex is an in-memory inverted index over the markdown files in a
+// directory, ranked with BM25
+type SearchIndex struct {
+	fs      FileSystem
+	baseDir string
+	logger  Logger
+
+	mu        sync.RWMutex
+	documents []indexedDocument
+	postings  map[string]map[int]int // term -> document -> term frequency
+	avgLength float64
+	signature uint64
+}
+
+// NewSearchIndex creates an empty index for the markdown files under baseDir
+func NewSearchIndex(fs FileSystem, baseDir string, logger Logger) *SearchIndex {
+	return &SearchIndex{
+		fs:       fs,
+		baseDir:  baseDir,
+		logger:   logger,
+		postings: make(map[string]map[int]int),
+	}
+}
+
+// Build reads every markdown file and replaces the index contents
+func (idx *SearchIndex) Build() error {
+	files, err := docs.Walk(idx.fs, idx.baseDir)
+	if err != nil {
+		return err
+	}
+
+	documents := make([]indexedDocument, 0, len(files))
+	postings := make(map[string]map[int]int)
+	totalLength := 0
+
+	for _, file := range files {
+		content, err := idx.fs.ReadFile(file.Path)
+		if err != nil {
+			idx.logger.Error("Failed to index document", "path", file.Path, "error", err)
+			continue
+		}
+
+		text := string(stripFrontMatter(content))
+		doc := indexedDocument{
+			path:    docs.URL(file.Rel),
+			title:   docs.Title(content),
+			content: text,
+		}
+		id := len(documents)
+
+		terms := tokenize(text)
+		for _, term := range tokenize(doc.title) {
+			for i := 0; i < titleWeight; i++ {
+				terms = append(terms, term)
+			}
+		}
+		for _, term := range terms {
+			if postings[term] == nil {
+				postings[term] = make(map[int]int)
+			}
+			postings[term][id]++
+		}
+
+		doc.length = len(terms)
+		totalLength += doc.length
+		documents = append(documents, doc)
+	}
+
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	idx.documents = documents
+	idx.postings = postings
+	idx.signature = filesSignature(files)
+	idx.avgLength = 0
+	if len(documents) > 0 {
+		idx.avgLength = float64(totalLength) / float64(len(documents))
+	}
+
+	return nil
+}
+
+// Refresh rebuilds the index if any markdown file was added, removed or changed
+func (idx *SearchIndex) Refresh() error {
+	files, err := docs.Walk(idx.fs, idx.baseDir)
+	if err != nil {
+		return err
+	}
+
+	idx.mu.RLock()
+	unchanged := filesSignature(files) == idx.signature
+	idx.mu.RUnlock()
+
+	if unchanged {
+		return nil
+	}
+
+	if err := idx.Build(); err != nil {
+		return err
+	}
+	idx.logger.Info("Reindexed documents", "count", idx.Len())
+	return nil
+}
+
+// Len returns the number of indexed documents
+func (idx *SearchIndex) Len() int {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	return len(idx.documents)
+}
+
+// Search returns up to limit documents matching the query, best first
+func (idx *SearchIndex) Search(query string, limit int) []SearchResult {
+	results, _ := idx.search(query, limit)
+	return results
+}
+
+// search returns up to limit documents matching the query and how many
+// matched in all. Snippets are only built for the documents returned.
+func (idx *SearchIndex) search(query string, limit int) ([]SearchResult, int) {
+	terms := uniqueTerms(tokenize(query))
+	if len(terms) == 0 {
+		return []SearchResult{}, 0
+	}
+
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	n := float64(len(idx.documents))
+	scores := make(map[int]float64)
+	for _, term := range terms {
+		postings := idx.postings[term]
+		if len(postings) == 0 {
+			continue
+		}
+
+		df := float64(len(postings))
+		idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+		for id, tf := range postings {
+			length := float64(idx.documents[id].length)
+			norm := float64(tf) * (bm25K1 + 1) / (float64(tf) + bm25K1*(1-bm25B+bm25B*length/idx.avgLength))
+			scores[id] += idf * norm
+		}
+	}
+
+	type match struct {
+		id    int
+		score float64
+	}
+	matches := make([]match, 0, len(scores))
+	for id, score := range scores {
+		matches = append(matches, match{id, math.Round(score*1000) / 1000})
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].score != matches[j].score {
+			return matches[i].score > matches[j].score
+		}
+		return idx.documents[matches[i].id].path < idx.documents[matches[j].id].path
+	})
+
+	total := len(matches)
+	if limit > 0 && len(matches) > limit {
+		matches = matches[:limit]
+	}
+
+	results := make([]SearchResult, 0, len(matches))
+	for _, m := range matches {
+		doc := idx.documents[m.id]
+		results = append(results, SearchResult{
+			Path:     doc.path,
+			Title:    doc.title,
+			Score:    m.score,
+			Snippets: snippets(doc.content, terms),
+		})
+	}
+	return results, total
+}
+
+// ServeHTTP handles GET /_search?q=...&limit=...
+func (idx *SearchIndex) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+	if query == "" {
+		http.Error(w, "Missing query parameter q", http.StatusBadRequest)
+		return
+	}
+
+	limit := defaultSearchSize
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 {
+			http.Error(w, "Invalid limit", http.StatusBadRequest)
+			return
+		}
+		limit = min(n, maxSearchSize)
+	}
+
+	results, total := idx.search(query, limit)
+	response := SearchResponse{Query: query, Total: total, Results: results}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// tokenize splits text into lowercase terms, dropping stop words
+func tokenize(text string) []string {
+	fields := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+
+	terms := fields[:0]
+	for _, field := range fields {
+		if !stopWords[field] {
+			terms = append(terms, field)
+		}
+	}
+	return terms
+}
+
+func uniqueTerms(terms []string) []string {
+	seen := make(map[string]bool, len(terms))
+	unique := terms[:0]
+	for _, term := range terms {
+		if !seen[term] {
+			seen[term] = true
+			unique = append(unique, term)
+		}
+	}
+	return unique
+}
+
+// snippets extracts short passages around the first occurrences of the
+// query terms, with matches wrapped in <mark> and everything else escaped
+func snippets(content string, terms []string) []string {
+	var windows [][2]int
+	for _, term := range terms {
+		pos, length := indexWord(content, term)
+		if pos < 0 {
+			continue
+		}
+
+		start := max(0, pos-snippetRadius)
+		end := min(len(content), pos+length+snippetRadius)
+		if len(windows) > 0 && start <= windows[len(windows)-1][1] {
+			windows[len(windows)-1][1] = max(end, windows[len(windows)-1][1])
+		} else {
+			windows = append(windows, [2]int{start, end})
+		}
+		if len(windows) == maxSnippets {
+			break
+		}
+	}
+
+	result := make([]string, 0, len(windows))
+	for _, window := range windows {
+		start, end := alignToRunes(content, window[0]), alignToRunes(content, window[1])
+		snippet := highlight(content[start:end], terms)
+		if start > 0 {
+			snippet = "…" + snippet
+		}
+		if end < len(content) {
+			snippet += "…"
+		}
+		result = append(result, snippet)
+	}
+	return result
+}
+
+// indexWord finds the lowercase term in text, ignoring case, where it isn't
+// part of a longer word, and returns its offset and length in text. Matching
+// rune by rune against text itself keeps the offsets valid where lowercasing
+// would change the length of the text.
+func indexWord(text, term string) (int, int) {
+	for i := 0; i < len(text); {
+		if length := matchWord(text, i, term); length > 0 {
+			return i, length
+		}
+		_, size := utf8.DecodeRuneInString(text[i:])
+		i += size
+	}
+	return -1, 0
+}
+
+// matchWord returns the length in text of a whole-word match of term at i,
+// ignoring case, or 0 when there is none
+func matchWord(text string, i int, term string) int {
+	if before, _ := utf8.DecodeLastRuneInString(text[:i]); i > 0 && isWordRune(before) {
+		return 0
+	}
+
+	end := i
+	for _, want := range term {
+		if end >= len(text) {
+			return 0
+		}
+		r, size := utf8.DecodeRuneInString(text[end:])
+		if !equalFoldRune(r, want) {
+			return 0
+		}
+		end += size
+	}
+
+	if after, _ := utf8.DecodeRuneInString(text[end:]); end < len(text) && isWordRune(after) {
+		return 0
+	}
+	return end - i
+}
+
+// equalFoldRune reports whether a and b are the same letter ignoring case
+func equalFoldRune(a, b rune) bool {
+	if a == b {
+		return true
+	}
+	for f := unicode.SimpleFold(a); f != a; f = unicode.SimpleFold(f) {
+		if f == b {
+			return true
+		}
+	}
+	return false
+}
+
+// isWordRune matches the characters tokenize keeps in terms
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsNumber(r)
+}
+
+// alignToRunes moves i back to the start of a UTF-8 sequence
+func alignToRunes(s string, i int) int {
+	for i > 0 && i < len(s) && s[i]&0xC0 == 0x80 {
+		i--
+	}
+	return i
+}
+
+// highlight escapes text and wraps whole-word matches of terms in <mark>
+func highlight(text string, terms []string) string {
+	var b strings.Builder
+
+	for i := 0; i < len(text); {
+		length := 0
+		for _, term := range terms {
+			if length = matchWord(text, i, term); length > 0 {
+				break
+			}
+		}
+
+		if length > 0 {
+			b.WriteString("<mark>" + html.EscapeString(text[i:i+length]) + "</mark>")
+			i += length
+			continue
+		}
+
+		_, size := utf8.DecodeRuneInString(text[i:])
+		b.WriteString(html.EscapeString(text[i : i+size]))
+		i += size
+	}
+
+	return strings.ReplaceAll(b.String(), "\n", " ")
+}
@@ -0,0 +1,201 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func newSearchFS() *MockFileSystem {
+	fs := NewMockFileSystem()
+	fs.SetDir("/base")
+	fs.SetFile("/base/index.md", "# Home\n\nWelcome to the docs.")
+	fs.SetFile("/base/docs/routing.md", "# Routing\n\nThe router matches paths. Routing rules are evaluated in order, and routing is fast.")
+	fs.SetFile("/base/docs/auth.md", "---\ntitle: Authentication\n---\n\nUse a bearer token. The router checks tokens before routing <requests>.")
+	fs.SetFile("/base/docs/guides/index.md", "# Guides\n\nStep by step guides.")
+	return fs
+}
+
+func TestSearchIndex_Search(t *testing.T) {
+	index := NewSearchIndex(newSearchFS(), "/base", NewMockLogger())
+	if err := index.Build(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if index.Len() != 4 {
+		t.Fatalf("expected 4 documents, got %d", index.Len())
+	}
+
+	results := index.Search("routing", 10)
+	if len(results) != 2 {
+		t.Fatalf("expected 2 results, got %d: %+v", len(results), results)
+	}
+	if results[0].Path != "/docs/routing" || results[0].Title != "Routing" {
+		t.Errorf("expected routing page to rank first, got %+v", results[0])
+	}
+	if results[0].Score <= results[1].Score {
+		t.Errorf("expected descending scores, got %v and %v", results[0].Score, results[1].Score)
+	}
+
+	auth := results[1]
+	if auth.Title != "Authentication" {
+		t.Errorf("expected title from front matter, got %q", auth.Title)
+	}
+	if len(auth.Snippets) == 0 || !strings.Contains(auth.Snippets[0], "<mark>routing</mark> &lt;requests&gt;") {
+		t.Errorf("expected escaped, highlighted snippet, got %v", auth.Snippets)
+	}
+
+	if results := index.Search("guides", 10); len(results) != 1 || results[0].Path != "/docs/guides/" {
+		t.Errorf("expected index.md to map to its directory, got %+v", results)
+	}
+	if results := index.Search("the and of", 10); len(results) != 0 {
+		t.Errorf("expected stop words to match nothing, got %+v", results)
+	}
+	if results := index.Search("router token", 1); len(results) != 1 {
+		t.Errorf("expected limit to apply, got %d results", len(results))
+	}
+}
+
+func TestSearchIndex_Refresh(t *testing.T) {
+	fs := newSearchFS()
+	index := NewSearchIndex(fs, "/base", NewMockLogger())
+	if err := index.Build(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	fs.SetFile("/base/docs/deploy.md", "# Deploying\n\nShip it.")
+	if err := index.Refresh(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if results := index.Search("deploying", 10); len(results) != 1 {
+		t.Errorf("expected new document to be indexed, got %+v", results)
+	}
+}
+
+func TestSearchIndex_ServeHTTP(t *testing.T) {
+	index := NewSearchIndex(newSearchFS(), "/base", NewMockLogger())
+	if err := index.Build(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name            string
+		method          string
+		url             string
+		expectedStatus  int
+		expectedTotal   int
+		expectedResults int // defaults to expectedTotal
+	}{
+		{name: "search", method: "GET", url: "/_search?q=token", expectedStatus: 200, expectedTotal: 1},
+		{name: "total beyond limit", method: "GET", url: "/_search?q=routing&limit=1", expectedStatus: 200, expectedTotal: 2, expectedResults: 1},
+		{name: "no matches", method: "GET", url: "/_search?q=kubernetes", expectedStatus: 200, expectedTotal: 0},
+		{name: "missing query", method: "GET", url: "/_search", expectedStatus: 400},
+		{name: "invalid limit", method: "GET", url: "/_search?q=token&limit=x", expectedStatus: 400},
+		{name: "wrong method", method: "POST", url: "/_search?q=token", expectedStatus: 405},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.url, nil)
+			w := httptest.NewRecorder()
+
+			index.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+			if tt.expectedStatus != 200 {
+				return
+			}
+
+			var response SearchResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			expectedResults := tt.expectedResults
+			if expectedResults == 0 {
+				expectedResults = tt.expectedTotal
+			}
+			if response.Total != tt.expectedTotal || len(response.Results) != expectedResults {
+				t.Errorf("expected %d results, got %+v", tt.expectedTotal, response)
+			}
+		})
+	}
+}
+
+func TestSnippets_NonASCII(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		terms    []string
+		expected []string
+	}{
+		{
+			// "Ⱥ" is 2 bytes but lowercases to 3, so offsets in the lowercased
+			// text run past the original
+			name:     "text that grows when lowercased",
+			content:  strings.Repeat("Ⱥ", 200) + " golang here",
+			terms:    []string{"golang"},
+			expected: []string{"<mark>golang</mark> here"},
+		},
+		{
+			// "İ" is 2 bytes but lowercases to 3
+			name:     "offsets after dotted capital I",
+			content:  "İİİİ " + strings.Repeat("x ", 50) + "the Golang docs",
+			terms:    []string{"golang"},
+			expected: []string{"the <mark>Golang</mark> docs"},
+		},
+		{
+			name:     "case-insensitive non-ASCII match",
+			content:  "Über ÜNÏCODE support",
+			terms:    []string{"ünïcode"},
+			expected: []string{"Über <mark>ÜNÏCODE</mark> support"},
+		},
+		{
+			name:     "not part of a longer word",
+			content:  "golangé golang",
+			terms:    []string{"golang"},
+			expected: []string{"golangé <mark>golang</mark>"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := snippets(tt.content, tt.terms)
+			if len(result) != len(tt.expected) {
+				t.Fatalf("expected %d snippets, got %q", len(tt.expected), result)
+			}
+			for i, expected := range tt.expected {
+				if !strings.Contains(result[i], expected) {
+					t.Errorf("expected snippet to contain %q, got %q", expected, result[i])
+				}
+			}
+		})
+	}
+}
+
+func TestSearchIndex_Search_NonASCII(t *testing.T) {
+	fs := NewMockFileSystem()
+	fs.SetDir("/base")
+	fs.SetFile("/base/index.md", "# Home\n\n"+strings.Repeat("Ⱥ", 200)+" golang here")
+
+	index := NewSearchIndex(fs, "/base", NewMockLogger())
+	if err := index.Build(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	results := index.Search("golang", 10)
+	if len(results) != 1 || len(results[0].Snippets) != 1 || !strings.HasSuffix(results[0].Snippets[0], "<mark>golang</mark> here") {
+		t.Errorf("expected a snippet ending at the match, got %+v", results)
+	}
+}
+
+func TestTokenize(t *testing.T) {
+	terms := tokenize("The Router's config_file, v2.0 and Ünïcode!")
+	expected := []string{"router", "s", "config", "file", "v2", "0", "ünïcode"}
+
+	if strings.Join(terms, " ") != strings.Join(expected, " ") {
+		t.Errorf("expected %v, got %v", expected, terms)
+	}
+}
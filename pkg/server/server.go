@@ -1,102 +1,393 @@
 package server
 
 import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"io"
 	"net/http"
+	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
+	"mdify/internal/docs"
 	"mdify/internal/filesystem"
 )
 
+// Polling intervals for detecting changes in the served directory
+const (
+	indexRefreshInterval = 10 * time.Second
+	watchPollInterval    = time.Second
+)
+
+// Defaults for the listener and HTTP server timeouts
+const (
+	defaultAddr            = "127.0.0.1:8080"
+	defaultReadTimeout     = 30 * time.Second
+	defaultWriteTimeout    = 60 * time.Second
+	defaultIdleTimeout     = 120 * time.Second
+	defaultShutdownTimeout = 10 * time.Second
+	readHeaderTimeout      = 10 * time.Second
+)
+
 type FileSystem interface {
 	ReadFile(filename string) ([]byte, error)
+	ReadDir(name string) ([]os.DirEntry, error)
 	Stat(name string) (filesystem.FileInfo, error)
 }
 
+// Logger interface for leveled, structured logging; *slog.Logger satisfies it
 type Logger interface {
-	Printf(format string, v ...interface{})
+	Debug(msg string, args ...interface{})
+	Info(msg string, args ...interface{})
+	Warn(msg string, args ...interface{})
+	Error(msg string, args ...interface{})
+}
+
+// Config holds configuration for the server
+type Config struct {
+	Addr            string        // host:port or unix:/path/to.sock; port 0 picks a free port
+	ReadTimeout     time.Duration // maximum duration for reading a request
+	WriteTimeout    time.Duration // maximum duration for writing a response
+	IdleTimeout     time.Duration // how long keep-alive connections stay open
+	ShutdownTimeout time.Duration // how long to drain connections on shutdown
+	TLSCertFile     string        // PEM certificate; serves HTTPS together with TLSKeyFile
+	TLSKeyFile      string        // PEM private key for TLSCertFile
+	SelfSignedTLS   bool          // serve HTTPS with a certificate generated on startup
+	AuthTokens      []string      // bearer tokens that grant access
+	HtpasswdFile    string        // htpasswd file of users allowed in with basic auth
+	Watch           bool          // push reload events to browsers and disable caching
+	PollInterval    time.Duration // how often to check for changes (default 1s when watching, 10s otherwise)
+	Upstream        string        // original site to reverse-proxy non-markdown requests to
+	AccessLog       io.Writer     // destination for JSON access log lines; nil disables them
+	UnsafeHTML      bool          // render raw HTML in documents instead of omitting it
 }
 
 type Service struct {
 	fs     FileSystem
 	logger Logger
+	config Config
 }
 
 type MarkdownHandler struct {
-	baseDir string
-	fs      FileSystem
-	logger  Logger
+	baseDir    string
+	fs         FileSystem
+	logger     Logger
+	liveReload bool
+	private    bool // responses require authentication
+	unsafeHTML bool // render raw HTML in documents
 }
 
 // NewService creates a new server service
-func NewService(fs FileSystem, logger Logger) *Service {
+func NewService(fs FileSystem, logger Logger, config Config) *Service {
 	return &Service{
 		fs:     fs,
 		logger: logger,
+		config: config.withDefaults(),
 	}
 }
 
-// ServeMarkdownFiles starts an HTTP server to serve markdown files
-func (s *Service) ServeMarkdownFiles(dir string, port int) error {
+// withDefaults fills in the listen address, timeouts and poll interval
+// left unset
+func (config Config) withDefaults() Config {
+	if config.Addr == "" {
+		config.Addr = defaultAddr
+	}
+	if config.ReadTimeout <= 0 {
+		config.ReadTimeout = defaultReadTimeout
+	}
+	if config.WriteTimeout <= 0 {
+		config.WriteTimeout = defaultWriteTimeout
+	}
+	if config.IdleTimeout <= 0 {
+		config.IdleTimeout = defaultIdleTimeout
+	}
+	if config.ShutdownTimeout <= 0 {
+		config.ShutdownTimeout = defaultShutdownTimeout
+	}
+	if config.PollInterval <= 0 {
+		config.PollInterval = indexRefreshInterval
+		if config.Watch {
+			config.PollInterval = watchPollInterval
+		}
+	}
+	return config
+}
+
+// ServeMarkdownFiles serves the markdown files in dir until ctx is cancelled,
+// then shuts down gracefully
+func (s *Service) ServeMarkdownFiles(ctx context.Context, dir string) error {
+	srv, err := s.Listen(dir)
+	if err != nil {
+		return err
+	}
+	return srv.Serve(ctx)
+}
+
+// Listen prepares the handlers for dir and binds the configured address
+func (s *Service) Listen(dir string) (*Server, error) {
 	absDir, err := filepath.Abs(dir)
 	if err != nil {
-		return fmt.Errorf("failed to get absolute path for %s: %w", dir, err)
+		return nil, fmt.Errorf("failed to get absolute path for %s: %w", dir, err)
 	}
 
 	if _, err := s.fs.Stat(absDir); err != nil {
-		return fmt.Errorf("directory does not exist: %s", absDir)
+		return nil, fmt.Errorf("directory does not exist: %s", absDir)
 	}
 
-	handler := &MarkdownHandler{baseDir: absDir, fs: s.fs, logger: s.logger}
+	handler := &MarkdownHandler{baseDir: absDir, fs: s.fs, logger: s.logger, liveReload: s.config.Watch, unsafeHTML: s.config.UnsafeHTML}
 
-	s.logger.Printf("Starting server on port %d, serving files from %s", port, absDir)
-	s.logger.Printf("Server running at http://localhost:%d", port)
+	index := NewSearchIndex(s.fs, absDir, s.logger)
+	if err := index.Build(); err != nil {
+		return nil, fmt.Errorf("failed to build search index: %w", err)
+	}
+	s.logger.Info("Indexed documents for search", "count", index.Len())
+
+	watcher := NewWatcher(s.fs, absDir, s.config.PollInterval, s.logger)
+	watcher.OnChange(func() {
+		if err := index.Build(); err != nil {
+			s.logger.Error("Failed to rebuild search index", "error", err)
+			return
+		}
+		s.logger.Info("Reindexed documents", "count", index.Len())
+	})
+
+	tlsConfig, err := s.tlsConfig()
+	if err != nil {
+		return nil, err
+	}
 
-	return http.ListenAndServe(fmt.Sprintf(":%d", port), handler)
+	auth, err := newAuthenticator(s.fs, s.config.AuthTokens, s.config.HtpasswdFile, s.logger)
+	if err != nil {
+		return nil, err
+	}
+	handler.private = auth != nil
+
+	metrics := NewMetrics()
+	ready := &readiness{fs: s.fs, dir: absDir}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(healthPath, serveHealth)
+	mux.Handle(readyPath, ready)
+	mux.Handle(metricsPath, metrics)
+	mux.Handle("/_search", index)
+	if s.config.Watch {
+		mux.Handle("/_events", watcher)
+		s.logger.Info("Watching for changes", "dir", absDir)
+	}
+	if s.config.Upstream != "" {
+		proxy, err := NewProxyHandler(s.config.Upstream, absDir, s.fs, s.logger)
+		if err != nil {
+			return nil, err
+		}
+		proxy.markdown.private = handler.private
+		mux.Handle("/", proxy)
+		s.logger.Info("Proxying HTML requests", "upstream", s.config.Upstream)
+	} else {
+		mux.Handle("/", handler)
+	}
+
+	var root http.Handler = mux
+	if auth != nil {
+		root = auth.wrap(mux)
+		s.logger.Info("Requiring authentication", "open_paths", []string{healthPath, readyPath})
+	}
+	root = newAccessLog(s.config.AccessLog, metrics).wrap(root)
+
+	listener, err := listen(s.config.Addr)
+	if err != nil {
+		return nil, err
+	}
+
+	srv := newServer(root, tlsConfig, listener, s.config, s.logger)
+	srv.watcher = watcher
+	srv.readiness = ready
+
+	s.logger.Info("Serving files", "dir", absDir)
+	s.logger.Info("Server running", "url", srv.URL())
+	return srv, nil
 }
 
 // ServeHTTP handles individual HTTP requests
 func (h *MarkdownHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
 	requestPath := strings.TrimPrefix(r.URL.Path, "/")
 
-	if requestPath == "" {
-		requestPath = "index.md"
+	// checked before anything else so hidden directories aren't listed either
+	if docs.IsHidden(requestPath) {
+		h.logger.Debug("File not found", "path", requestPath)
+		http.NotFound(w, r)
+		return
 	}
 
-	if !strings.HasSuffix(requestPath, ".md") {
-		requestPath += ".md"
+	if requestPath == "" || strings.HasSuffix(requestPath, "/") {
+		h.serveDirectory(w, r, requestPath)
+		return
 	}
 
-	filePath := filepath.Join(h.baseDir, requestPath)
+	filePath, ok := h.resolve(requestPath)
+	if !ok {
+		http.Error(w, "Invalid path", http.StatusBadRequest)
+		return
+	}
 
-	if !strings.HasPrefix(filePath, h.baseDir) {
+	// an explicit .md URL always gets the raw markdown
+	if strings.HasSuffix(requestPath, ".md") {
+		if info, err := h.fs.Stat(filePath); err == nil && info.IsExist() && !info.IsDir() {
+			h.serveFile(w, r, filePath, info, FormatMarkdown)
+			return
+		}
+		h.logger.Debug("File not found", "path", filePath)
+		http.NotFound(w, r)
+		return
+	}
+
+	if info, err := h.fs.Stat(filePath + ".md"); err == nil && info.IsExist() && !info.IsDir() {
+		h.serveFile(w, r, filePath+".md", info, negotiateFormat(r))
+		return
+	}
+
+	if info, err := h.fs.Stat(filePath); err == nil && info.IsExist() {
+		// redirect directories to their trailing-slash form so relative links work
+		if info.IsDir() {
+			http.Redirect(w, r, r.URL.Path+"/", http.StatusMovedPermanently)
+			return
+		}
+		h.serveStatic(w, r, filePath, info)
+		return
+	}
+
+	switch requestPath {
+	case "sitemap.xml":
+		h.serveSitemap(w, r)
+		return
+	case "llms.txt":
+		h.serveLLMsTxt(w, r)
+		return
+	}
+
+	h.logger.Debug("File not found", "path", filePath+".md")
+	http.NotFound(w, r)
+}
+
+// resolve maps a request path onto the served directory, rejecting paths
+// that escape it
+func (h *MarkdownHandler) resolve(requestPath string) (string, bool) {
+	return docs.ResolvePath(h.baseDir, requestPath)
+}
+
+// serveDirectory serves a directory's index.md, or a generated listing when
+// the directory has no index
+func (h *MarkdownHandler) serveDirectory(w http.ResponseWriter, r *http.Request, requestPath string) {
+	dirPath, ok := h.resolve(requestPath)
+	if !ok {
 		http.Error(w, "Invalid path", http.StatusBadRequest)
 		return
 	}
 
-	fileInfo, err := h.fs.Stat(filePath)
-	if err != nil || !fileInfo.IsExist() {
-		h.logger.Printf("File not found: %s", filePath)
+	indexPath := filepath.Join(dirPath, "index.md")
+	if info, err := h.fs.Stat(indexPath); err == nil && info.IsExist() {
+		h.serveFile(w, r, indexPath, info, negotiateFormat(r))
+		return
+	}
+
+	info, err := h.fs.Stat(dirPath)
+	if err != nil || !info.IsExist() || !info.IsDir() {
+		h.logger.Debug("File not found", "path", indexPath)
 		http.NotFound(w, r)
 		return
 	}
 
+	w.Header().Set("Vary", "Accept")
+	listing, err := h.listDirectory(dirPath, "/"+requestPath)
+	if err != nil {
+		h.logger.Error("Failed to list directory", "path", dirPath, "error", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	if h.liveReload {
+		listing.LiveReload = liveReloadScript
+	}
+
+	if err := writeListing(w, listing, negotiateFormat(r)); err != nil {
+		h.logger.Warn("Failed to write listing", "path", dirPath, "error", err)
+	}
+}
+
+// serveFile serves a markdown file as-is, or rendered to HTML for browsers.
+// Conditional, HEAD and Range requests are handled by http.ServeContent.
+func (h *MarkdownHandler) serveFile(w http.ResponseWriter, r *http.Request, filePath string, info filesystem.FileInfo, format string) {
+	content, err := h.fs.ReadFile(filePath)
+	if err != nil {
+		h.logger.Error("Failed to read file", "path", filePath, "error", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Cache-Control", h.cacheControl())
+	w.Header().Set("Vary", "Accept")
+
+	if format == FormatHTML {
+		page, err := h.renderPage(r, filePath, content)
+		if err != nil {
+			h.logger.Error("Failed to render", "path", filePath, "error", err)
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		content = page
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	} else {
+		w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+	}
+
+	// the HTML page also depends on its sidebar and navigation, which come
+	// from other files, so it is validated by the hash of what is sent alone
+	modTime := info.ModTime()
+	if format == FormatHTML {
+		modTime = time.Time{}
+	}
+	w.Header().Set("ETag", etag(content))
+	http.ServeContent(w, r, filePath, modTime, bytes.NewReader(content))
+}
+
+// serveStatic serves a non-markdown file such as an image, with the content
+// type derived from its extension
+func (h *MarkdownHandler) serveStatic(w http.ResponseWriter, r *http.Request, filePath string, info filesystem.FileInfo) {
 	content, err := h.fs.ReadFile(filePath)
 	if err != nil {
-		h.logger.Printf("Error reading file %s: %v", filePath, err)
+		h.logger.Error("Failed to read file", "path", filePath, "error", err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
 
-	w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
-	w.Header().Set("Cache-Control", "public, max-age=3600")
+	w.Header().Set("Cache-Control", h.cacheControl())
+	w.Header().Set("ETag", etag(content))
+	http.ServeContent(w, r, filePath, info.ModTime(), bytes.NewReader(content))
+}
+
+// etag returns a strong entity tag for content
+func etag(content []byte) string {
+	sum := sha256.Sum256(content)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
 
-	h.logger.Printf("Serving: %s", filePath)
-	w.Write(content)
+// cacheControl returns the Cache-Control header for documents. Watch mode
+// disables caching so reloads always fetch the latest content, and documents
+// behind authentication must not be kept by shared caches.
+func (h *MarkdownHandler) cacheControl() string {
+	if h.liveReload {
+		return "no-cache"
+	}
+	if h.private {
+		return "private, max-age=3600"
+	}
+	return "public, max-age=3600"
 }
@@ -1,6 +1,7 @@
 package server
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
@@ -172,9 +173,9 @@ func TestServerService_ServeMarkdownFiles(t *testing.T) {
 		mockFS.SetStatError(fmt.Errorf("directory not found"))
 		logger := NewMockLogger()
 		
-		server := NewService(mockFS, logger)
+		server := NewService(mockFS, logger, Config{})
 		
-		err := server.ServeMarkdownFiles("/nonexistent", 8080)
+		err := server.ServeMarkdownFiles(context.Background(), "/nonexistent")
 		
 		if err == nil {
 			t.Errorf("expected error for non-existent directory")
@@ -0,0 +1,108 @@
+package server
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"fmt"
+	"math/big"
+	"net"
+	"strings"
+	"time"
+)
+
+// selfSignedValidity is how long generated development certificates last
+const selfSignedValidity = 30 * 24 * time.Hour
+
+// tlsConfig builds the server's TLS configuration, or returns nil when the
+// server should speak plain HTTP
+func (s *Service) tlsConfig() (*tls.Config, error) {
+	config := s.config
+
+	if (config.TLSCertFile == "") != (config.TLSKeyFile == "") {
+		return nil, fmt.Errorf("both a TLS certificate and key are required")
+	}
+	if config.TLSCertFile != "" && config.SelfSignedTLS {
+		return nil, fmt.Errorf("cannot use both a TLS certificate and a self-signed certificate")
+	}
+
+	var cert tls.Certificate
+	switch {
+	case config.TLSCertFile != "":
+		certPEM, err := s.fs.ReadFile(config.TLSCertFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read TLS certificate %s: %w", config.TLSCertFile, err)
+		}
+		keyPEM, err := s.fs.ReadFile(config.TLSKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read TLS key %s: %w", config.TLSKeyFile, err)
+		}
+		cert, err = tls.X509KeyPair(certPEM, keyPEM)
+		if err != nil {
+			return nil, fmt.Errorf("invalid TLS certificate or key: %w", err)
+		}
+	case config.SelfSignedTLS:
+		var err error
+		cert, err = selfSignedCertificate(config.Addr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate self-signed certificate: %w", err)
+		}
+		fingerprint := sha256.Sum256(cert.Certificate[0])
+		s.logger.Info("Generated self-signed certificate", "sha256", fmt.Sprintf("%X", fingerprint))
+	default:
+		return nil, nil
+	}
+
+	return &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}, nil
+}
+
+// selfSignedCertificate generates a certificate for localhost and the host
+// in the listen address
+func selfSignedCertificate(addr string) (tls.Certificate, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{Organization: []string{"mdify development"}},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(selfSignedValidity),
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		DNSNames:              []string{"localhost"},
+		IPAddresses:           []net.IP{net.IPv4(127, 0, 0, 1), net.IPv6loopback},
+	}
+
+	if host, _, err := net.SplitHostPort(addr); err == nil && host != "" && !strings.HasPrefix(addr, unixPrefix) {
+		if ip := net.ParseIP(host); ip != nil {
+			if !ip.IsLoopback() && !ip.IsUnspecified() {
+				template.IPAddresses = append(template.IPAddresses, ip)
+			}
+		} else if host != "localhost" {
+			template.DNSNames = append(template.DNSNames, host)
+		}
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}, nil
+}
@@ -0,0 +1,134 @@
+package server
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestSelfSignedCertificate(t *testing.T) {
+	tests := []struct {
+		addr        string
+		expectedDNS []string
+		expectedIPs int
+	}{
+		{addr: "127.0.0.1:8443", expectedDNS: []string{"localhost"}, expectedIPs: 2},
+		{addr: "docs.internal:8443", expectedDNS: []string{"localhost", "docs.internal"}, expectedIPs: 2},
+		{addr: "10.0.0.5:8443", expectedDNS: []string{"localhost"}, expectedIPs: 3},
+		{addr: ":8443", expectedDNS: []string{"localhost"}, expectedIPs: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.addr, func(t *testing.T) {
+			cert, err := selfSignedCertificate(tt.addr)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			parsed, err := x509.ParseCertificate(cert.Certificate[0])
+			if err != nil {
+				t.Fatalf("invalid certificate: %v", err)
+			}
+			if strings.Join(parsed.DNSNames, ",") != strings.Join(tt.expectedDNS, ",") {
+				t.Errorf("expected DNS names %v, got %v", tt.expectedDNS, parsed.DNSNames)
+			}
+			if len(parsed.IPAddresses) != tt.expectedIPs {
+				t.Errorf("expected %d IP addresses, got %v", tt.expectedIPs, parsed.IPAddresses)
+			}
+		})
+	}
+}
+
+func TestService_TLSConfig(t *testing.T) {
+	cert, err := selfSignedCertificate("127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := x509.MarshalPKCS8PrivateKey(cert.PrivateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fs := NewMockFileSystem()
+	fs.SetFile("/tls/cert.pem", string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Certificate[0]})))
+	fs.SetFile("/tls/key.pem", string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: key})))
+	fs.SetFile("/tls/bad.pem", "not a certificate")
+
+	tests := []struct {
+		name          string
+		config        Config
+		expectTLS     bool
+		expectedError string
+	}{
+		{name: "plain http", config: Config{}},
+		{name: "certificate files", config: Config{TLSCertFile: "/tls/cert.pem", TLSKeyFile: "/tls/key.pem"}, expectTLS: true},
+		{name: "self-signed", config: Config{SelfSignedTLS: true}, expectTLS: true},
+		{name: "missing key", config: Config{TLSCertFile: "/tls/cert.pem"}, expectedError: "both a TLS certificate and key are required"},
+		{name: "conflicting options", config: Config{TLSCertFile: "/tls/cert.pem", TLSKeyFile: "/tls/key.pem", SelfSignedTLS: true}, expectedError: "cannot use both"},
+		{name: "unreadable certificate", config: Config{TLSCertFile: "/tls/missing.pem", TLSKeyFile: "/tls/key.pem"}, expectedError: "failed to read TLS certificate"},
+		{name: "invalid certificate", config: Config{TLSCertFile: "/tls/bad.pem", TLSKeyFile: "/tls/key.pem"}, expectedError: "invalid TLS certificate or key"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config, err := NewService(fs, NewMockLogger(), tt.config).tlsConfig()
+			if tt.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectedError) {
+					t.Errorf("expected error containing %q, got %v", tt.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if (config != nil) != tt.expectTLS {
+				t.Errorf("expected TLS %v, got %v", tt.expectTLS, config != nil)
+			}
+		})
+	}
+}
+
+func TestService_ServeTLSWithAuth(t *testing.T) {
+	config := Config{Addr: "127.0.0.1:0", SelfSignedTLS: true, AuthTokens: []string{"team-token"}}
+	srv, err := NewService(newListenFS(), NewMockLogger(), config).Listen("/base")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	stop := startServer(t, srv)
+	defer stop()
+
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}}
+	base := "https://" + srv.Addr().String()
+
+	resp, err := client.Get(base + "/healthz")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || resp.TLS == nil {
+		t.Errorf("expected open health check over TLS, got %d", resp.StatusCode)
+	}
+
+	resp, err = client.Get(base + "/")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("expected status 401 without a token, got %d", resp.StatusCode)
+	}
+
+	req, _ := http.NewRequest("GET", base+"/", nil)
+	req.Header.Set("Authorization", "Bearer team-token")
+	resp, err = client.Do(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected status 200 with a token, got %d", resp.StatusCode)
+	}
+}
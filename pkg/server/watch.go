@@ -0,0 +1,178 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"mdify/internal/docs"
+)
+
+// sseHeartbeatInterval keeps idle event streams open through proxies
+const sseHeartbeatInterval = 30 * time.Second
+
+// liveReloadScript reloads an HTML page when the server reports a change
+const liveReloadScript = `<script>new EventSource("/_events").addEventListener("reload", function () { location.reload(); });</script>`
+
+// Watcher polls a directory for added, removed or modified markdown files.
+// Polling works on every platform and filesystem, including network mounts.
+type Watcher struct {
+	fs       FileSystem
+	dir      string
+	interval time.Duration
+	logger   Logger
+
+	mu        sync.Mutex
+	primed    bool
+	signature uint64
+	callbacks []func()
+	clients   map[chan struct{}]struct{}
+	done      chan struct{} // closed when Run returns
+}
+
+// NewWatcher creates a watcher that checks dir every interval
+func NewWatcher(fs FileSystem, dir string, interval time.Duration, logger Logger) *Watcher {
+	return &Watcher{
+		fs:       fs,
+		dir:      dir,
+		interval: interval,
+		logger:   logger,
+		clients:  make(map[chan struct{}]struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+// OnChange registers a callback run after every detected change, before
+// subscribers are notified
+func (w *Watcher) OnChange(callback func()) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.callbacks = append(w.callbacks, callback)
+}
+
+// Check polls the directory once and reports whether anything changed since
+// the previous check. The first check only records the current state.
+func (w *Watcher) Check() (bool, error) {
+	files, err := docs.Walk(w.fs, w.dir)
+	if err != nil {
+		return false, err
+	}
+	signature := filesSignature(files)
+
+	w.mu.Lock()
+	changed := w.primed && signature != w.signature
+	w.primed = true
+	w.signature = signature
+	callbacks := append([]func(){}, w.callbacks...)
+	w.mu.Unlock()
+
+	if !changed {
+		return false, nil
+	}
+
+	for _, callback := range callbacks {
+		callback()
+	}
+	w.notify()
+	return true, nil
+}
+
+// Run polls the directory until stop is closed
+func (w *Watcher) Run(stop <-chan struct{}) {
+	defer close(w.done)
+
+	if _, err := w.Check(); err != nil {
+		w.logger.Error("Failed to watch directory", "dir", w.dir, "error", err)
+	}
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			changed, err := w.Check()
+			if err != nil {
+				w.logger.Error("Failed to watch directory", "dir", w.dir, "error", err)
+			} else if changed {
+				w.logger.Info("Detected changes", "dir", w.dir)
+			}
+		}
+	}
+}
+
+// Subscribe returns a channel that receives a value after each change, and a
+// function that unsubscribes it
+func (w *Watcher) Subscribe() (<-chan struct{}, func()) {
+	ch := make(chan struct{}, 1)
+
+	w.mu.Lock()
+	w.clients[ch] = struct{}{}
+	w.mu.Unlock()
+
+	return ch, func() {
+		w.mu.Lock()
+		delete(w.clients, ch)
+		w.mu.Unlock()
+	}
+}
+
+// notify wakes every subscriber without blocking on slow ones; a pending
+// notification already covers the new change
+func (w *Watcher) notify() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for ch := range w.clients {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// ServeHTTP streams reload events to browsers as Server-Sent Events
+func (w *Watcher) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(rw, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	flusher, ok := rw.(http.Flusher)
+	if !ok {
+		http.Error(rw, "Streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	events, unsubscribe := w.Subscribe()
+	defer unsubscribe()
+
+	// the stream outlives the server's write timeout
+	http.NewResponseController(rw).SetWriteDeadline(time.Time{})
+
+	rw.Header().Set("Content-Type", "text/event-stream")
+	rw.Header().Set("Cache-Control", "no-cache")
+	rw.Header().Set("Connection", "keep-alive")
+	fmt.Fprint(rw, "retry: 1000\n\n")
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(sseHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-w.done:
+			return
+		case <-heartbeat.C:
+			fmt.Fprint(rw, ": ping\n\n")
+		case <-events:
+			fmt.Fprint(rw, "event: reload\ndata: {}\n\n")
+		}
+		flusher.Flush()
+	}
+}
@@ -0,0 +1,137 @@
+package server
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWatcher_Check(t *testing.T) {
+	fs := newSearchFS()
+	watcher := NewWatcher(fs, "/base", time.Second, NewMockLogger())
+
+	var calls []string
+	watcher.OnChange(func() { calls = append(calls, "callback") })
+	events, unsubscribe := watcher.Subscribe()
+	defer unsubscribe()
+
+	if changed, err := watcher.Check(); err != nil || changed {
+		t.Fatalf("expected first check to only record state, got changed=%v err=%v", changed, err)
+	}
+	if changed, _ := watcher.Check(); changed {
+		t.Error("expected no change without modifications")
+	}
+
+	fs.SetFile("/base/docs/new.md", "# New")
+	if changed, err := watcher.Check(); err != nil || !changed {
+		t.Fatalf("expected added file to be detected, got changed=%v err=%v", changed, err)
+	}
+	if len(calls) != 1 {
+		t.Errorf("expected callback to run once, got %d", len(calls))
+	}
+	select {
+	case <-events:
+	default:
+		t.Error("expected subscriber to be notified")
+	}
+
+	delete(fs.files, "/base/docs/new.md")
+	if changed, _ := watcher.Check(); !changed {
+		t.Error("expected removed file to be detected")
+	}
+
+	fs.SetFile("/base/notes.txt", "not markdown")
+	if changed, _ := watcher.Check(); changed {
+		t.Error("expected non-markdown files to be ignored")
+	}
+}
+
+func TestWatcher_ServeHTTP(t *testing.T) {
+	fs := newSearchFS()
+	watcher := NewWatcher(fs, "/base", time.Second, NewMockLogger())
+	if _, err := watcher.Check(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	srv := httptest.NewServer(watcher)
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("expected event stream, got %q", ct)
+	}
+
+	fs.SetFile("/base/docs/new.md", "# New")
+	if _, err := watcher.Check(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	found := make(chan bool)
+	go func() {
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			if scanner.Text() == "event: reload" {
+				found <- true
+				return
+			}
+		}
+		found <- false
+	}()
+
+	select {
+	case ok := <-found:
+		if !ok {
+			t.Error("expected a reload event")
+		}
+	case <-time.After(2 * time.Second):
+		t.Error("timed out waiting for reload event")
+	}
+}
+
+func TestMarkdownHandler_LiveReload(t *testing.T) {
+	tests := []struct {
+		name          string
+		liveReload    bool
+		path          string
+		accept        string
+		expectedCache string
+		expectScript  bool
+	}{
+		{name: "page without watch", path: "/docs/intro", accept: "text/html", expectedCache: "public, max-age=3600"},
+		{name: "page with watch", liveReload: true, path: "/docs/intro", accept: "text/html", expectedCache: "no-cache", expectScript: true},
+		{name: "markdown with watch", liveReload: true, path: "/docs/intro", expectedCache: "no-cache"},
+		{name: "listing with watch", liveReload: true, path: "/docs/", accept: "text/html", expectScript: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := &MarkdownHandler{baseDir: "/base", fs: newListingFS(), logger: NewMockLogger(), liveReload: tt.liveReload}
+
+			req := httptest.NewRequest("GET", tt.path, nil)
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			w := httptest.NewRecorder()
+
+			handler.ServeHTTP(w, req)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("expected status 200, got %d", w.Code)
+			}
+			if tt.expectedCache != "" && w.Header().Get("Cache-Control") != tt.expectedCache {
+				t.Errorf("expected Cache-Control %q, got %q", tt.expectedCache, w.Header().Get("Cache-Control"))
+			}
+			if hasScript := strings.Contains(w.Body.String(), `EventSource("/_events")`); hasScript != tt.expectScript {
+				t.Errorf("expected reload script %v, got %v", tt.expectScript, hasScript)
+			}
+		})
+	}
+}
@@ -15,7 +15,10 @@ type HTTPClient interface {
 }
 
 type Logger interface {
-	Printf(format string, v ...interface{})
+	Debug(msg string, args ...interface{})
+	Info(msg string, args ...interface{})
+	Warn(msg string, args ...interface{})
+	Error(msg string, args ...interface{})
 }
 
 // Sitemap represents a sitemap XML structure
@@ -43,7 +46,7 @@ func NewService(client HTTPClient, logger Logger) *Service {
 
 // FetchSitemap fetches and parses a sitemap from the given URL
 func (s *Service) FetchSitemap(sitemapURL string) (*Sitemap, error) {
-	s.logger.Printf("Fetching sitemap: %s", sitemapURL)
+	s.logger.Info("Fetching sitemap", "url", sitemapURL)
 
 	resp, err := s.client.Get(sitemapURL)
 	if err != nil {
@@ -66,13 +69,13 @@ func (s *Service) FetchSitemap(sitemapURL string) (*Sitemap, error) {
 	if err := decoder.Decode(&sitemap); err != nil {
 		// If it's not a valid sitemap format (RSS, HTML), return empty sitemap instead of error
 		if strings.Contains(err.Error(), "expected element type") {
-			s.logger.Printf("Warning: Document is not a sitemap format, found 0 URLs")
+			s.logger.Warn("Document is not in sitemap format, found 0 URLs", "url", sitemapURL)
 			return &Sitemap{URLs: []URL{}}, nil
 		}
 		return nil, fmt.Errorf("failed to parse sitemap XML: %w", err)
 	}
 
-	s.logger.Printf("Found %d URLs in sitemap", len(sitemap.URLs))
+	s.logger.Info("Found URLs in sitemap", "url", sitemapURL, "count", len(sitemap.URLs))
 	return &sitemap, nil
 }
 
@@ -87,7 +90,7 @@ func (s *Service) FilterURLs(sitemap *Sitemap, pathFilter string) []string {
 	}
 
 	if pathFilter != "" {
-		s.logger.Printf("Filtered to %d URLs matching path filter '%s'", len(filteredURLs), pathFilter)
+		s.logger.Info("Filtered URLs", "filter", pathFilter, "count", len(filteredURLs))
 	}
 
 	return filteredURLs
@@ -203,7 +203,7 @@ func TestSitemapService_FilterURLs(t *testing.T) {
 					t.Errorf("expected filtering log message")
 				}
 				lastMessage := logger.GetLastMessage()
-				if !strings.Contains(lastMessage, "Filtered to") {
+				if !strings.Contains(lastMessage, "Filtered URLs filter="+tt.pathFilter) {
 					t.Errorf("expected filtering message, got: %s", lastMessage)
 				}
 			}
@@ -0,0 +1,81 @@
+package stats
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+// Output formats for a report
+const (
+	FormatTable = "table"
+	FormatJSON  = "json"
+)
+
+// Write renders the report in the given format
+func (r *Report) Write(w io.Writer, format string) error {
+	switch format {
+	case FormatTable:
+		return r.WriteTable(w)
+	case FormatJSON:
+		return r.WriteJSON(w)
+	default:
+		return fmt.Errorf("invalid format %q (use table or json)", format)
+	}
+}
+
+// WriteJSON writes the report as an indented JSON object
+func (r *Report) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(r)
+}
+
+// WriteTable writes the report as aligned columns followed by the largest
+// files, duplicates and empty pages
+func (r *Report) WriteTable(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "FILE\tBYTES\tWORDS\tTOKENS")
+	for _, file := range r.Files {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\n", file.Path, file.Bytes, file.Words, file.Tokens)
+	}
+	fmt.Fprintf(tw, "TOTAL (%d files)\t%d\t%d\t%d\n", r.Total.Files, r.Total.Bytes, r.Total.Words, r.Total.Tokens)
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "\nTokens estimated with the %s tokenizer.\n", r.Tokenizer)
+	if r.FitsContext != nil {
+		verdict := "fits"
+		if !*r.FitsContext {
+			verdict = "does not fit"
+		}
+		fmt.Fprintf(&b, "Context window: %d of %d tokens (%d%%), %s.\n",
+			r.Total.Tokens, r.ContextWindow, r.Total.Tokens*100/r.ContextWindow, verdict)
+	}
+
+	if len(r.Largest) > 0 {
+		b.WriteString("\nLargest files:\n")
+		for _, file := range r.Largest {
+			fmt.Fprintf(&b, "  %s (%d tokens)\n", file.Path, file.Tokens)
+		}
+	}
+	if len(r.Duplicates) > 0 {
+		b.WriteString("\nDuplicate content:\n")
+		for _, group := range r.Duplicates {
+			fmt.Fprintf(&b, "  %s\n", strings.Join(group, " = "))
+		}
+	}
+	if len(r.Empty) > 0 {
+		b.WriteString("\nEmpty pages:\n")
+		for _, path := range r.Empty {
+			fmt.Fprintf(&b, "  %s\n", path)
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
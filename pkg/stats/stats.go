@@ -0,0 +1,200 @@
+package stats
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"mdify/internal/docs"
+	"mdify/internal/filesystem"
+)
+
+// DefaultTop is how many of the largest files a report lists by default
+const DefaultTop = 10
+
+// FileSystem interface for reading the docs corpus
+type FileSystem interface {
+	ReadFile(filename string) ([]byte, error)
+	ReadDir(name string) ([]os.DirEntry, error)
+	Stat(name string) (filesystem.FileInfo, error)
+}
+
+// Logger interface for leveled, structured logging; *slog.Logger satisfies it
+type Logger interface {
+	Debug(msg string, args ...interface{})
+	Info(msg string, args ...interface{})
+	Warn(msg string, args ...interface{})
+	Error(msg string, args ...interface{})
+}
+
+// Config holds configuration for collecting statistics
+type Config struct {
+	Tokenizer     Tokenizer // defaults to BPETokenizer
+	Top           int       // how many of the largest files to list
+	ContextWindow int       // tokens to check the corpus against, or 0
+}
+
+// FileStats are the counts for a single markdown file
+type FileStats struct {
+	Path   string `json:"path"`
+	Bytes  int    `json:"bytes"`
+	Words  int    `json:"words"`
+	Tokens int    `json:"tokens"`
+}
+
+// Totals are the counts for the whole corpus
+type Totals struct {
+	Files  int `json:"files"`
+	Bytes  int `json:"bytes"`
+	Words  int `json:"words"`
+	Tokens int `json:"tokens"`
+}
+
+// Report describes a docs directory
+type Report struct {
+	Dir           string      `json:"dir"`
+	Tokenizer     string      `json:"tokenizer"`
+	Files         []FileStats `json:"files"`
+	Total         Totals      `json:"total"`
+	Largest       []FileStats `json:"largest"`    // by tokens, largest first
+	Duplicates    [][]string  `json:"duplicates"` // groups of files with the same content
+	Empty         []string    `json:"empty"`      // files with no text besides headings
+	ContextWindow int         `json:"context_window,omitempty"`
+	FitsContext   *bool       `json:"fits_context,omitempty"`
+}
+
+// Service collects statistics about converted docs
+type Service struct {
+	fs     FileSystem
+	logger Logger
+	config Config
+}
+
+// NewService creates a new statistics service
+func NewService(fs FileSystem, logger Logger, config Config) *Service {
+	if config.Tokenizer == nil {
+		config.Tokenizer = BPETokenizer{}
+	}
+	if config.Top <= 0 {
+		config.Top = DefaultTop
+	}
+
+	return &Service{
+		fs:     fs,
+		logger: logger,
+		config: config,
+	}
+}
+
+// Collect reads every markdown file below dir and reports on them
+func (s *Service) Collect(dir string) (*Report, error) {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get absolute path for %s: %w", dir, err)
+	}
+	if info, err := s.fs.Stat(absDir); err != nil || !info.IsExist() {
+		return nil, fmt.Errorf("directory does not exist: %s", absDir)
+	}
+
+	documents, err := docs.List(s.fs, absDir)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Report{
+		Dir:        absDir,
+		Tokenizer:  s.config.Tokenizer.Name(),
+		Files:      make([]FileStats, 0, len(documents)),
+		Duplicates: [][]string{},
+		Empty:      []string{},
+	}
+
+	groups := make(map[[sha256.Size]byte][]string)
+	var hashes [][sha256.Size]byte
+
+	for _, doc := range documents {
+		content, err := s.fs.ReadFile(filepath.Join(absDir, filepath.FromSlash(doc.File)))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", doc.File, err)
+		}
+
+		body := stripFrontMatter(string(content))
+		file := FileStats{
+			Path:   doc.File,
+			Bytes:  len(content),
+			Words:  len(strings.Fields(body)),
+			Tokens: s.config.Tokenizer.CountTokens(body),
+		}
+		report.Files = append(report.Files, file)
+		report.Total.Files++
+		report.Total.Bytes += file.Bytes
+		report.Total.Words += file.Words
+		report.Total.Tokens += file.Tokens
+		s.logger.Debug("Counted", "path", doc.File, "words", file.Words, "tokens", file.Tokens)
+
+		if isEmpty(body) {
+			report.Empty = append(report.Empty, doc.File)
+			continue
+		}
+
+		hash := sha256.Sum256([]byte(strings.Join(strings.Fields(body), " ")))
+		if _, seen := groups[hash]; !seen {
+			hashes = append(hashes, hash)
+		}
+		groups[hash] = append(groups[hash], doc.File)
+	}
+
+	for _, hash := range hashes {
+		if len(groups[hash]) > 1 {
+			report.Duplicates = append(report.Duplicates, groups[hash])
+		}
+	}
+
+	largest := append([]FileStats(nil), report.Files...)
+	sort.SliceStable(largest, func(i, j int) bool {
+		return largest[i].Tokens > largest[j].Tokens
+	})
+	if len(largest) > s.config.Top {
+		largest = largest[:s.config.Top]
+	}
+	report.Largest = largest
+
+	if s.config.ContextWindow > 0 {
+		fits := report.Total.Tokens <= s.config.ContextWindow
+		report.ContextWindow = s.config.ContextWindow
+		report.FitsContext = &fits
+	}
+
+	return report, nil
+}
+
+// stripFrontMatter removes a leading YAML front matter block
+func stripFrontMatter(markdown string) string {
+	lines := strings.SplitAfter(markdown, "\n")
+	if strings.TrimSpace(lines[0]) != "---" {
+		return markdown
+	}
+
+	for i := 1; i < len(lines); i++ {
+		if strings.TrimSpace(lines[i]) == "---" {
+			return strings.Join(lines[i+1:], "")
+		}
+	}
+
+	return markdown
+}
+
+// isEmpty reports whether a page has nothing but headings, as when the
+// selector matched a title but no content
+func isEmpty(body string) bool {
+	for _, line := range strings.Split(body, "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "#") {
+			return false
+		}
+	}
+	return true
+}
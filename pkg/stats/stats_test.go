@@ -0,0 +1,179 @@
+package stats
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"mdify/internal/filesystem"
+)
+
+func newCorpus() *filesystem.MemFileSystem {
+	fs := filesystem.NewMemFileSystem()
+	fs.WriteFile("/docs/index.md", []byte("# Home\n\nWelcome to the docs."))
+	fs.WriteFile("/docs/copy.md", []byte("# Home\n\nWelcome  to the\ndocs."))
+	fs.WriteFile("/docs/empty.md", []byte("---\ntitle: Empty\n---\n# Empty\n\n## Nothing here\n"))
+	fs.WriteFile("/docs/guide/install.md", []byte("# Install\n\n"+strings.Repeat("Run the installer and follow the prompts. ", 20)))
+	return fs
+}
+
+func TestService_Collect(t *testing.T) {
+	logger := NewMockLogger()
+	service := NewService(newCorpus(), logger, Config{Tokenizer: CharTokenizer{}, Top: 2})
+
+	report, err := service.Collect("/docs")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var paths []string
+	for _, file := range report.Files {
+		paths = append(paths, file.Path)
+	}
+	if !reflect.DeepEqual(paths, []string{"copy.md", "empty.md", "guide/install.md", "index.md"}) {
+		t.Errorf("unexpected files %v", paths)
+	}
+
+	index := report.Files[3]
+	if index.Words != 6 || index.Tokens != 7 || index.Bytes != 28 {
+		t.Errorf("unexpected counts for index.md: %+v", index)
+	}
+	if report.Files[1].Words != 5 {
+		t.Errorf("expected front matter not to be counted, got %d words", report.Files[1].Words)
+	}
+
+	var total Totals
+	for _, file := range report.Files {
+		total.Files++
+		total.Bytes += file.Bytes
+		total.Words += file.Words
+		total.Tokens += file.Tokens
+	}
+	if report.Total != total {
+		t.Errorf("expected totals %+v, got %+v", total, report.Total)
+	}
+
+	if len(report.Largest) != 2 || report.Largest[0].Path != "guide/install.md" {
+		t.Errorf("expected the 2 largest files starting with guide/install.md, got %+v", report.Largest)
+	}
+	if !reflect.DeepEqual(report.Duplicates, [][]string{{"copy.md", "index.md"}}) {
+		t.Errorf("expected copy.md and index.md as duplicates, got %v", report.Duplicates)
+	}
+	if !reflect.DeepEqual(report.Empty, []string{"empty.md"}) {
+		t.Errorf("expected empty.md to be empty, got %v", report.Empty)
+	}
+	if report.Tokenizer != "chars" || report.FitsContext != nil {
+		t.Errorf("unexpected tokenizer %q or context check %v", report.Tokenizer, report.FitsContext)
+	}
+
+	messages := logger.GetMessages()
+	if len(messages) != 4 || messages[0] != "DEBUG Counted path=copy.md words=6 tokens=8" {
+		t.Errorf("expected a debug log per file, got %v", messages)
+	}
+}
+
+func TestService_Collect_ContextWindow(t *testing.T) {
+	tests := []struct {
+		name     string
+		window   int
+		expected bool
+	}{
+		{name: "fits", window: 100000, expected: true},
+		{name: "does not fit", window: 10, expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := NewService(newCorpus(), NewMockLogger(), Config{ContextWindow: tt.window})
+
+			report, err := service.Collect("/docs")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if report.FitsContext == nil || *report.FitsContext != tt.expected {
+				t.Errorf("expected fits %v, got %v", tt.expected, report.FitsContext)
+			}
+			if report.Tokenizer != "bpe" {
+				t.Errorf("expected the bpe tokenizer by default, got %q", report.Tokenizer)
+			}
+		})
+	}
+}
+
+func TestService_Collect_MissingDir(t *testing.T) {
+	service := NewService(filesystem.NewMemFileSystem(), NewMockLogger(), Config{})
+	if _, err := service.Collect("/missing"); err == nil {
+		t.Error("expected error but got none")
+	}
+}
+
+func TestReport_Write(t *testing.T) {
+	service := NewService(newCorpus(), NewMockLogger(), Config{ContextWindow: 10})
+	report, err := service.Collect("/docs")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		format      string
+		contains    []string
+		expectError bool
+	}{
+		{
+			name:   "table",
+			format: FormatTable,
+			contains: []string{
+				"FILE              BYTES  WORDS  TOKENS\n",
+				"TOTAL (4 files)",
+				"Tokens estimated with the bpe tokenizer.",
+				"does not fit.",
+				"Largest files:\n  guide/install.md (",
+				"Duplicate content:\n  copy.md = index.md\n",
+				"Empty pages:\n  empty.md\n",
+			},
+		},
+		{
+			name:     "json",
+			format:   FormatJSON,
+			contains: []string{`"tokenizer": "bpe"`, `"fits_context": false`, `"context_window": 10`},
+		},
+		{
+			name:        "unknown format",
+			format:      "csv",
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			err := report.Write(&out, tt.format)
+			if tt.expectError {
+				if err == nil {
+					t.Error("expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			for _, s := range tt.contains {
+				if !strings.Contains(out.String(), s) {
+					t.Errorf("expected output to contain %q, got:\n%s", s, out.String())
+				}
+			}
+			if tt.format == FormatJSON {
+				var decoded Report
+				if err := json.Unmarshal(out.Bytes(), &decoded); err != nil {
+					t.Fatalf("invalid JSON: %v", err)
+				}
+				if !reflect.DeepEqual(decoded.Files, report.Files) {
+					t.Errorf("expected files to round-trip, got %+v", decoded.Files)
+				}
+			}
+		})
+	}
+}
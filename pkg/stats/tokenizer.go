@@ -0,0 +1,115 @@
+package stats
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"mdify/pkg/chunk"
+)
+
+// Tokenizer counts the tokens a model would see for a piece of text
+type Tokenizer interface {
+	Name() string
+	CountTokens(text string) int
+}
+
+// tokenizers are the built-in tokenizers, by name
+var tokenizers = map[string]Tokenizer{
+	"bpe":   BPETokenizer{},
+	"chars": CharTokenizer{},
+}
+
+// LookupTokenizer returns the built-in tokenizer with the given name
+func LookupTokenizer(name string) (Tokenizer, error) {
+	tokenizer, ok := tokenizers[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown tokenizer %q (use %s)", name, strings.Join(TokenizerNames(), " or "))
+	}
+	return tokenizer, nil
+}
+
+// TokenizerNames lists the built-in tokenizers
+func TokenizerNames() []string {
+	names := make([]string, 0, len(tokenizers))
+	for name := range tokenizers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// CharTokenizer estimates one token per four characters, the same estimate
+// the chunker budgets with
+type CharTokenizer struct{}
+
+func (CharTokenizer) Name() string { return "chars" }
+
+func (CharTokenizer) CountTokens(text string) int {
+	return chunk.EstimateTokens(text)
+}
+
+// BPETokenizer approximates byte-pair encoders such as cl100k. Text is split
+// into pre-tokens the way those encoders do it, a word with its leading space,
+// a run of digits, punctuation or whitespace, and each pre-token is costed by
+// how such runs are usually merged: common words are one token, long words a
+// token per eight letters, digits a token per three, symbols a token per two,
+// and CJK text a token per character.
+type BPETokenizer struct{}
+
+func (BPETokenizer) Name() string { return "bpe" }
+
+func (BPETokenizer) CountTokens(text string) int {
+	tokens := 0
+	runes := []rune(text)
+	for i := 0; i < len(runes); {
+		// a single space is merged into the word that follows it
+		if runes[i] == ' ' && i+1 < len(runes) && !unicode.IsSpace(runes[i+1]) {
+			i++
+		}
+		start := i
+
+		r := runes[i]
+		switch {
+		case isCJK(r):
+			i++
+			tokens++
+		case unicode.IsLetter(r):
+			ascii := true
+			for i < len(runes) && unicode.IsLetter(runes[i]) && !isCJK(runes[i]) {
+				ascii = ascii && runes[i] < unicode.MaxASCII
+				i++
+			}
+			perToken := 8
+			if !ascii {
+				perToken = 4
+			}
+			tokens += ceilDiv(i-start, perToken)
+		case unicode.IsDigit(r):
+			for i < len(runes) && unicode.IsDigit(runes[i]) {
+				i++
+			}
+			tokens += ceilDiv(i-start, 3)
+		case unicode.IsSpace(r):
+			for i < len(runes) && unicode.IsSpace(runes[i]) {
+				i++
+			}
+			tokens++
+		default:
+			for i < len(runes) && !unicode.IsSpace(runes[i]) && !unicode.IsLetter(runes[i]) && !unicode.IsDigit(runes[i]) {
+				i++
+			}
+			tokens += ceilDiv(i-start, 2)
+		}
+	}
+	return tokens
+}
+
+func isCJK(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul)
+}
+
+func ceilDiv(n, d int) int {
+	return (n + d - 1) / d
+}
@@ -0,0 +1,72 @@
+package stats
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBPETokenizer_CountTokens(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		expected int
+	}{
+		{name: "empty", text: "", expected: 0},
+		{name: "words with leading spaces", text: "the quick brown fox", expected: 4},
+		{name: "long word", text: "internationalization", expected: 3},
+		{name: "digits in groups of three", text: "1234567", expected: 3},
+		{name: "punctuation", text: "a, b.", expected: 4},
+		{name: "whitespace runs", text: "one\n\ntwo", expected: 3},
+		{name: "markdown", text: "## Install\n\n```sh\nnpm install\n```", expected: 12},
+		{name: "CJK", text: "日本語", expected: 3},
+		{name: "accented words", text: "über", expected: 1},
+		{name: "non-latin words", text: "привет", expected: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := (BPETokenizer{}).CountTokens(tt.text); got != tt.expected {
+				t.Errorf("expected %d tokens, got %d", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestBPETokenizer_CloseToCharEstimate(t *testing.T) {
+	text := strings.Repeat("Install the package with your package manager, then import it in your project. ", 50)
+
+	bpe := (BPETokenizer{}).CountTokens(text)
+	chars := (CharTokenizer{}).CountTokens(text)
+	if bpe < chars/2 || bpe > chars*2 {
+		t.Errorf("expected estimates for prose to be close, got bpe %d and chars %d", bpe, chars)
+	}
+}
+
+func TestLookupTokenizer(t *testing.T) {
+	tests := []struct {
+		name        string
+		expectError bool
+	}{
+		{name: "bpe"},
+		{name: "chars"},
+		{name: "tiktoken", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokenizer, err := LookupTokenizer(tt.name)
+			if tt.expectError {
+				if err == nil {
+					t.Error("expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tokenizer.Name() != tt.name {
+				t.Errorf("expected tokenizer %q, got %q", tt.name, tokenizer.Name())
+			}
+		})
+	}
+}